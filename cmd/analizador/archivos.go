@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// entradaEstandar es el argumento que indica leer el programa de la entrada
+// estándar
+const entradaEstandar = "-"
+
+// expandir convierte los argumentos en la lista de archivos a analizar, sin
+// repetir y en el orden en que aparecen. Los patrones se expanden, y de los
+// directorios se toman, recursivamente, los archivos con extensión ext.
+func expandir(args []string, ext string) ([]string, error) {
+	var archivos []string
+	vistos := map[string]bool{}
+	agregar := func(ruta string) {
+		if !vistos[ruta] {
+			vistos[ruta] = true
+			archivos = append(archivos, ruta)
+		}
+	}
+
+	for _, arg := range args {
+		if arg == entradaEstandar {
+			agregar(arg)
+			continue
+		}
+		rutas := []string{arg}
+		if strings.ContainsAny(arg, "*?[") {
+			coincidencias, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("patrón inválido %q: %w", arg, err)
+			}
+			if len(coincidencias) == 0 {
+				return nil, fmt.Errorf("ningún archivo coincide con %q", arg)
+			}
+			rutas = coincidencias
+		}
+
+		for _, ruta := range rutas {
+			info, err := os.Stat(ruta)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				agregar(ruta)
+				continue
+			}
+			err = filepath.WalkDir(ruta, func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.IsDir() && filepath.Ext(p) == ext {
+					agregar(p)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(archivos) == 0 {
+		return nil, fmt.Errorf("no se encontraron archivos %s", ext)
+	}
+	return archivos, nil
+}
+
+// leer devuelve el contenido de un archivo, o de la entrada estándar si la
+// ruta es "-"
+func leer(ruta string, entrada io.Reader) (string, error) {
+	if ruta == entradaEstandar {
+		datos, err := io.ReadAll(entrada)
+		if err != nil {
+			return "", fmt.Errorf("entrada estándar: %w", err)
+		}
+		return string(datos), nil
+	}
+	datos, err := os.ReadFile(ruta)
+	if err != nil {
+		return "", err
+	}
+	return string(datos), nil
+}
@@ -0,0 +1,141 @@
+// Command analizador analiza programas desde la línea de comandos, sin
+// levantar el servidor. Recibe archivos, directorios (que recorre buscando
+// los archivos con la extensión de --ext) y patrones como prog/*.src, o "-"
+// para leer la entrada estándar:
+//
+//	analizador [opciones] archivo|directorio|patrón...
+//
+// Informa los diagnósticos al estilo de un compilador:
+//
+//	prog.src:3:5: error: Caracter no reconocido '@'
+//	y = @;
+//	    ^
+//
+// Termina con código 1 si algún programa tiene errores y con 2 si no pudo
+// leer los archivos o las opciones son inválidas. Las advertencias no
+// cambian el código de salida.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"analyzer-api/pkg/analyzer"
+)
+
+// Códigos de salida
+const (
+	salidaOK       = 0
+	salidaErrores  = 1 // algún programa tiene errores
+	salidaFallaUso = 2 // opciones inválidas o archivos que no se pudieron leer
+)
+
+func main() {
+	os.Exit(ejecutar(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// ejecutar procesa los argumentos y devuelve el código de salida
+func ejecutar(args []string, entrada io.Reader, salida, errores io.Writer) int {
+	banderas := flag.NewFlagSet("analizador", flag.ContinueOnError)
+	banderas.SetOutput(errores)
+	formato := banderas.String("format", "text", "formato de la salida: text o json")
+	fase := banderas.String("phase", string(analyzer.PhaseSemantic), "última fase que se ejecuta: lexico, sintactico o semantico")
+	ext := banderas.String("ext", ".src", "extensión de los archivos que se buscan en los directorios")
+	nombreDialecto := banderas.String("dialecto", "", "nombre del dialecto del código; vacío para el dialecto base")
+	dirDialectos := banderas.String("dialectos", directorioDialectos(), "directorio con las especificaciones de los dialectos")
+	version := banderas.Bool("version", false, "mostrar la versión y salir")
+	banderas.Usage = func() {
+		fmt.Fprintln(errores, "Uso: analizador [opciones] archivo|directorio|patrón...")
+		banderas.PrintDefaults()
+	}
+	if err := banderas.Parse(args); err != nil {
+		return salidaFallaUso
+	}
+
+	if *version {
+		fmt.Fprintln(salida, "analizador", analyzer.Version)
+		return salidaOK
+	}
+	if *formato != "text" && *formato != "json" {
+		fmt.Fprintf(errores, "analizador: formato desconocido: %s\n", *formato)
+		return salidaFallaUso
+	}
+	if banderas.NArg() == 0 {
+		banderas.Usage()
+		return salidaFallaUso
+	}
+
+	if !analyzer.Phase(*fase).Valid() {
+		fmt.Fprintf(errores, "analizador: fase desconocida: %s\n", *fase)
+		return salidaFallaUso
+	}
+
+	opciones := analyzer.Options{Phase: analyzer.Phase(*fase)}
+	if *nombreDialecto != "" {
+		dialectos, err := analyzer.LoadDialects(*dirDialectos)
+		if err != nil {
+			fmt.Fprintf(errores, "analizador: %v\n", err)
+			return salidaFallaUso
+		}
+		d, ok := dialectos[*nombreDialecto]
+		if !ok {
+			fmt.Fprintf(errores, "analizador: dialecto desconocido: %s\n", *nombreDialecto)
+			return salidaFallaUso
+		}
+		opciones.Dialect = d
+	}
+
+	archivos, err := expandir(banderas.Args(), *ext)
+	if err != nil {
+		fmt.Fprintf(errores, "analizador: %v\n", err)
+		return salidaFallaUso
+	}
+
+	ctx, cancelar := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancelar()
+
+	var analizados []archivoAnalizado
+	for _, archivo := range archivos {
+		fuente, err := leer(archivo, entrada)
+		if err != nil {
+			fmt.Fprintf(errores, "analizador: %v\n", err)
+			return salidaFallaUso
+		}
+		res, err := analyzer.Analyze(ctx, fuente, opciones)
+		if err != nil {
+			fmt.Fprintf(errores, "analizador: %v\n", err)
+			return salidaFallaUso
+		}
+		analizados = append(analizados, archivoAnalizado{ruta: archivo, resultado: res})
+	}
+
+	if *formato == "json" {
+		err = imprimirJSON(salida, analizados)
+	} else {
+		err = imprimirTexto(salida, errores, analizados)
+	}
+	if err != nil {
+		fmt.Fprintf(errores, "analizador: %v\n", err)
+		return salidaFallaUso
+	}
+
+	for _, a := range analizados {
+		if a.resultado.HasErrors() {
+			return salidaErrores
+		}
+	}
+	return salidaOK
+}
+
+// directorioDialectos es el directorio de dialectos por defecto, el mismo
+// que usa el servidor
+func directorioDialectos() string {
+	if dir := os.Getenv("DIALECTOS_DIR"); dir != "" {
+		return dir
+	}
+	return "dialectos"
+}
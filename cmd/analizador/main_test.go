@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// escribir crea los archivos indicados bajo dir, con sus directorios
+func escribir(t *testing.T, dir string, archivos map[string]string) {
+	t.Helper()
+	for nombre, contenido := range archivos {
+		ruta := filepath.Join(dir, nombre)
+		if err := os.MkdirAll(filepath.Dir(ruta), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(ruta, []byte(contenido), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCodigosDeSalida(t *testing.T) {
+	dir := t.TempDir()
+	escribir(t, dir, map[string]string{
+		"bien.src":        "int x = 1;\nprint(x);\n",
+		"advertencia.src": "int x = 1;\n",
+		"mal.src":         "int x = 1;\ny = @;\n",
+	})
+	ruta := func(nombre string) string { return filepath.Join(dir, nombre) }
+
+	casos := []struct {
+		nombre  string
+		args    []string
+		entrada string
+		codigo  int
+	}{
+		{"sin errores", []string{ruta("bien.src")}, "", salidaOK},
+		{"solo advertencias", []string{ruta("advertencia.src")}, "", salidaOK},
+		{"con errores", []string{ruta("bien.src"), ruta("mal.src")}, "", salidaErrores},
+		{"entrada estándar", []string{"-"}, "y = 1;", salidaErrores},
+		{"json", []string{"-format", "json", ruta("mal.src")}, "", salidaErrores},
+		{"solo la fase léxica", []string{"-phase", "lexico", ruta("advertencia.src")}, "", salidaOK},
+		{"versión", []string{"-version"}, "", salidaOK},
+		{"sin archivos", nil, "", salidaFallaUso},
+		{"archivo inexistente", []string{ruta("no.src")}, "", salidaFallaUso},
+		{"fase desconocida", []string{"-phase", "otra", ruta("bien.src")}, "", salidaFallaUso},
+		{"formato desconocido", []string{"-format", "xml", ruta("bien.src")}, "", salidaFallaUso},
+		{"opción desconocida", []string{"-otra", ruta("bien.src")}, "", salidaFallaUso},
+		{"dialecto desconocido", []string{"-dialecto", "x", "-dialectos", dir, ruta("bien.src")}, "", salidaFallaUso},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			var salida, errores bytes.Buffer
+			if codigo := ejecutar(c.args, strings.NewReader(c.entrada), &salida, &errores); codigo != c.codigo {
+				t.Errorf("código %d, se esperaba %d\nsalida:\n%s\nerrores:\n%s", codigo, c.codigo, salida.String(), errores.String())
+			}
+		})
+	}
+}
+
+// Cada diagnóstico lleva la línea del código y una marca bajo la columna
+func TestSalidaTexto(t *testing.T) {
+	casos := []struct {
+		codigo      string
+		diagnostico string
+	}{
+		{"int x = 1;\ny = @;\n", "<entrada>:2:5: error: Caracter no reconocido '@'\ny = @;\n    ^\n"},
+		{"int x = 1;\n\ty = @;\n", "<entrada>:2:6: error: Caracter no reconocido '@'\n\ty = @;\n\t    ^\n"},
+	}
+	for _, c := range casos {
+		var salida, errores bytes.Buffer
+		if codigo := ejecutar([]string{"-"}, strings.NewReader(c.codigo), &salida, &errores); codigo != salidaErrores {
+			t.Fatalf("código %d, se esperaba %d", codigo, salidaErrores)
+		}
+		if !strings.Contains(salida.String(), c.diagnostico) {
+			t.Errorf("la salida no contiene\n%s\nsalida:\n%s", c.diagnostico, salida.String())
+		}
+		if !strings.Contains(errores.String(), " en 1 archivo") {
+			t.Errorf("sin resumen en los errores:\n%s", errores.String())
+		}
+	}
+}
+
+func TestExpandir(t *testing.T) {
+	dir := t.TempDir()
+	escribir(t, dir, map[string]string{
+		"a.src":          "",
+		"b.src":          "",
+		"notas.txt":      "",
+		"sub/c.src":      "",
+		"sub/d.txt":      "",
+		"sub/otro/e.src": "",
+	})
+	ruta := func(nombre string) string { return filepath.Join(dir, filepath.FromSlash(nombre)) }
+
+	casos := []struct {
+		nombre   string
+		args     []string
+		esperado []string
+	}{
+		{"archivo", []string{ruta("notas.txt")}, []string{ruta("notas.txt")}},
+		{"directorio recursivo", []string{ruta("sub")}, []string{ruta("sub/c.src"), ruta("sub/otro/e.src")}},
+		{"patrón", []string{ruta("*.src")}, []string{ruta("a.src"), ruta("b.src")}},
+		{"sin repetir", []string{ruta("b.src"), ruta("*.src"), ruta("b.src")}, []string{ruta("b.src"), ruta("a.src")}},
+		{"entrada estándar", []string{"-", ruta("a.src")}, []string{"-", ruta("a.src")}},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			archivos, err := expandir(c.args, ".src")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(archivos, c.esperado) {
+				t.Errorf("expandir(%v) = %v, se esperaba %v", c.args, archivos, c.esperado)
+			}
+		})
+	}
+
+	errores := [][]string{
+		{ruta("*.nada")},
+		{ruta("no.src")},
+		{ruta("[")},
+	}
+	for _, args := range errores {
+		if archivos, err := expandir(args, ".src"); err == nil {
+			t.Errorf("expandir(%v) = %v, se esperaba un error", args, archivos)
+		}
+	}
+	// Un directorio sin archivos con la extensión
+	if archivos, err := expandir([]string{ruta("sub")}, ".go"); err == nil {
+		t.Errorf("expandir = %v, se esperaba un error", archivos)
+	}
+}
+
+func TestSangria(t *testing.T) {
+	casos := []struct {
+		linea   string
+		columna int
+		marca   string
+	}{
+		{"y = @;", 5, "    "},
+		{"\ty = @;", 6, "\t    "},
+		{"\t\tx;", 3, "\t\t"},
+		{"y = @;", 1, ""},
+		{"y;", 10, "  "}, // la columna pasa del final de la línea
+		{"y;", 0, ""},
+	}
+	for _, c := range casos {
+		if marca := sangria(c.linea, c.columna); marca != c.marca {
+			t.Errorf("sangria(%q, %d) = %q, se esperaba %q", c.linea, c.columna, marca, c.marca)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"analyzer-api/pkg/analyzer"
+)
+
+// archivoAnalizado es un archivo junto con el resultado de analizarlo
+type archivoAnalizado struct {
+	ruta      string
+	resultado *analyzer.Result
+}
+
+// nombre es como se identifica el archivo en la salida
+func (a archivoAnalizado) nombre() string {
+	if a.ruta == entradaEstandar {
+		return "<entrada>"
+	}
+	return a.ruta
+}
+
+// diagnosticos devuelve los diagnósticos del archivo en el orden del código
+// fuente; los que no tienen posición van primero
+func (a archivoAnalizado) diagnosticos() []analyzer.Diagnostic {
+	diagnosticos := append([]analyzer.Diagnostic(nil), a.resultado.Diagnostics...)
+	sort.SliceStable(diagnosticos, func(i, j int) bool {
+		if diagnosticos[i].Line != diagnosticos[j].Line {
+			return diagnosticos[i].Line < diagnosticos[j].Line
+		}
+		return diagnosticos[i].Column < diagnosticos[j].Column
+	})
+	return diagnosticos
+}
+
+// imprimirTexto escribe cada diagnóstico como archivo:línea:columna seguido
+// de la línea del código y una marca bajo la columna. El resumen va a
+// errores para no mezclarse con los diagnósticos.
+func imprimirTexto(salida, errores io.Writer, analizados []archivoAnalizado) error {
+	totalErrores, totalAdvertencias := 0, 0
+	for _, a := range analizados {
+		lineas := strings.Split(a.resultado.Source, "\n")
+		for _, d := range a.diagnosticos() {
+			if d.Line < 1 {
+				// Sin posición en el código
+				fmt.Fprintf(salida, "%s: %s\n", a.nombre(), sinPosicion(d))
+				continue
+			}
+			if _, err := fmt.Fprintf(salida, "%s:%s\n", a.nombre(), d); err != nil {
+				return err
+			}
+			if d.Line <= len(lineas) {
+				linea := strings.TrimRight(lineas[d.Line-1], "\r")
+				fmt.Fprintf(salida, "%s\n%s^\n", linea, sangria(linea, d.Column))
+			}
+		}
+		totalErrores += len(a.resultado.Errors())
+		totalAdvertencias += len(a.resultado.Warnings())
+	}
+
+	if totalErrores > 0 || totalAdvertencias > 0 {
+		fmt.Fprintf(errores, "%s y %s en %s\n",
+			plural(totalErrores, "error", "errores"),
+			plural(totalAdvertencias, "advertencia", "advertencias"),
+			plural(len(analizados), "archivo", "archivos"))
+	}
+	return nil
+}
+
+// sinPosicion escribe un diagnóstico sin línea ni columna
+func sinPosicion(d analyzer.Diagnostic) string {
+	texto := fmt.Sprintf("%s: %s", d.Severity, d.Message)
+	if d.Code != "" {
+		texto += " [" + d.Code + "]"
+	}
+	return texto
+}
+
+// sangria devuelve el espacio que ocupa la línea antes de la columna dada,
+// conservando los tabuladores para que la marca quede alineada
+func sangria(linea string, columna int) string {
+	n := min(max(columna-1, 0), len(linea))
+	var b strings.Builder
+	for _, r := range linea[:n] {
+		if r == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return b.String()
+}
+
+func plural(n int, singular, varios string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, varios)
+}
+
+// diagnosticoJSON es un diagnóstico en la salida JSON
+type diagnosticoJSON struct {
+	Fase      string `json:"fase"`
+	Severidad string `json:"severidad"`
+	Codigo    string `json:"codigo,omitempty"`
+	Mensaje   string `json:"mensaje"`
+	Linea     int    `json:"linea"`
+	Columna   int    `json:"columna"`
+}
+
+// archivoJSON es el resultado de un archivo en la salida JSON
+type archivoJSON struct {
+	Archivo      string            `json:"archivo"`
+	Fases        []analyzer.Phase  `json:"fases"`
+	Errores      int               `json:"errores"`
+	Advertencias int               `json:"advertencias"`
+	Diagnosticos []diagnosticoJSON `json:"diagnosticos"`
+}
+
+// imprimirJSON escribe un arreglo con el resultado de cada archivo
+func imprimirJSON(salida io.Writer, analizados []archivoAnalizado) error {
+	archivos := []archivoJSON{}
+	for _, a := range analizados {
+		archivo := archivoJSON{
+			Archivo:      a.nombre(),
+			Fases:        a.resultado.Phases,
+			Errores:      len(a.resultado.Errors()),
+			Advertencias: len(a.resultado.Warnings()),
+			Diagnosticos: []diagnosticoJSON{},
+		}
+		for _, d := range a.diagnosticos() {
+			archivo.Diagnosticos = append(archivo.Diagnosticos, diagnosticoJSON{
+				Fase:      string(d.Phase),
+				Severidad: string(d.Severity),
+				Codigo:    d.Code,
+				Mensaje:   d.Message,
+				Linea:     d.Line,
+				Columna:   d.Column,
+			})
+		}
+		archivos = append(archivos, archivo)
+	}
+
+	encoder := json.NewEncoder(salida)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(archivos)
+}
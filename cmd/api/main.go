@@ -7,6 +7,7 @@ import (
 	"os"
 	
 	"analyzer-api/internal/api"
+	"analyzer-api/internal/lexer"
 )
 
 func main() {
@@ -16,8 +17,18 @@ func main() {
 		port = "8080"
 	}
 	
+	// Cargar los dialectos del directorio indicado o de ./dialectos
+	dir := os.Getenv("DIALECTOS_DIR")
+	if dir == "" {
+		dir = "dialectos"
+	}
+	dialectos, err := lexer.CargarDialectosDirectorio(dir)
+	if err != nil {
+		log.Fatal("Error al cargar los dialectos: ", err)
+	}
+	
 	// Configurar el router
-	router := api.SetupRouter()
+	router := api.SetupRouter(dialectos)
 	
 	// Iniciar el servidor
 	fmt.Printf("Servidor iniciado en http://localhost:%s\n", port)
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"analyzer-api/internal/automata"
+	"analyzer-api/pkg/models"
+)
+
+// SolicitudAutomata representa la solicitud para construir los autómatas de
+// un conjunto de definiciones de tokens
+type SolicitudAutomata struct {
+	Definiciones []automata.Definicion `json:"definiciones"` // vacío para las del lenguaje base
+	Codigo       string                `json:"codigo,omitempty"`
+}
+
+// maxCuerpoAutomata limita el tamaño en bytes de una solicitud de autómatas.
+// Alcanza para MaxDefiniciones patrones de MaxLongitudPatron bytes y un
+// código de ejemplo.
+const maxCuerpoAutomata = 64 << 10
+
+// GenerarAutomata construye el AFN, el AFD y el AFD mínimo de las definiciones
+func (h *AnalyzerHandler) GenerarAutomata(w http.ResponseWriter, r *http.Request) {
+	if !prepararSolicitud(w, r) {
+		return
+	}
+
+	var solicitud SolicitudAutomata
+	if !decodificarSolicitudLimite(w, r, &solicitud, maxCuerpoAutomata) {
+		return
+	}
+	if len(solicitud.Definiciones) == 0 {
+		solicitud.Definiciones = automata.DefinicionesBase()
+	}
+
+	resultado, err := resultadoAutomata(solicitud.Definiciones, solicitud.Codigo)
+	if errors.Is(err, automata.ErrLimite) {
+		http.Error(w, "Definiciones demasiado grandes: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Definiciones inválidas: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	responderJSON(w, resultado)
+}
+
+// resultadoAutomata construye el AFN, el AFD y el AFD mínimo de las
+// definiciones y, si se indica código, lo tokeniza con el AFD mínimo. Falla
+// con un error que envuelve automata.ErrLimite si las definiciones superan
+// los límites de la construcción.
+func resultadoAutomata(definiciones []automata.Definicion, codigo string) (*models.ResultadoAutomata, error) {
+	afn, err := automata.ConstruirAFN(definiciones)
+	if err != nil {
+		return nil, err
+	}
+	afd, err := afn.AFD()
+	if err != nil {
+		return nil, err
+	}
+	minimo := afd.Minimizar()
+
+	resultado := &models.ResultadoAutomata{
+		AFN:       automataInfo(len(afn.Estados), afn.Tabla(), "AFN"),
+		AFD:       automataInfo(len(afd.Estados), afd.Tabla(), "AFD"),
+		AFDMinimo: automataInfo(len(minimo.Estados), minimo.Tabla(), "AFDMinimo"),
+		Tokens:    []models.TokenInfo{},
+	}
+	resultado.Definiciones = convertir(definiciones, func(d automata.Definicion) models.DefinicionInfo {
+		return models.DefinicionInfo{
+			Nombre:  d.Nombre,
+			Patron:  d.Patron,
+			Tipo:    string(d.Tipo),
+			Ignorar: d.Ignorar,
+		}
+	})
+
+	if codigo != "" {
+		for _, token := range minimo.NuevoLexer(codigo).Analizar() {
+			resultado.Tokens = append(resultado.Tokens, models.TokenInfo{
+				Tipo:      token.Clase(),
+				Lexema:    token.Lexeme,
+				Linea:     token.Line,
+				Columna:   token.Column,
+				Categoria: token.Categoria(),
+				TipoToken: string(token.Type),
+			})
+		}
+	}
+
+	return resultado, nil
+}
+
+// automataInfo convierte la tabla de transiciones de un autómata a
+// AutomataInfo, con su descripción DOT bajo el nombre dado
+func automataInfo(estados int, tabla *automata.TablaTransiciones, nombre string) models.AutomataInfo {
+	info := &models.TablaTransicionesInfo{
+		Simbolos: tabla.Simbolos,
+		Inicial:  tabla.Inicial,
+		Filas: convertir(tabla.Filas, func(fila automata.FilaTabla) models.FilaTablaInfo {
+			return models.FilaTablaInfo(fila)
+		}),
+	}
+	return models.AutomataInfo{Estados: estados, Tabla: info, DOT: tabla.DOT(nombre)}
+}
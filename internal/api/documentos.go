@@ -0,0 +1,195 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+
+	"analyzer-api/internal/incremental"
+	"analyzer-api/pkg/models"
+)
+
+// Límites de los documentos abiertos que se conservan en memoria: su
+// cantidad y la suma del tamaño de sus textos. Al superar cualquiera se
+// descartan los usados hace más tiempo.
+const (
+	maxDocumentos      = 1000
+	maxBytesDocumentos = 64 << 20
+)
+
+// almacenDocumentos guarda los documentos abiertos para edición incremental
+type almacenDocumentos struct {
+	mu         sync.Mutex
+	documentos map[string]*incremental.Documento
+	tamanos    map[string]int // tamaño del texto de cada documento al guardarlo
+	bytes      int            // suma de tamanos
+	orden      []string       // del usado hace más tiempo al más reciente
+}
+
+func nuevoAlmacenDocumentos() *almacenDocumentos {
+	return &almacenDocumentos{
+		documentos: map[string]*incremental.Documento{},
+		tamanos:    map[string]int{},
+	}
+}
+
+// guardar registra un documento nuevo o actualiza el tamaño de uno editado
+func (a *almacenDocumentos) guardar(doc *incremental.Documento) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	tamano := len(doc.Texto())
+	a.documentos[doc.ID] = doc
+	a.bytes += tamano - a.tamanos[doc.ID]
+	a.tamanos[doc.ID] = tamano
+	a.tocar(doc.ID)
+	for len(a.orden) > 1 && (len(a.orden) > maxDocumentos || a.bytes > maxBytesDocumentos) {
+		id := a.orden[0]
+		a.bytes -= a.tamanos[id]
+		delete(a.documentos, id)
+		delete(a.tamanos, id)
+		a.orden = a.orden[1:]
+	}
+}
+
+func (a *almacenDocumentos) obtener(id string) (*incremental.Documento, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	doc, ok := a.documentos[id]
+	if ok {
+		a.tocar(id)
+	}
+	return doc, ok
+}
+
+// tocar marca el documento como el usado más recientemente
+func (a *almacenDocumentos) tocar(id string) {
+	for i, otro := range a.orden {
+		if otro == id {
+			a.orden = append(a.orden[:i], a.orden[i+1:]...)
+			break
+		}
+	}
+	a.orden = append(a.orden, id)
+}
+
+// nuevoID genera un identificador aleatorio para un documento o un lote
+func nuevoID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// SolicitudEdicion representa una edición sobre un documento ya abierto
+type SolicitudEdicion struct {
+	DocumentoID string              `json:"documentoId"`
+	Edicion     incremental.Edicion `json:"edicion"`
+}
+
+// AbrirDocumento analiza el código y lo registra para ediciones incrementales
+func (h *AnalyzerHandler) AbrirDocumento(w http.ResponseWriter, r *http.Request) {
+	if !prepararSolicitud(w, r) {
+		return
+	}
+
+	var solicitud SolicitudAnalisis
+	if !decodificarSolicitud(w, r, &solicitud) {
+		return
+	}
+	dialecto, ok := h.dialecto(solicitud.Dialecto)
+	if !ok {
+		http.Error(w, "Dialecto desconocido: "+solicitud.Dialecto, http.StatusBadRequest)
+		return
+	}
+
+	doc, err := incremental.Abrir(nuevoID(), solicitud.Codigo, dialecto)
+	if err != nil {
+		http.Error(w, "Documento demasiado grande: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.documentos.guardar(doc)
+
+	responderJSON(w, resultadoDocumento(doc))
+}
+
+// EditarDocumento aplica una edición a un documento abierto y devuelve solo
+// los diagnósticos que cambiaron
+func (h *AnalyzerHandler) EditarDocumento(w http.ResponseWriter, r *http.Request) {
+	if !prepararSolicitud(w, r) {
+		return
+	}
+
+	var solicitud SolicitudEdicion
+	if !decodificarSolicitud(w, r, &solicitud) {
+		return
+	}
+	doc, ok := h.documentos.obtener(solicitud.DocumentoID)
+	if !ok {
+		http.Error(w, "Documento desconocido: "+solicitud.DocumentoID, http.StatusNotFound)
+		return
+	}
+
+	cambios, err := doc.Aplicar(solicitud.Edicion)
+	if errors.Is(err, incremental.ErrLimite) {
+		http.Error(w, "Documento demasiado grande: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Edición inválida: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.documentos.guardar(doc)
+
+	responderJSON(w, resultadoEdicion(doc, cambios))
+}
+
+// resultadoDocumento crea el resultado de abrir un documento
+func resultadoDocumento(doc *incremental.Documento) *models.ResultadoDocumento {
+	errores, advertencias := erroresDeDiagnosticos(doc.Diagnosticos())
+	return &models.ResultadoDocumento{
+		DocumentoID:  doc.ID,
+		Version:      doc.Version(),
+		Errores:      errores,
+		Advertencias: advertencias,
+	}
+}
+
+// resultadoEdicion crea el resultado de aplicar una edición
+func resultadoEdicion(doc *incremental.Documento, cambios *incremental.Cambios) *models.ResultadoEdicion {
+	nuevos, advertenciasNuevas := erroresDeDiagnosticos(cambios.Agregados)
+	resueltos, advertenciasResueltas := erroresDeDiagnosticos(cambios.Eliminados)
+	return &models.ResultadoEdicion{
+		DocumentoID:               doc.ID,
+		Version:                   cambios.Version,
+		ErroresNuevos:             nuevos,
+		ErroresResueltos:          resueltos,
+		AdvertenciasNuevas:        advertenciasNuevas,
+		AdvertenciasResueltas:     advertenciasResueltas,
+		TokensReanalizados:        cambios.TokensReanalizados,
+		TokensReutilizados:        cambios.TokensReutilizados,
+		DeclaracionesReanalizadas: cambios.DeclaracionesReanalizadas,
+		DeclaracionesReutilizadas: cambios.DeclaracionesReutilizadas,
+	}
+}
+
+// erroresDeDiagnosticos separa los diagnósticos en errores y advertencias,
+// como en el resultado del análisis completo
+func erroresDeDiagnosticos(diagnosticos []incremental.Diagnostico) (errores, advertencias []models.ErrorInfo) {
+	errores, advertencias = []models.ErrorInfo{}, []models.ErrorInfo{}
+	for _, diag := range diagnosticos {
+		info := models.ErrorInfo{
+			Tipo:    diag.Tipo,
+			Mensaje: diag.Mensaje,
+			Linea:   diag.Linea,
+			Columna: diag.Columna,
+			Codigo:  diag.Codigo,
+		}
+		if diag.Codigo != "" {
+			advertencias = append(advertencias, info)
+		} else {
+			errores = append(errores, info)
+		}
+	}
+	return errores, advertencias
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"analyzer-api/internal/compilacion"
+	"analyzer-api/internal/interprete"
+	"analyzer-api/internal/parser"
+	"analyzer-api/pkg/models"
+)
+
+// EjecutarCodigo analiza el código y, si no tiene errores, lo ejecuta con la
+// entrada de la solicitud. Devuelve lo que el programa escribió y el valor
+// final de sus variables.
+func (h *AnalyzerHandler) EjecutarCodigo(w http.ResponseWriter, r *http.Request) {
+	if !prepararSolicitud(w, r) {
+		return
+	}
+
+	var solicitud SolicitudAnalisis
+	if !decodificarSolicitud(w, r, &solicitud) {
+		return
+	}
+
+	dialecto, ok := h.dialecto(solicitud.Dialecto)
+	if !ok {
+		http.Error(w, "Dialecto desconocido: "+solicitud.Dialecto, http.StatusBadRequest)
+		return
+	}
+
+	u := compilacion.Nueva(solicitud.Codigo, compilacion.ConDialecto(dialecto))
+	analisis := resultadoAnalisis(u)
+
+	responderJSON(w, resultadoEjecucion(r.Context(), u.Programa(), analisis.Errores, solicitud.Entrada))
+}
+
+// tiempoMaximoEjecucion limita lo que puede tardar un programa, además del
+// límite de pasos del intérprete
+const tiempoMaximoEjecucion = 5 * time.Second
+
+// resultadoEjecucion ejecuta el programa con la entrada dada para read. Si
+// el análisis encontró errores, el programa no se ejecuta y el resultado los
+// contiene. La ejecución se detiene si ctx termina o pasa
+// tiempoMaximoEjecucion.
+func resultadoEjecucion(ctx context.Context, ast *parser.Programa, errores []models.ErrorInfo, entrada string) *models.ResultadoEjecucion {
+	resultado := &models.ResultadoEjecucion{
+		Errores:   []models.ErrorInfo{},
+		Variables: []models.VariableEjecucion{},
+	}
+	if len(errores) > 0 {
+		resultado.Errores = append(resultado.Errores, errores...)
+		return resultado
+	}
+
+	ctx, cancelar := context.WithTimeout(ctx, tiempoMaximoEjecucion)
+	defer cancelar()
+	in := interprete.New(ast, interprete.ConEntrada(entrada), interprete.ConContexto(ctx))
+	if err := in.Ejecutar(); err != nil {
+		resultado.Errores = append(resultado.Errores, models.ErrorInfo{
+			Tipo:    "ejecucion",
+			Mensaje: err.Mensaje,
+			Linea:   err.Linea,
+			Columna: err.Columna,
+		})
+	}
+	resultado.Ejecutado = true
+	resultado.Salida = in.Salida()
+	resultado.Pasos = in.Pasos()
+	for _, v := range in.Globales() {
+		var valor interface{} = v.Valores
+		if !v.Arreglo {
+			valor = v.Valores[0]
+		}
+		resultado.Variables = append(resultado.Variables, models.VariableEjecucion{Nombre: v.Nombre, Valor: valor})
+	}
+	return resultado
+}
@@ -0,0 +1,69 @@
+package api
+
+import "net/http"
+
+// AnalizarLexico ejecuta solo el análisis léxico y devuelve los tokens y los
+// errores léxicos
+func (h *AnalyzerHandler) AnalizarLexico(w http.ResponseWriter, r *http.Request) {
+	if !prepararSolicitud(w, r) {
+		return
+	}
+
+	var solicitud SolicitudAnalisis
+	if !decodificarSolicitud(w, r, &solicitud) {
+		return
+	}
+	u, memoria, ok := h.unidadSolicitud(w, solicitud)
+	if !ok {
+		return
+	}
+
+	resultado := resultadoLexico(u)
+	resultado.Traza, resultado.TrazaDescartados = eventosTraza(memoria)
+
+	responderJSON(w, resultado)
+}
+
+// AnalizarSintaxis ejecuta el análisis léxico y el sintáctico y devuelve el
+// árbol y los errores de sintaxis
+func (h *AnalyzerHandler) AnalizarSintaxis(w http.ResponseWriter, r *http.Request) {
+	if !prepararSolicitud(w, r) {
+		return
+	}
+
+	var solicitud SolicitudAnalisis
+	if !decodificarSolicitud(w, r, &solicitud) {
+		return
+	}
+	u, memoria, ok := h.unidadSolicitud(w, solicitud)
+	if !ok {
+		return
+	}
+
+	resultado := resultadoSintactico(u)
+	resultado.Traza, resultado.TrazaDescartados = eventosTraza(memoria)
+
+	responderJSON(w, resultado)
+}
+
+// AnalizarSemantica ejecuta las tres fases y devuelve los errores
+// semánticos, las advertencias y la tabla de símbolos
+func (h *AnalyzerHandler) AnalizarSemantica(w http.ResponseWriter, r *http.Request) {
+	if !prepararSolicitud(w, r) {
+		return
+	}
+
+	var solicitud SolicitudAnalisis
+	if !decodificarSolicitud(w, r, &solicitud) {
+		return
+	}
+	u, memoria, ok := h.unidadSolicitud(w, solicitud)
+	if !ok {
+		return
+	}
+
+	resultado := resultadoSemantico(u)
+	resultado.Traza, resultado.TrazaDescartados = eventosTraza(memoria)
+
+	responderJSON(w, resultado)
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+
+	"analyzer-api/internal/formato"
+	"analyzer-api/internal/lexer"
+	"analyzer-api/pkg/models"
+)
+
+// FormatearCodigo devuelve el código con el estilo canónico y las diferencias
+// con el original
+func (h *AnalyzerHandler) FormatearCodigo(w http.ResponseWriter, r *http.Request) {
+	if !prepararSolicitud(w, r) {
+		return
+	}
+
+	var solicitud SolicitudAnalisis
+	if !decodificarSolicitud(w, r, &solicitud) {
+		return
+	}
+
+	dialecto, ok := h.dialecto(solicitud.Dialecto)
+	if !ok {
+		http.Error(w, "Dialecto desconocido: "+solicitud.Dialecto, http.StatusBadRequest)
+		return
+	}
+
+	responderJSON(w, resultadoFormato(solicitud.Codigo, dialecto))
+}
+
+// resultadoFormato formatea el código en el dialecto dado
+func resultadoFormato(codigo string, dialecto *lexer.Dialecto) *models.ResultadoFormato {
+	formateado, lexicos, sintacticos := formato.Formatear(codigo, dialecto)
+	resultado := &models.ResultadoFormato{
+		Codigo:     formateado,
+		Diff:       formato.Diff(codigo, formateado),
+		Modificado: formateado != codigo,
+		Errores:    []models.ErrorInfo{},
+	}
+	for _, err := range lexicos {
+		resultado.Errores = append(resultado.Errores, models.ErrorInfo{
+			Tipo:    models.FASE_LEXICA,
+			Mensaje: err.Mensaje,
+			Linea:   err.Linea,
+			Columna: err.Columna,
+		})
+	}
+	for _, err := range sintacticos {
+		resultado.Errores = append(resultado.Errores, models.ErrorInfo{
+			Tipo:    models.FASE_SINTACTICA,
+			Mensaje: err.Mensaje,
+			Linea:   err.Linea,
+			Columna: err.Columna,
+		})
+	}
+	return resultado
+}
@@ -0,0 +1,314 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"analyzer-api/internal/diagrama"
+	"analyzer-api/internal/gramatica"
+	"analyzer-api/internal/lexer"
+	"analyzer-api/internal/parser"
+	"analyzer-api/pkg/models"
+)
+
+// SolicitudGramatica representa una gramática en BNF o EBNF y, opcionalmente,
+// un programa para analizar con las tablas generadas
+type SolicitudGramatica struct {
+	Gramatica string `json:"gramatica"`
+	Codigo    string `json:"codigo,omitempty"`
+	Dialecto  string `json:"dialecto,omitempty"`
+	Metodo    string `json:"metodo,omitempty"` // lr0, slr o lalr; solo para /api/gramatica/lr
+}
+
+// metodosLR son los nombres aceptados en SolicitudGramatica.Metodo
+var metodosLR = map[string]gramatica.MetodoLR{
+	"lr0":  gramatica.LR0,
+	"slr":  gramatica.SLR,
+	"lalr": gramatica.LALR1,
+}
+
+// AnalizarLL1 calcula PRIMERO, SIGUIENTE y la tabla LL(1) de una gramática
+func (h *AnalyzerHandler) AnalizarLL1(w http.ResponseWriter, r *http.Request) {
+	if !prepararSolicitud(w, r) {
+		return
+	}
+
+	var solicitud SolicitudGramatica
+	if !decodificarSolicitud(w, r, &solicitud) {
+		return
+	}
+	g, ok := h.cargarGramatica(w, solicitud)
+	if !ok {
+		return
+	}
+	dialecto, ok := h.dialecto(solicitud.Dialecto)
+	if !ok {
+		http.Error(w, "Dialecto desconocido: "+solicitud.Dialecto, http.StatusBadRequest)
+		return
+	}
+
+	responderJSON(w, resultadoLL1(g, solicitud.Codigo, dialecto))
+}
+
+// AnalizarLR construye la colección canónica LR(0) y las tablas ACCION e IR_A
+// de una gramática con el método pedido, LALR(1) por defecto
+func (h *AnalyzerHandler) AnalizarLR(w http.ResponseWriter, r *http.Request) {
+	if !prepararSolicitud(w, r) {
+		return
+	}
+
+	var solicitud SolicitudGramatica
+	if !decodificarSolicitud(w, r, &solicitud) {
+		return
+	}
+	metodo := gramatica.LALR1
+	if solicitud.Metodo != "" {
+		var ok bool
+		if metodo, ok = metodosLR[strings.ToLower(solicitud.Metodo)]; !ok {
+			http.Error(w, "Método desconocido: "+solicitud.Metodo+" (use lr0, slr o lalr)", http.StatusBadRequest)
+			return
+		}
+	}
+	g, ok := h.cargarGramatica(w, solicitud)
+	if !ok {
+		return
+	}
+	dialecto, ok := h.dialecto(solicitud.Dialecto)
+	if !ok {
+		http.Error(w, "Dialecto desconocido: "+solicitud.Dialecto, http.StatusBadRequest)
+		return
+	}
+
+	resultado, err := resultadoLR(g, metodo, solicitud.Codigo, dialecto)
+	if err != nil {
+		http.Error(w, "Gramática demasiado grande: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	responderJSON(w, resultado)
+}
+
+// cargarGramatica lee la gramática de la solicitud. Devuelve false si la
+// solicitud ya fue respondida con un error.
+func (h *AnalyzerHandler) cargarGramatica(w http.ResponseWriter, solicitud SolicitudGramatica) (*gramatica.Gramatica, bool) {
+	g, err := gramatica.Cargar(solicitud.Gramatica)
+	if errors.Is(err, gramatica.ErrLimite) {
+		http.Error(w, "Gramática demasiado grande: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "Gramática inválida: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return g, true
+}
+
+// ObtenerGramatica exporta la gramática que reconoce el parser. El parámetro
+// formato elige la respuesta: json (por defecto) con el EBNF, las
+// producciones y un diagrama por regla; ebnf con el texto; o svg con los
+// diagramas de todas las reglas, o solo el de la indicada en regla.
+func (h *AnalyzerHandler) ObtenerGramatica(w http.ResponseWriter, r *http.Request) {
+	if !prepararSolicitudMetodo(w, r, http.MethodGet) {
+		return
+	}
+
+	consulta := r.URL.Query()
+	dialecto, ok := h.dialecto(consulta.Get("dialecto"))
+	if !ok {
+		http.Error(w, "Dialecto desconocido: "+consulta.Get("dialecto"), http.StatusBadRequest)
+		return
+	}
+
+	switch formato := consulta.Get("formato"); formato {
+	case "", "json":
+		resultado, err := resultadoGramaticaParser(dialecto)
+		if err != nil {
+			http.Error(w, "Error al exportar la gramática: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		responderJSON(w, resultado)
+
+	case "ebnf":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(parser.EBNF(dialecto)))
+
+	case "svg":
+		reglas := parser.Gramatica()
+		if nombre := consulta.Get("regla"); nombre != "" {
+			reglas = nil
+			for _, regla := range parser.Gramatica() {
+				if regla.Nombre == nombre {
+					reglas = append(reglas, regla)
+				}
+			}
+			if len(reglas) == 0 {
+				http.Error(w, "Regla desconocida: "+nombre, http.StatusNotFound)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Write([]byte(diagrama.Reglas(reglas, dialecto)))
+
+	default:
+		http.Error(w, "Formato desconocido: "+formato+" (use json, ebnf o svg)", http.StatusBadRequest)
+	}
+}
+
+// resultadoLL1 calcula los conjuntos y la tabla LL(1) de la gramática y
+// analiza el código si no está vacío
+func resultadoLL1(g *gramatica.Gramatica, codigo string, dialecto *lexer.Dialecto) *models.ResultadoLL1 {
+	tabla := g.TablaLL1()
+	resultado := &models.ResultadoLL1{
+		Gramatica:    g.String(),
+		Producciones: nombresProducciones(g),
+		Advertencias: append([]string{}, g.Advertencias...),
+		Primeros:     g.Primeros(),
+		Siguientes:   g.Siguientes(),
+		Tabla: &models.TablaLL1Info{
+			Terminales: tabla.Terminales,
+			Celdas:     tabla.Celdas,
+			Conflictos: conflictosLL1(tabla.Conflictos),
+		},
+		EsLL1: tabla.EsLL1(),
+	}
+	if codigo != "" {
+		traza := tabla.Analizar(lexer.New(codigo, lexer.ConDialecto(dialecto)).Analizar())
+		resultado.Traza = trazaInfo(traza, func(p gramatica.PasoLL1) models.PasoLL1Info {
+			return models.PasoLL1Info(p)
+		})
+	}
+	return resultado
+}
+
+// nombresProducciones numera las producciones como las referencian las tablas
+func nombresProducciones(g *gramatica.Gramatica) []string {
+	producciones := make([]string, len(g.Producciones))
+	for i, p := range g.Producciones {
+		producciones[i] = p.String()
+	}
+	return producciones
+}
+
+// conflictosLL1 convierte los conflictos de una tabla LL(1) a ConflictoLL1Info
+func conflictosLL1(conflictos []gramatica.ConflictoLL1) []models.ConflictoLL1Info {
+	return convertir(conflictos, func(c gramatica.ConflictoLL1) models.ConflictoLL1Info {
+		return models.ConflictoLL1Info(c)
+	})
+}
+
+// trazaInfo convierte la traza de un análisis con una tabla, con paso para
+// convertir cada uno de sus pasos
+func trazaInfo[P, Q any](traza *gramatica.TrazaAnalisis[P], paso func(P) Q) *models.TrazaAnalisisInfo[Q] {
+	return &models.TrazaAnalisisInfo[Q]{
+		Aceptada: traza.Aceptada,
+		Pasos:    convertir(traza.Pasos, paso),
+		Error:    traza.Error,
+		Linea:    traza.Linea,
+		Columna:  traza.Columna,
+	}
+}
+
+// resultadoLR construye las tablas con el método dado y analiza el código
+// si no está vacío. Devuelve un error que envuelve gramatica.ErrLimite si la
+// colección de estados es demasiado grande.
+func resultadoLR(g *gramatica.Gramatica, metodo gramatica.MetodoLR, codigo string, dialecto *lexer.Dialecto) (*models.ResultadoLR, error) {
+	tabla, err := g.TablaLR(metodo)
+	if err != nil {
+		return nil, err
+	}
+	resultado := &models.ResultadoLR{
+		Gramatica:     g.String(),
+		Producciones:  nombresProducciones(g),
+		Advertencias:  append([]string{}, g.Advertencias...),
+		Tabla:         tablaLRInfo(tabla),
+		SinConflictos: tabla.SinConflictos(),
+	}
+	if codigo != "" {
+		traza := tabla.Analizar(lexer.New(codigo, lexer.ConDialecto(dialecto)).Analizar())
+		resultado.Traza = trazaInfo(traza, func(p gramatica.PasoLR) models.PasoLRInfo {
+			return models.PasoLRInfo(p)
+		})
+	}
+	return resultado, nil
+}
+
+// tablaLRInfo convierte las tablas ACCION e IR_A a TablaLRInfo
+func tablaLRInfo(tabla *gramatica.TablaLR) *models.TablaLRInfo {
+	acciones := func(a []gramatica.AccionLR) []models.AccionLRInfo {
+		return convertir(a, func(a gramatica.AccionLR) models.AccionLRInfo {
+			return models.AccionLRInfo(a)
+		})
+	}
+	return &models.TablaLRInfo{
+		Metodo:    string(tabla.Metodo),
+		Aumentada: models.ProduccionInfo(tabla.Aumentada),
+		Estados: convertir(tabla.Estados, func(e gramatica.EstadoLR) models.EstadoLRInfo {
+			return models.EstadoLRInfo(e)
+		}),
+		Terminales:   tabla.Terminales,
+		NoTerminales: tabla.NoTerminales,
+		Accion: convertir(tabla.Accion, func(fila map[string][]gramatica.AccionLR) map[string][]models.AccionLRInfo {
+			if fila == nil {
+				return nil
+			}
+			info := make(map[string][]models.AccionLRInfo, len(fila))
+			for terminal, a := range fila {
+				info[terminal] = acciones(a)
+			}
+			return info
+		}),
+		IrA: tabla.IrA,
+		Conflictos: convertir(tabla.Conflictos, func(c gramatica.ConflictoLR) models.ConflictoLRInfo {
+			return models.ConflictoLRInfo{
+				Estado:   c.Estado,
+				Terminal: c.Terminal,
+				Tipo:     c.Tipo,
+				Acciones: acciones(c.Acciones),
+			}
+		}),
+	}
+}
+
+// resultadoGramaticaParser exporta la gramática del parser con los lexemas
+// del dialecto y verifica que sea LL(1), como requiere el análisis
+// descendente con un token de anticipación
+func resultadoGramaticaParser(dialecto *lexer.Dialecto) (*models.ResultadoGramaticaParser, error) {
+	resultado := &models.ResultadoGramaticaParser{
+		EBNF:       parser.EBNF(dialecto),
+		Reglas:     []models.ReglaGramatica{},
+		Conflictos: []models.ConflictoLL1Info{},
+	}
+	var producciones []gramatica.Produccion
+	for _, p := range parser.Producciones() {
+		resultado.Producciones = append(resultado.Producciones, p.String())
+		producciones = append(producciones, gramatica.Produccion{Izquierda: p.Izquierda, Derecha: p.Derecha})
+	}
+	for _, r := range parser.Gramatica() {
+		resultado.Reglas = append(resultado.Reglas, models.ReglaGramatica{
+			Nombre:   r.Nombre,
+			EBNF:     r.Cuerpo.EBNF(dialecto),
+			Cuerpo:   elementoInfo(r.Cuerpo),
+			Diagrama: diagrama.Regla(r, dialecto),
+		})
+	}
+
+	g, err := gramatica.Nueva(parser.REGLA_PROGRAMA, producciones)
+	if err != nil {
+		return nil, err
+	}
+	tabla := g.TablaLL1()
+	resultado.EsLL1 = tabla.EsLL1()
+	resultado.Conflictos = conflictosLL1(tabla.Conflictos)
+	return resultado, nil
+}
+
+// elementoInfo convierte una expresión EBNF y sus subexpresiones a
+// ElementoInfo
+func elementoInfo(e parser.Elemento) models.ElementoInfo {
+	return models.ElementoInfo{
+		Tipo:    string(e.Tipo),
+		Simbolo: e.Simbolo,
+		Hijos:   convertir(e.Hijos, elementoInfo),
+	}
+}
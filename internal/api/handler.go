@@ -2,82 +2,213 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
-	 "fmt"
+	"analyzer-api/internal/compilacion"
 	"analyzer-api/internal/lexer"
-	"analyzer-api/internal/parser"
-	"analyzer-api/internal/semantic"
+	"analyzer-api/internal/traza"
 	"analyzer-api/pkg/models"
 )
 
 // AnalyzerHandler maneja las solicitudes a la API del analizador
-type AnalyzerHandler struct{}
+type AnalyzerHandler struct {
+	dialectos  map[string]*lexer.Dialecto // dialectos disponibles por nombre
+	documentos *almacenDocumentos         // documentos abiertos para edición incremental
+	lotes      *almacenLotes              // resultados de los lotes de métricas
+}
 
-// NewAnalyzerHandler crea un nuevo manejador para el analizador
-func NewAnalyzerHandler() *AnalyzerHandler {
-	return &AnalyzerHandler{}
+// NewAnalyzerHandler crea un nuevo manejador para el analizador con los
+// dialectos cargados al iniciar el servidor
+func NewAnalyzerHandler(dialectos map[string]*lexer.Dialecto) *AnalyzerHandler {
+	if dialectos == nil {
+		dialectos = map[string]*lexer.Dialecto{}
+	}
+	base := lexer.DialectoBase()
+	if _, ok := dialectos[base.Nombre]; !ok {
+		dialectos[base.Nombre] = base
+	}
+	return &AnalyzerHandler{
+		dialectos:  dialectos,
+		documentos: nuevoAlmacenDocumentos(),
+		lotes:      nuevoAlmacenLotes(),
+	}
 }
 
 // SolicitudAnalisis representa la solicitud para analizar código
 type SolicitudAnalisis struct {
-	Codigo string `json:"codigo"`
+	Codigo   string `json:"codigo"`
+	Dialecto string `json:"dialecto,omitempty"` // vacío para el dialecto base
+	Traza    bool   `json:"traza,omitempty"`    // devolver la traza de las fases
+	CST      bool   `json:"cst,omitempty"`      // devolver el árbol concreto y la derivación
+	Ejecutar bool   `json:"ejecutar,omitempty"` // ejecutar el programa si no tiene errores
+	Entrada  string `json:"entrada,omitempty"`  // enteros que lee read, separados por espacios
+
+	// Fases cuyos resultados se devuelven: "lexico", "sintactico" y
+	// "semantico". Vacío para todas.
+	Fases []string `json:"fases,omitempty"`
+}
+
+// dialecto busca el dialecto solicitado por nombre
+func (h *AnalyzerHandler) dialecto(nombre string) (*lexer.Dialecto, bool) {
+	if nombre == "" {
+		return lexer.DialectoBase(), true
+	}
+	d, ok := h.dialectos[nombre]
+	return d, ok
 }
 
-// AnalizarCodigo analiza el código fuente
+// AnalizarCodigo analiza el código fuente. Si la solicitud elige fases,
+// solo se ejecutan las necesarias para obtenerlas y el resultado contiene
+// únicamente lo que producen las elegidas.
 func (h *AnalyzerHandler) AnalizarCodigo(w http.ResponseWriter, r *http.Request) {
+	if !prepararSolicitud(w, r) {
+		return
+	}
+	
+	// Decodificar la solicitud
+	var solicitud SolicitudAnalisis
+	if !decodificarSolicitud(w, r, &solicitud) {
+		return
+	}
+	
+	fases, err := models.SeleccionarFases(solicitud.Fases)
+	if err != nil {
+		http.Error(w, "Fases inválidas: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if solicitud.Ejecutar && len(fases) < len(models.Fases) {
+		http.Error(w, "Para ejecutar el programa se necesitan todas las fases", http.StatusBadRequest)
+		return
+	}
+
+	u, memoria, ok := h.unidadSolicitud(w, solicitud)
+	if !ok {
+		return
+	}
+
+	// Crear el resultado. La unidad ejecuta solo las fases necesarias.
+	resultado := resultadoAnalisisFases(u, fases)
+	resultado.Traza, resultado.TrazaDescartados = eventosTraza(memoria)
+	if solicitud.Ejecutar {
+		resultado.Ejecucion = resultadoEjecucion(r.Context(), u.Programa(), resultado.Errores, solicitud.Entrada)
+	}
+
+	responderJSON(w, resultado)
+}
+
+// unidadSolicitud crea la unidad de compilación para el código de la
+// solicitud con el dialecto, la traza y el árbol concreto que pide. La
+// memoria de la traza es nil si no se pidió. Devuelve false si la solicitud
+// ya fue respondida con un error.
+func (h *AnalyzerHandler) unidadSolicitud(w http.ResponseWriter, solicitud SolicitudAnalisis) (*compilacion.Unidad, *traza.Memoria, bool) {
+	dialecto, ok := h.dialecto(solicitud.Dialecto)
+	if !ok {
+		http.Error(w, "Dialecto desconocido: "+solicitud.Dialecto, http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	opciones := []compilacion.Opcion{compilacion.ConDialecto(dialecto)}
+
+	// La traza solo se recolecta si el cliente la pide
+	var memoria *traza.Memoria
+	if solicitud.Traza {
+		memoria = traza.NuevaMemoria(0)
+		opciones = append(opciones, compilacion.ConTraza(memoria))
+	}
+
+	if solicitud.CST {
+		opciones = append(opciones, compilacion.ConArbolConcreto())
+	}
+	return compilacion.Nueva(solicitud.Codigo, opciones...), memoria, true
+}
+
+// eventosTraza devuelve los eventos registrados y cuántos se descartaron, o
+// nada si la solicitud no pidió la traza
+func eventosTraza(memoria *traza.Memoria) ([]models.EventoInfo, int) {
+	if memoria == nil {
+		return nil, 0
+	}
+	eventos := []models.EventoInfo{}
+	for _, e := range memoria.Eventos() {
+		eventos = append(eventos, models.EventoInfo{
+			Fase:        e.Fase,
+			Tipo:        string(e.Tipo),
+			Regla:       e.Regla,
+			TipoToken:   e.TipoToken,
+			Lexema:      e.Lexema,
+			Mensaje:     e.Mensaje,
+			Linea:       e.Linea,
+			Columna:     e.Columna,
+			Profundidad: e.Profundidad,
+		})
+	}
+	return eventos, memoria.Descartados()
+}
+
+// prepararSolicitud establece los encabezados CORS y verifica que el método
+// sea POST. Devuelve false si la solicitud ya fue respondida.
+func prepararSolicitud(w http.ResponseWriter, r *http.Request) bool {
+	return prepararSolicitudMetodo(w, r, http.MethodPost)
+}
+
+// prepararSolicitudMetodo es como prepararSolicitud para un método dado
+func prepararSolicitudMetodo(w http.ResponseWriter, r *http.Request, metodo string) bool {
 	// Establecer encabezados CORS
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Methods", metodo+", OPTIONS")
 	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 	
 	// Manejar solicitudes OPTIONS (preflight)
 	if r.Method == http.MethodOptions {
 		w.WriteHeader(http.StatusOK)
-		return
+		return false
 	}
 	
-	// Verificar que el método sea POST
-	if r.Method != http.MethodPost {
+	// Verificar el método
+	if r.Method != metodo {
 		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
-		return
+		return false
 	}
-	
-	// Decodificar la solicitud
-	var solicitud SolicitudAnalisis
+	return true
+}
+
+// maxCuerpoSolicitud limita el tamaño en bytes del cuerpo de una solicitud
+const maxCuerpoSolicitud = 1 << 20
+
+// decodificarSolicitud lee el cuerpo JSON de la solicitud en destino, de
+// hasta maxCuerpoSolicitud bytes. Devuelve false si la solicitud ya fue
+// respondida con un error.
+func decodificarSolicitud(w http.ResponseWriter, r *http.Request, destino interface{}) bool {
+	return decodificarSolicitudLimite(w, r, destino, maxCuerpoSolicitud)
+}
+
+// decodificarSolicitudLimite es como decodificarSolicitud con un tamaño
+// máximo dado
+func decodificarSolicitudLimite(w http.ResponseWriter, r *http.Request, destino interface{}, limite int64) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, limite)
 	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&solicitud); err != nil {
+	if err := decoder.Decode(destino); err != nil {
+		var demasiado *http.MaxBytesError
+		if errors.As(err, &demasiado) {
+			http.Error(w, "La solicitud supera el tamaño máximo", http.StatusRequestEntityTooLarge)
+			return false
+		}
 		http.Error(w, "Error al decodificar la solicitud: "+err.Error(), http.StatusBadRequest)
-		return
+		return false
 	}
-	
-	fmt.Println("Código a analizar:", solicitud.Codigo)
-	
-	// Realizar el análisis
-	l := lexer.New(solicitud.Codigo)
-	fmt.Println("Analizador léxico creado")
-	
-	p := parser.New(l)
-	fmt.Println("Analizador sintáctico creado")
-	
-	ast := p.Parse()
-	fmt.Println("AST generado")
-	
-	sem := semantic.New(ast)
-	fmt.Println("Analizador semántico creado")
-	
-	// Crear el resultado
-	resultado := models.NuevoResultadoAnalisis(l, p, sem, solicitud.Codigo)
-	fmt.Println("Resultado generado")
-	
+	return true
+}
+
+// responderJSON codifica y envía la respuesta. Devuelve false si falló.
+func responderJSON(w http.ResponseWriter, respuesta interface{}) bool {
 	// Establecer encabezado de tipo de contenido
 	w.Header().Set("Content-Type", "application/json")
 	
 	// Codificar y enviar la respuesta
 	encoder := json.NewEncoder(w)
-	if err := encoder.Encode(resultado); err != nil {
+	if err := encoder.Encode(respuesta); err != nil {
 		http.Error(w, "Error al codificar la respuesta: "+err.Error(), http.StatusInternalServerError)
-		return
+		return false
 	}
-	
-	fmt.Println("Respuesta enviada correctamente")
+	return true
 }
\ No newline at end of file
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+
+	"analyzer-api/internal/compilacion"
+	"analyzer-api/internal/lexer"
+	"analyzer-api/internal/metricas"
+	"analyzer-api/pkg/models"
+)
+
+// maxLotes limita cuántos lotes de métricas se conservan en memoria; al
+// superarlo se descarta el más antiguo
+const maxLotes = 100
+
+// almacenLotes guarda los resultados de los lotes de métricas
+type almacenLotes struct {
+	mu    sync.Mutex
+	lotes map[string]*models.ResultadoLote
+	orden []string // del más antiguo al más reciente
+}
+
+func nuevoAlmacenLotes() *almacenLotes {
+	return &almacenLotes{lotes: map[string]*models.ResultadoLote{}}
+}
+
+func (a *almacenLotes) guardar(lote *models.ResultadoLote) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lotes[lote.LoteID] = lote
+	a.orden = append(a.orden, lote.LoteID)
+	for len(a.orden) > maxLotes {
+		delete(a.lotes, a.orden[0])
+		a.orden = a.orden[1:]
+	}
+}
+
+func (a *almacenLotes) obtener(id string) (*models.ResultadoLote, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	lote, ok := a.lotes[id]
+	return lote, ok
+}
+
+// resumenes devuelve el resumen de cada lote, sin las métricas de sus
+// programas, del más antiguo al más reciente
+func (a *almacenLotes) resumenes() []models.ResultadoLote {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	resultado := make([]models.ResultadoLote, 0, len(a.orden))
+	for _, id := range a.orden {
+		resultado = append(resultado, models.ResultadoLote{LoteID: id, Resumen: a.lotes[id].Resumen})
+	}
+	return resultado
+}
+
+// ProgramaLote es un programa de un lote de métricas
+type ProgramaLote struct {
+	Nombre string `json:"nombre"`
+	Codigo string `json:"codigo"`
+}
+
+// SolicitudLote representa un lote de programas para calcular sus métricas
+type SolicitudLote struct {
+	Programas []ProgramaLote `json:"programas"`
+	Dialecto  string         `json:"dialecto,omitempty"` // vacío para el dialecto base
+}
+
+// LotesMetricas con POST calcula las métricas de un lote de programas y
+// guarda su resumen; con GET devuelve el lote indicado en el parámetro id o,
+// sin él, el resumen de todos los lotes guardados
+func (h *AnalyzerHandler) LotesMetricas(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		h.obtenerLote(w, r)
+		return
+	}
+	if !prepararSolicitud(w, r) {
+		return
+	}
+
+	var solicitud SolicitudLote
+	if !decodificarSolicitud(w, r, &solicitud) {
+		return
+	}
+	dialecto, ok := h.dialecto(solicitud.Dialecto)
+	if !ok {
+		http.Error(w, "Dialecto desconocido: "+solicitud.Dialecto, http.StatusBadRequest)
+		return
+	}
+
+	lote := resultadoLote(nuevoID(), solicitud.Programas, dialecto)
+	h.lotes.guardar(lote)
+
+	responderJSON(w, lote)
+}
+
+func (h *AnalyzerHandler) obtenerLote(w http.ResponseWriter, r *http.Request) {
+	if !prepararSolicitudMetodo(w, r, http.MethodGet) {
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		responderJSON(w, h.lotes.resumenes())
+		return
+	}
+	lote, ok := h.lotes.obtener(id)
+	if !ok {
+		http.Error(w, "Lote desconocido: "+id, http.StatusNotFound)
+		return
+	}
+	responderJSON(w, lote)
+}
+
+// resultadoLote analiza los programas de un lote y crea el resultado con
+// sus métricas y el resumen de todas ellas
+func resultadoLote(id string, programas []ProgramaLote, dialecto *lexer.Dialecto) *models.ResultadoLote {
+	resultado := &models.ResultadoLote{
+		LoteID:    id,
+		Programas: make([]models.MetricasPrograma, len(programas)),
+	}
+	lote := make([]metricas.Metricas, len(programas))
+	for i, programa := range programas {
+		u := compilacion.Nueva(programa.Codigo, compilacion.ConDialecto(dialecto))
+		lote[i] = metricas.Calcular(u.Tokens(), u.Programa())
+		resultado.Programas[i] = models.MetricasPrograma{
+			Nombre:   programa.Nombre,
+			Errores:  len(u.ErroresLexicos()) + len(u.ErroresSintacticos()) + len(u.ErroresSemanticos()),
+			Metricas: metricasInfo(lote[i]),
+		}
+	}
+
+	resumen := metricas.Resumir(lote)
+	resultado.Resumen = models.ResumenInfo{
+		Programas:               resumen.Programas,
+		Sentencias:              models.EstadisticaInfo(resumen.Sentencias),
+		ProfundidadCiclos:       models.EstadisticaInfo(resumen.ProfundidadCiclos),
+		ComplejidadCiclomatica:  models.EstadisticaInfo(resumen.ComplejidadCiclomatica),
+		VolumenHalstead:         models.EstadisticaInfo(resumen.VolumenHalstead),
+		EsfuerzoHalstead:        models.EstadisticaInfo(resumen.EsfuerzoHalstead),
+		Variables:               models.EstadisticaInfo(resumen.Variables),
+		LongitudIdentificadores: models.EstadisticaInfo(resumen.LongitudIdentificadores),
+	}
+	return resultado
+}
@@ -0,0 +1,298 @@
+package api
+
+import (
+	"sort"
+
+	"analyzer-api/internal/compilacion"
+	"analyzer-api/internal/lexer"
+	"analyzer-api/internal/metricas"
+	"analyzer-api/internal/parser"
+	"analyzer-api/internal/semantic"
+	"analyzer-api/pkg/models"
+)
+
+// Los resultados de las fases se construyen aquí y no en models para que
+// ese paquete no dependa de la unidad de compilación, que es interna.
+
+// resultadoAnalisis crea el resultado completo del análisis de una
+// unidad de compilación
+func resultadoAnalisis(u *compilacion.Unidad) *models.ResultadoAnalisis {
+	return resultadoAnalisisFases(u, models.Fases)
+}
+
+// resultadoAnalisisFases crea un resultado de análisis que contiene
+// solo los tokens, el árbol, los errores y los símbolos de las fases dadas.
+// En la unidad se ejecutan únicamente las fases necesarias para obtenerlos.
+func resultadoAnalisisFases(u *compilacion.Unidad, fases []string) *models.ResultadoAnalisis {
+	resultado := &models.ResultadoAnalisis{
+		Fases:        fases,
+		Tokens:       []models.TokenInfo{},
+		Errores:      []models.ErrorInfo{},
+		Advertencias: []models.ErrorInfo{},
+		Simbolos:     []models.SimboloInfo{},
+		CodigoFuente: u.Codigo(),
+	}
+
+	if models.IncluyeFase(fases, models.FASE_LEXICA) {
+		lexico := resultadoLexico(u)
+		resultado.Tokens = lexico.Tokens
+		resultado.ConteoTokens = lexico.ConteoTokens
+		resultado.Errores = append(resultado.Errores, lexico.Errores...)
+	}
+
+	if models.IncluyeFase(fases, models.FASE_SINTACTICA) {
+		sintactico := resultadoSintactico(u)
+		resultado.AST = sintactico.AST
+		resultado.CST = sintactico.CST
+		resultado.Derivacion = sintactico.Derivacion
+		resultado.Errores = append(resultado.Errores, sintactico.Errores...)
+	}
+
+	if models.IncluyeFase(fases, models.FASE_SEMANTICA) {
+		semantico := resultadoSemantico(u)
+		resultado.Errores = append(resultado.Errores, semantico.Errores...)
+		resultado.Advertencias = semantico.Advertencias
+		resultado.Simbolos = semantico.Simbolos
+	}
+
+	// Las métricas necesitan el árbol, así que solo se calculan si alguna
+	// fase pedida ya lo construyó
+	if models.IncluyeFase(fases, models.FASE_SINTACTICA) || models.IncluyeFase(fases, models.FASE_SEMANTICA) {
+		m := metricasInfo(metricas.Calcular(u.Tokens(), u.Programa()))
+		resultado.Metricas = &m
+	}
+
+	return resultado
+}
+
+// textoFuente devuelve el texto del código fuente que ocupa una expresión,
+// o "" si no hay expresión
+func textoFuente(codigo string, expr parser.Expresion) string {
+	if expr == nil {
+		return ""
+	}
+	rango := expr.Ubicacion()
+	if rango.Inicio.Offset < 0 || rango.Fin.Offset > len(codigo) || rango.Inicio.Offset > rango.Fin.Offset {
+		return ""
+	}
+	return codigo[rango.Inicio.Offset:rango.Fin.Offset]
+}
+
+// sitios convierte las posiciones de uso de un símbolo a SitioInfo
+func sitios(posiciones []semantic.Sitio) []models.SitioInfo {
+	var resultado []models.SitioInfo
+	for _, p := range posiciones {
+		resultado = append(resultado, models.SitioInfo{Linea: p.Linea, Columna: p.Columna})
+	}
+	return resultado
+}
+
+// resultadoLexico crea el resultado con los tokens de la unidad, su
+// conteo y los errores léxicos
+func resultadoLexico(u *compilacion.Unidad) *models.ResultadoLexico {
+	resultado := &models.ResultadoLexico{
+		Tokens:  []models.TokenInfo{},
+		Errores: []models.ErrorInfo{},
+	}
+
+	// Convertir tokens a models.TokenInfo
+	tokens := u.Tokens()
+	for _, token := range tokens {
+		resultado.Tokens = append(resultado.Tokens, models.TokenInfo{
+			Tipo:      token.Clase(),
+			Lexema:    token.Lexeme,
+			Linea:     token.Line,
+			Columna:   token.Column,
+			Categoria: token.Categoria(),
+			TipoToken: string(token.Type),
+		})
+	}
+
+	// Obtener conteo de tokens
+	conteo := u.ConteoTokens()
+	resultado.ConteoTokens = models.ConteoTokens{
+		PR:       conteo[lexer.CATEGORIA_PR],
+		ID:       conteo[lexer.CATEGORIA_ID],
+		Numeros:  conteo[lexer.CATEGORIA_NUMEROS],
+		Simbolos: conteo[lexer.CATEGORIA_SIMBOLOS],
+		Error:    conteo[lexer.CATEGORIA_ERROR],
+		Total:    len(tokens) - 1, // Restamos 1 para no contar EOF
+	}
+
+	// Convertir errores léxicos a models.ErrorInfo
+	for _, err := range u.ErroresLexicos() {
+		resultado.Errores = append(resultado.Errores, models.ErrorInfo{
+			Tipo:    models.FASE_LEXICA,
+			Mensaje: err.Mensaje,
+			Linea:   err.Linea,
+			Columna: err.Columna,
+		})
+	}
+
+	return resultado
+}
+
+// resultadoSintactico crea el resultado con el árbol de la unidad y
+// los errores de sintaxis
+func resultadoSintactico(u *compilacion.Unidad) *models.ResultadoSintactico {
+	resultado := &models.ResultadoSintactico{
+		AST:     nodoInfo(parser.CodificarNodo(u.Programa())),
+		Errores: []models.ErrorInfo{},
+	}
+
+	// Convertir errores sintácticos a models.ErrorInfo
+	for _, err := range u.ErroresSintacticos() {
+		resultado.Errores = append(resultado.Errores, models.ErrorInfo{
+			Tipo:    models.FASE_SINTACTICA,
+			Mensaje: err.Mensaje,
+			Linea:   err.Linea,
+			Columna: err.Columna,
+		})
+	}
+
+	if cst := u.ArbolConcreto(); cst != nil {
+		resultado.CST = nodoConcretoInfo(cst)
+		resultado.Derivacion = convertir(cst.Derivacion(), func(paso parser.PasoDerivacion) models.PasoDerivacionInfo {
+			return models.PasoDerivacionInfo{
+				Produccion: models.ProduccionInfo(paso.Produccion),
+				Posicion:   paso.Posicion,
+			}
+		})
+	}
+
+	return resultado
+}
+
+// resultadoSemantico crea el resultado con los errores semánticos de
+// la unidad, sus advertencias y la tabla de símbolos
+func resultadoSemantico(u *compilacion.Unidad) *models.ResultadoSemantico {
+	resultado := &models.ResultadoSemantico{
+		Errores:      []models.ErrorInfo{},
+		Advertencias: []models.ErrorInfo{},
+		Simbolos:     []models.SimboloInfo{},
+	}
+
+	// Convertir errores semánticos a models.ErrorInfo
+	for _, err := range u.ErroresSemanticos() {
+		resultado.Errores = append(resultado.Errores, models.ErrorInfo{
+			Tipo:    models.FASE_SEMANTICA,
+			Mensaje: err.Mensaje,
+			Linea:   err.Linea,
+			Columna: err.Columna,
+		})
+	}
+
+	// Convertir advertencias a models.ErrorInfo
+	for _, adv := range u.Advertencias() {
+		resultado.Advertencias = append(resultado.Advertencias, models.ErrorInfo{
+			Tipo:    models.FASE_SEMANTICA,
+			Mensaje: adv.Mensaje,
+			Linea:   adv.Linea,
+			Columna: adv.Columna,
+			Codigo:  adv.Codigo,
+		})
+	}
+
+	// Convertir símbolos a models.SimboloInfo
+	for _, simbolo := range u.TablaSimbolos().ListarSimbolos() {
+		resultado.Simbolos = append(resultado.Simbolos, models.SimboloInfo{
+			Nombre:        simbolo.Nombre,
+			Tipo:          simbolo.Tipo,
+			Valor:         simbolo.Valor,
+			Linea:         simbolo.Linea,
+			Columna:       simbolo.Columna,
+			Categoria:     simbolo.Categoria,
+			Ambito:        simbolo.Ambito,
+			Firma:         simbolo.Firma(),
+			Dimensiones:   simbolo.Dimensiones,
+			Inicializador: textoFuente(u.Codigo(), simbolo.Inicializador),
+			ValorInicial:  simbolo.ValorInicial,
+			Asignaciones:  sitios(simbolo.Asignaciones),
+			Lecturas:      simbolo.Lecturas,
+			Escrituras:    simbolo.Escrituras,
+		})
+	}
+	// En el orden del código fuente
+	sort.Slice(resultado.Simbolos, func(i, j int) bool {
+		a, b := resultado.Simbolos[i], resultado.Simbolos[j]
+		if a.Linea != b.Linea {
+			return a.Linea < b.Linea
+		}
+		if a.Columna != b.Columna {
+			return a.Columna < b.Columna
+		}
+		return a.Nombre < b.Nombre
+	})
+
+	return resultado
+}
+
+// convertir aplica f a cada elemento de xs. Conserva la diferencia entre
+// una lista nil y una vacía, que en JSON son null y [].
+func convertir[T, U any](xs []T, f func(T) U) []U {
+	if xs == nil {
+		return nil
+	}
+	resultado := make([]U, len(xs))
+	for i, x := range xs {
+		resultado[i] = f(x)
+	}
+	return resultado
+}
+
+// rangoInfo convierte el rango de un nodo a RangoInfo
+func rangoInfo(r parser.Rango) models.RangoInfo {
+	return models.RangoInfo{
+		Inicio: models.PosicionInfo(r.Inicio),
+		Fin:    models.PosicionInfo(r.Fin),
+	}
+}
+
+// nodoInfo convierte un nodo serializable del AST y sus descendientes a
+// NodoInfo. Devuelve nil si j es nil.
+func nodoInfo(j *parser.NodoJSON) *models.NodoInfo {
+	if j == nil {
+		return nil
+	}
+	n := &models.NodoInfo{
+		Kind:      j.Kind,
+		Rol:       j.Rol,
+		Rango:     rangoInfo(j.Rango),
+		Tipo:      j.Tipo,
+		Nombre:    j.Nombre,
+		Valor:     j.Valor,
+		Operador:  j.Operador,
+		Constante: j.Constante,
+		Children:  convertir(j.Children, nodoInfo),
+	}
+	return n
+}
+
+// nodoConcretoInfo convierte un nodo del árbol concreto y sus descendientes
+// a NodoConcretoInfo
+func nodoConcretoInfo(c *parser.NodoConcreto) *models.NodoConcretoInfo {
+	n := &models.NodoConcretoInfo{
+		Simbolo:  c.Simbolo,
+		Terminal: c.Terminal,
+		Lexema:   c.Lexema,
+		Rango:    rangoInfo(c.Rango),
+		Hijos:    convertir(c.Hijos, nodoConcretoInfo),
+	}
+	return n
+}
+
+// metricasInfo convierte las métricas de un programa a MetricasInfo
+func metricasInfo(m metricas.Metricas) models.MetricasInfo {
+	info := models.MetricasInfo{
+		Sentencias:              m.Sentencias,
+		ProfundidadCiclos:       m.ProfundidadCiclos,
+		ComplejidadCiclomatica:  m.ComplejidadCiclomatica,
+		Halstead:                models.HalsteadInfo(m.Halstead),
+		Variables:               m.Variables,
+		LongitudIdentificadores: m.LongitudIdentificadores,
+		Funciones: convertir(m.Funciones, func(f metricas.MetricasFuncion) models.MetricasFuncionInfo {
+			return models.MetricasFuncionInfo(f)
+		}),
+	}
+	return info
+}
@@ -1,19 +1,34 @@
 package api
 
 import (
+	"log"
 	"net/http"
+
+	"analyzer-api/internal/lexer"
 )
 
 // Router configura las rutas de la API
-func SetupRouter() http.Handler {
+func SetupRouter(dialectos map[string]*lexer.Dialecto) http.Handler {
 	// Crear el manejador
-	handler := NewAnalyzerHandler()
+	handler := NewAnalyzerHandler(dialectos)
 	
 	// Crear el multiplexor
 	mux := http.NewServeMux()
 	
 	// Configurar rutas
 	mux.HandleFunc("/api/analyze", handler.AnalizarCodigo)
+	mux.HandleFunc("/api/lex", handler.AnalizarLexico)
+	mux.HandleFunc("/api/parse", handler.AnalizarSintaxis)
+	mux.HandleFunc("/api/semantic", handler.AnalizarSemantica)
+	mux.HandleFunc("/api/automata", handler.GenerarAutomata)
+	mux.HandleFunc("/api/documentos", handler.AbrirDocumento)
+	mux.HandleFunc("/api/documentos/editar", handler.EditarDocumento)
+	mux.HandleFunc("/api/format", handler.FormatearCodigo)
+	mux.HandleFunc("/api/run", handler.EjecutarCodigo)
+	mux.HandleFunc("/api/metricas/lotes", handler.LotesMetricas)
+	mux.HandleFunc("/api/gramatica/ll1", handler.AnalizarLL1)
+	mux.HandleFunc("/api/gramatica/lr", handler.AnalizarLR)
+	mux.HandleFunc("/api/grammar", handler.ObtenerGramatica)
 	
 	// Agregar middleware para logging
 	return loggingMiddleware(mux)
@@ -22,8 +37,7 @@ func SetupRouter() http.Handler {
 // loggingMiddleware registra las solicitudes HTTP
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Loggear la solicitud (en una implementación real usaríamos un logger)
-		println(r.Method, r.URL.Path)
+		log.Printf("%s %s", r.Method, r.URL.Path)
 		
 		// Llamar al siguiente handler
 		next.ServeHTTP(w, r)
@@ -0,0 +1,235 @@
+package automata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EstadoAFD es un estado del autómata finito determinista
+type EstadoAFD struct {
+	Transiciones []int // destino por clase de símbolos, o -1
+	Acepta       int   // índice de la definición reconocida, o -1
+	Conjunto     []int // estados del AFN (o del AFD sin minimizar) que representa
+}
+
+// AFD es un autómata finito determinista sobre clases de bytes
+type AFD struct {
+	Clases       []Rango
+	Estados      []EstadoAFD
+	Inicial      int
+	Definiciones []Definicion
+	claseDe      [256]int
+}
+
+// Compilar construye el AFD mínimo que reconoce las definiciones
+func Compilar(definiciones []Definicion) (*AFD, error) {
+	afn, err := ConstruirAFN(definiciones)
+	if err != nil {
+		return nil, err
+	}
+	afd, err := afn.AFD()
+	if err != nil {
+		return nil, err
+	}
+	return afd.Minimizar(), nil
+}
+
+// AFD convierte el AFN en un AFD mediante la construcción de subconjuntos.
+// Devuelve un error que envuelve ErrLimite si el AFD supera MaxEstadosAFD
+// estados.
+func (afn *AFN) AFD() (*AFD, error) {
+	afd := &AFD{
+		Clases:       afn.particionAlfabeto(),
+		Definiciones: afn.Definiciones,
+	}
+	afd.indexarClases()
+
+	indice := map[string]int{}
+	agregar := func(conjunto []int) int {
+		clave := claveConjunto(conjunto)
+		if id, ok := indice[clave]; ok {
+			return id
+		}
+		id := len(afd.Estados)
+		indice[clave] = id
+		afd.Estados = append(afd.Estados, EstadoAFD{
+			Conjunto: conjunto,
+			Acepta:   afn.aceptacion(conjunto),
+		})
+		return id
+	}
+
+	afd.Inicial = agregar(afn.cerradura([]int{afn.Inicial}))
+	for actual := 0; actual < len(afd.Estados); actual++ {
+		transiciones := make([]int, len(afd.Clases))
+		for c, clase := range afd.Clases {
+			var destinos []int
+			vistos := map[int]bool{}
+			for _, e := range afd.Estados[actual].Conjunto {
+				for _, t := range afn.Estados[e].Transiciones {
+					if t.Rango.Desde <= clase.Desde && clase.Hasta <= t.Rango.Hasta && !vistos[t.Destino] {
+						vistos[t.Destino] = true
+						destinos = append(destinos, t.Destino)
+					}
+				}
+			}
+			if len(destinos) == 0 {
+				transiciones[c] = -1
+				continue
+			}
+			transiciones[c] = agregar(afn.cerradura(destinos))
+		}
+		afd.Estados[actual].Transiciones = transiciones
+		if len(afd.Estados) > MaxEstadosAFD {
+			return nil, fmt.Errorf("%w: el AFD tiene más de %d estados", ErrLimite, MaxEstadosAFD)
+		}
+	}
+
+	return afd, nil
+}
+
+// aceptacion devuelve la definición de mayor prioridad aceptada por un
+// conjunto de estados del AFN
+func (afn *AFN) aceptacion(conjunto []int) int {
+	acepta := -1
+	for _, e := range conjunto {
+		if a := afn.Estados[e].Acepta; a >= 0 && (acepta < 0 || a < acepta) {
+			acepta = a
+		}
+	}
+	return acepta
+}
+
+// Minimizar devuelve un AFD equivalente con el mínimo número de estados,
+// refinando particiones (algoritmo de Moore) a partir de la definición que
+// acepta cada estado
+func (afd *AFD) Minimizar() *AFD {
+	grupo := make([]int, len(afd.Estados))
+	for i, estado := range afd.Estados {
+		grupo[i] = estado.Acepta + 1
+	}
+
+	for {
+		firmas := map[string]int{}
+		nuevo := make([]int, len(afd.Estados))
+		for i, estado := range afd.Estados {
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "%d", grupo[i])
+			for _, destino := range estado.Transiciones {
+				if destino < 0 {
+					sb.WriteString(",-")
+				} else {
+					fmt.Fprintf(&sb, ",%d", grupo[destino])
+				}
+			}
+			firma := sb.String()
+			id, ok := firmas[firma]
+			if !ok {
+				id = len(firmas)
+				firmas[firma] = id
+			}
+			nuevo[i] = id
+		}
+		estable := len(firmas) == contarDistintos(grupo)
+		grupo = nuevo
+		if estable {
+			break
+		}
+	}
+
+	// Numerar los grupos en el orden de recorrido desde el estado inicial
+	numero := map[int]int{}
+	var orden []int
+	cola := []int{afd.Inicial}
+	visitado := map[int]bool{afd.Inicial: true}
+	for len(cola) > 0 {
+		e := cola[0]
+		cola = cola[1:]
+		if _, ok := numero[grupo[e]]; !ok {
+			numero[grupo[e]] = len(orden)
+			orden = append(orden, e)
+		}
+		for _, destino := range afd.Estados[e].Transiciones {
+			if destino >= 0 && !visitado[destino] {
+				visitado[destino] = true
+				cola = append(cola, destino)
+			}
+		}
+	}
+
+	minimo := &AFD{
+		Clases:       afd.Clases,
+		Definiciones: afd.Definiciones,
+		Estados:      make([]EstadoAFD, len(orden)),
+		Inicial:      0,
+	}
+	minimo.indexarClases()
+	for i, representante := range orden {
+		transiciones := make([]int, len(afd.Clases))
+		for c, destino := range afd.Estados[representante].Transiciones {
+			if destino < 0 {
+				transiciones[c] = -1
+			} else {
+				transiciones[c] = numero[grupo[destino]]
+			}
+		}
+		var conjunto []int
+		for e := range afd.Estados {
+			if grupo[e] == grupo[representante] {
+				conjunto = append(conjunto, e)
+			}
+		}
+		minimo.Estados[i] = EstadoAFD{
+			Transiciones: transiciones,
+			Acepta:       afd.Estados[representante].Acepta,
+			Conjunto:     conjunto,
+		}
+	}
+	return minimo
+}
+
+// Siguiente devuelve el estado alcanzado desde estado con el byte b, o -1
+func (afd *AFD) Siguiente(estado int, b byte) int {
+	c := afd.claseDe[b]
+	if c < 0 || estado < 0 {
+		return -1
+	}
+	return afd.Estados[estado].Transiciones[c]
+}
+
+func (afd *AFD) indexarClases() {
+	for b := range afd.claseDe {
+		afd.claseDe[b] = -1
+	}
+	for c, clase := range afd.Clases {
+		for b := int(clase.Desde); b <= int(clase.Hasta); b++ {
+			afd.claseDe[b] = c
+		}
+	}
+}
+
+func claveConjunto(conjunto []int) string {
+	partes := make([]string, len(conjunto))
+	for i, e := range conjunto {
+		partes[i] = fmt.Sprint(e)
+	}
+	return strings.Join(partes, ",")
+}
+
+func conjuntoOrdenado(conjunto map[int]bool) []int {
+	resultado := make([]int, 0, len(conjunto))
+	for e := range conjunto {
+		resultado = append(resultado, e)
+	}
+	sort.Ints(resultado)
+	return resultado
+}
+
+func contarDistintos(valores []int) int {
+	distintos := map[int]bool{}
+	for _, v := range valores {
+		distintos[v] = true
+	}
+	return len(distintos)
+}
@@ -0,0 +1,196 @@
+package automata
+
+import (
+	"fmt"
+
+	"analyzer-api/internal/lexer"
+)
+
+// Definicion describe una categoría de token mediante una expresión regular.
+// Cuando varias definiciones reconocen el mismo lexema gana la primera.
+type Definicion struct {
+	Nombre  string          `json:"nombre"`
+	Patron  string          `json:"patron"`
+	Tipo    lexer.TokenType `json:"tipo,omitempty"`    // tipo emitido; por defecto el nombre
+	Ignorar bool            `json:"ignorar,omitempty"` // no emitir (espacios, comentarios)
+}
+
+// TransicionAFN es una transición del AFN con un rango de bytes
+type TransicionAFN struct {
+	Rango   Rango
+	Destino int
+}
+
+// EstadoAFN es un estado del autómata finito no determinista
+type EstadoAFN struct {
+	Epsilon      []int
+	Transiciones []TransicionAFN
+	Acepta       int // índice de la definición reconocida, o -1
+}
+
+// AFN es un autómata finito no determinista con transiciones épsilon
+// construido por el método de Thompson
+type AFN struct {
+	Estados      []EstadoAFN
+	Inicial      int
+	Definiciones []Definicion
+}
+
+// fragmento es un AFN parcial con un único estado inicial y uno final
+type fragmento struct {
+	inicio int
+	fin    int
+}
+
+// ConstruirAFN compila las definiciones en un único AFN cuyo estado inicial
+// tiene transiciones épsilon hacia el AFN de cada definición
+func ConstruirAFN(definiciones []Definicion) (*AFN, error) {
+	if len(definiciones) == 0 {
+		return nil, fmt.Errorf("no hay definiciones de tokens")
+	}
+	if len(definiciones) > MaxDefiniciones {
+		return nil, fmt.Errorf("%w: hay %d definiciones y el máximo es %d", ErrLimite, len(definiciones), MaxDefiniciones)
+	}
+
+	afn := &AFN{Definiciones: definiciones}
+	afn.Inicial = afn.nuevoEstado()
+
+	for i, def := range definiciones {
+		if def.Nombre == "" {
+			return nil, fmt.Errorf("la definición %d no tiene nombre", i+1)
+		}
+		if len(def.Patron) > MaxLongitudPatron {
+			return nil, fmt.Errorf("%w: el patrón de %s tiene %d bytes y el máximo es %d", ErrLimite, def.Nombre, len(def.Patron), MaxLongitudPatron)
+		}
+		arbol, err := analizarRegex(def.Patron)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", def.Nombre, err)
+		}
+		if _, vacia := arbol.(*regexVacia); vacia {
+			return nil, fmt.Errorf("%s: el patrón está vacío", def.Nombre)
+		}
+		frag := afn.thompson(arbol)
+		afn.Estados[frag.fin].Acepta = i
+		afn.agregarEpsilon(afn.Inicial, frag.inicio)
+		if len(afn.Estados) > MaxEstadosAFN {
+			return nil, fmt.Errorf("%w: el AFN tiene más de %d estados", ErrLimite, MaxEstadosAFN)
+		}
+	}
+
+	return afn, nil
+}
+
+func (afn *AFN) nuevoEstado() int {
+	afn.Estados = append(afn.Estados, EstadoAFN{Acepta: -1})
+	return len(afn.Estados) - 1
+}
+
+func (afn *AFN) agregarEpsilon(desde, hasta int) {
+	afn.Estados[desde].Epsilon = append(afn.Estados[desde].Epsilon, hasta)
+}
+
+// thompson construye el fragmento de AFN de un nodo de la expresión regular
+func (afn *AFN) thompson(nodo nodoRegex) fragmento {
+	switch n := nodo.(type) {
+	case *regexClase:
+		inicio, fin := afn.nuevoEstado(), afn.nuevoEstado()
+		for _, r := range n.rangos {
+			afn.Estados[inicio].Transiciones = append(afn.Estados[inicio].Transiciones,
+				TransicionAFN{Rango: r, Destino: fin})
+		}
+		return fragmento{inicio, fin}
+	case *regexConcatenacion:
+		izquierda := afn.thompson(n.izquierda)
+		derecha := afn.thompson(n.derecha)
+		afn.agregarEpsilon(izquierda.fin, derecha.inicio)
+		return fragmento{izquierda.inicio, derecha.fin}
+	case *regexAlternativa:
+		inicio := afn.nuevoEstado()
+		izquierda := afn.thompson(n.izquierda)
+		derecha := afn.thompson(n.derecha)
+		fin := afn.nuevoEstado()
+		afn.agregarEpsilon(inicio, izquierda.inicio)
+		afn.agregarEpsilon(inicio, derecha.inicio)
+		afn.agregarEpsilon(izquierda.fin, fin)
+		afn.agregarEpsilon(derecha.fin, fin)
+		return fragmento{inicio, fin}
+	case *regexEstrella:
+		inicio := afn.nuevoEstado()
+		sub := afn.thompson(n.sub)
+		fin := afn.nuevoEstado()
+		afn.agregarEpsilon(inicio, sub.inicio)
+		afn.agregarEpsilon(inicio, fin)
+		afn.agregarEpsilon(sub.fin, sub.inicio)
+		afn.agregarEpsilon(sub.fin, fin)
+		return fragmento{inicio, fin}
+	case *regexMas:
+		inicio := afn.nuevoEstado()
+		sub := afn.thompson(n.sub)
+		fin := afn.nuevoEstado()
+		afn.agregarEpsilon(inicio, sub.inicio)
+		afn.agregarEpsilon(sub.fin, sub.inicio)
+		afn.agregarEpsilon(sub.fin, fin)
+		return fragmento{inicio, fin}
+	case *regexOpcional:
+		inicio := afn.nuevoEstado()
+		sub := afn.thompson(n.sub)
+		fin := afn.nuevoEstado()
+		afn.agregarEpsilon(inicio, sub.inicio)
+		afn.agregarEpsilon(inicio, fin)
+		afn.agregarEpsilon(sub.fin, fin)
+		return fragmento{inicio, fin}
+	default: // regexVacia
+		inicio, fin := afn.nuevoEstado(), afn.nuevoEstado()
+		afn.agregarEpsilon(inicio, fin)
+		return fragmento{inicio, fin}
+	}
+}
+
+// cerradura calcula la cerradura épsilon de un conjunto de estados
+func (afn *AFN) cerradura(estados []int) []int {
+	visitado := make(map[int]bool, len(estados))
+	pila := append([]int(nil), estados...)
+	for _, e := range estados {
+		visitado[e] = true
+	}
+	for len(pila) > 0 {
+		e := pila[len(pila)-1]
+		pila = pila[:len(pila)-1]
+		for _, siguiente := range afn.Estados[e].Epsilon {
+			if !visitado[siguiente] {
+				visitado[siguiente] = true
+				pila = append(pila, siguiente)
+			}
+		}
+	}
+	return conjuntoOrdenado(visitado)
+}
+
+// particionAlfabeto divide los bytes usados en el AFN en clases disjuntas: dos
+// bytes de la misma clase tienen exactamente las mismas transiciones
+func (afn *AFN) particionAlfabeto() []Rango {
+	var cortes [257]bool
+	var usado [256]bool
+	for _, estado := range afn.Estados {
+		for _, t := range estado.Transiciones {
+			cortes[t.Rango.Desde] = true
+			cortes[int(t.Rango.Hasta)+1] = true
+			for b := int(t.Rango.Desde); b <= int(t.Rango.Hasta); b++ {
+				usado[b] = true
+			}
+		}
+	}
+
+	var clases []Rango
+	inicio := -1
+	for b := 0; b <= 256; b++ {
+		if inicio >= 0 && (b == 256 || cortes[b] || !usado[b]) {
+			clases = append(clases, Rango{byte(inicio), byte(b - 1)})
+			inicio = -1
+		}
+		if b < 256 && usado[b] && inicio < 0 {
+			inicio = b
+		}
+	}
+	return clases
+}
@@ -0,0 +1,134 @@
+package automata
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"analyzer-api/internal/lexer"
+)
+
+// mostrarTokens resume los tokens como TIPO:lexema@línea:columna
+func mostrarTokens(tokens []Token) []string {
+	var resumen []string
+	for _, tok := range tokens {
+		resumen = append(resumen, fmt.Sprintf("%s:%s@%d:%d", tok.Type, tok.Lexeme, tok.Line, tok.Column))
+	}
+	return resumen
+}
+
+func TestCompilarReconoce(t *testing.T) {
+	definiciones := []Definicion{
+		{Nombre: "IF", Patron: "if"},
+		{Nombre: "ID", Patron: "[a-z]+"},
+		{Nombre: "NUM", Patron: "[0-9]+(\\.[0-9]+)?"},
+		{Nombre: "ESPACIO", Patron: "[ \\n]+", Ignorar: true},
+	}
+	afd, err := Compilar(definiciones)
+	if err != nil {
+		t.Fatalf("Compilar: %v", err)
+	}
+
+	casos := []struct {
+		entrada string
+		tokens  string
+	}{
+		{"if", "IF:if@1:1 EOF:@1:3"},
+		{"iff", "ID:iff@1:1 EOF:@1:4"},
+		{"if x", "IF:if@1:1 ID:x@1:4 EOF:@1:5"},
+		{"3.14 7.", "NUM:3.14@1:1 NUM:7@1:6 ERROR:.@1:7 EOF:@1:8"},
+		{"a\nb", "ID:a@1:1 ID:b@2:1 EOF:@2:2"},
+		{"A", "ERROR:A@1:1 EOF:@1:2"},
+		{"", "EOF:@1:1"},
+	}
+	for _, c := range casos {
+		t.Run(c.entrada, func(t *testing.T) {
+			got := strings.Join(mostrarTokens(afd.NuevoLexer(c.entrada).Analizar()), " ")
+			if got != c.tokens {
+				t.Errorf("tokens %q, se esperaban %q", got, c.tokens)
+			}
+		})
+	}
+}
+
+// El AFD de DefinicionesBase debe reconocer lo mismo que el analizador
+// léxico escrito a mano
+func TestDefinicionesBaseComoLexer(t *testing.T) {
+	afd, err := Compilar(DefinicionesBase())
+	if err != nil {
+		t.Fatalf("Compilar: %v", err)
+	}
+
+	casos := []string{
+		"int x = 10;",
+		"while (x != 0) { x = x - 1; }",
+		"if (a <= b && c >= d || !e) { print(a); } else { print(b); }",
+		"// comentario\nint y = 2; /* bloque\n de dos líneas */ y = y * 3;",
+		"int arr[3]; arr[0] = arr[1] % 2;",
+		"x = @;",
+	}
+	for _, entrada := range casos {
+		t.Run(entrada, func(t *testing.T) {
+			got := mostrarTokens(afd.NuevoLexer(entrada).Analizar())
+			want := mostrarTokens(lexer.New(entrada).Analizar())
+			if strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Errorf("AFD:\n  %v\nlexer:\n  %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMinimizar(t *testing.T) {
+	// (a|b)*abb tiene 4 estados en el AFD mínimo
+	afn, err := ConstruirAFN([]Definicion{{Nombre: "X", Patron: "(a|b)*abb"}})
+	if err != nil {
+		t.Fatalf("ConstruirAFN: %v", err)
+	}
+	afd, err := afn.AFD()
+	if err != nil {
+		t.Fatalf("AFD: %v", err)
+	}
+	if n := len(afd.Minimizar().Estados); n != 4 {
+		t.Errorf("el AFD mínimo tiene %d estados, se esperaban 4", n)
+	}
+}
+
+func TestErroresDefiniciones(t *testing.T) {
+	muchas := make([]Definicion, MaxDefiniciones+1)
+	for i := range muchas {
+		muchas[i] = Definicion{Nombre: fmt.Sprintf("D%d", i), Patron: "a"}
+	}
+	// Cada definición cabe en MaxLongitudPatron pero juntas superan
+	// MaxEstadosAFN
+	grandes := make([]Definicion, 16)
+	for i := range grandes {
+		grandes[i] = Definicion{Nombre: fmt.Sprintf("G%d", i), Patron: strings.Repeat("(a|b)*", 40)}
+	}
+	explosivo := "(a|b)*a" + strings.Repeat("(a|b)", 12)
+
+	casos := []struct {
+		nombre       string
+		definiciones []Definicion
+		limite       bool // el error debe envolver ErrLimite
+	}{
+		{"sin definiciones", nil, false},
+		{"sin nombre", []Definicion{{Patron: "a"}}, false},
+		{"patrón inválido", []Definicion{{Nombre: "X", Patron: "(a"}}, false},
+		{"demasiadas definiciones", muchas, true},
+		{"patrón largo", []Definicion{{Nombre: "X", Patron: strings.Repeat("a", MaxLongitudPatron+1)}}, true},
+		{"AFN grande", grandes, true},
+		{"AFD exponencial", []Definicion{{Nombre: "X", Patron: explosivo}}, true},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			_, err := Compilar(c.definiciones)
+			if err == nil {
+				t.Fatalf("Compilar no devolvió error")
+			}
+			if errors.Is(err, ErrLimite) != c.limite {
+				t.Errorf("errors.Is(%v, ErrLimite) = %v, se esperaba %v", err, !c.limite, c.limite)
+			}
+		})
+	}
+}
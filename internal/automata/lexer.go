@@ -0,0 +1,158 @@
+package automata
+
+import (
+	"iter"
+	"sort"
+
+	"analyzer-api/internal/lexer"
+)
+
+// Lexer reconoce tokens recorriendo un AFD con la regla del lexema más largo.
+// Implementa lexer.Fuente, así que puede alimentar directamente al parser.
+type Lexer struct {
+	afd       *AFD
+	input     string
+	pos       int
+	linea     int
+	columna   int
+	tokens    []Token
+	terminado bool
+}
+
+// Token es un alias para que los clientes no necesiten importar lexer
+type Token = lexer.Token
+
+// NuevoLexer crea un analizador léxico dirigido por el AFD
+func (afd *AFD) NuevoLexer(input string) *Lexer {
+	return &Lexer{afd: afd, input: input, linea: 1, columna: 1}
+}
+
+// NextToken obtiene el siguiente token. Los bytes que no inician ningún token
+// se devuelven uno a uno como TOKEN_ERROR.
+func (l *Lexer) NextToken() Token {
+	for {
+		if l.pos >= len(l.input) {
+			tok := Token{Type: lexer.TOKEN_EOF, Line: l.linea, Column: l.columna, Offset: l.pos}
+			if !l.terminado {
+				l.terminado = true
+				l.tokens = append(l.tokens, tok)
+			}
+			return tok
+		}
+
+		inicio, linea, columna := l.pos, l.linea, l.columna
+		fin, definicion := l.reconocer()
+		if definicion < 0 {
+			fin = l.pos + 1
+		}
+		lexema := l.input[l.pos:fin]
+		l.avanzar(fin)
+
+		if definicion >= 0 && l.afd.Definiciones[definicion].Ignorar {
+			continue
+		}
+
+		tok := Token{Type: lexer.TOKEN_ERROR, Lexeme: lexema, Line: linea, Column: columna, Offset: inicio}
+		if definicion >= 0 {
+			def := l.afd.Definiciones[definicion]
+			tok.Type = def.Tipo
+			if tok.Type == "" {
+				tok.Type = lexer.TokenType(def.Nombre)
+			}
+		}
+		l.tokens = append(l.tokens, tok)
+		return tok
+	}
+}
+
+// Analizar reconoce lo que resta de la entrada y devuelve todos los tokens
+func (l *Lexer) Analizar() []Token {
+	for !l.terminado {
+		l.NextToken()
+	}
+	return l.tokens
+}
+
+// Tokens devuelve un iterador que extrae los tokens bajo demanda hasta el
+// token EOF inclusive
+func (l *Lexer) Tokens() iter.Seq[Token] {
+	return func(yield func(Token) bool) {
+		for !l.terminado {
+			if !yield(l.NextToken()) {
+				return
+			}
+		}
+	}
+}
+
+// reconocer recorre el AFD desde la posición actual y devuelve el fin del
+// lexema aceptado más largo y su definición (-1 si no hay ninguno)
+func (l *Lexer) reconocer() (int, int) {
+	fin, definicion := l.pos, -1
+	estado := l.afd.Inicial
+	for i := l.pos; i < len(l.input) && estado >= 0; i++ {
+		estado = l.afd.Siguiente(estado, l.input[i])
+		if estado >= 0 && l.afd.Estados[estado].Acepta >= 0 {
+			fin, definicion = i+1, l.afd.Estados[estado].Acepta
+		}
+	}
+	return fin, definicion
+}
+
+// avanzar mueve la posición hasta fin actualizando línea y columna
+func (l *Lexer) avanzar(fin int) {
+	for ; l.pos < fin; l.pos++ {
+		if l.input[l.pos] == '\n' {
+			l.linea++
+			l.columna = 1
+		} else {
+			l.columna++
+		}
+	}
+}
+
+// DefinicionesBase devuelve definiciones equivalentes al dialecto base del
+// analizador léxico escrito a mano
+func DefinicionesBase() []Definicion {
+	var definiciones []Definicion
+	for _, palabra := range clavesOrdenadas(lexer.MapaReservadas) {
+		tipo := lexer.MapaReservadas[palabra]
+		definiciones = append(definiciones, Definicion{Nombre: string(tipo), Patron: escaparRegex(palabra), Tipo: tipo})
+	}
+	definiciones = append(definiciones,
+		Definicion{Nombre: string(lexer.TOKEN_IDENT), Patron: "[A-Za-z_][A-Za-z0-9_]*", Tipo: lexer.TOKEN_IDENT},
+		Definicion{Nombre: string(lexer.TOKEN_NUMBER), Patron: "[0-9]+", Tipo: lexer.TOKEN_NUMBER},
+	)
+	for _, op := range clavesOrdenadas(lexer.MapaOperadores) {
+		tipo := lexer.MapaOperadores[op]
+		definiciones = append(definiciones, Definicion{Nombre: string(tipo), Patron: escaparRegex(op), Tipo: tipo})
+	}
+	definiciones = append(definiciones,
+		Definicion{Nombre: "ESPACIO", Patron: `[ \t\r\n]+`, Ignorar: true},
+		Definicion{Nombre: "COMENTARIO", Patron: `//[^\n]*`, Ignorar: true},
+		Definicion{Nombre: "COMENTARIO_BLOQUE", Patron: `/\*([^*]|\*+[^*/])*\*+/`, Ignorar: true},
+	)
+	return definiciones
+}
+
+// escaparRegex escapa los metacaracteres de un lexema literal
+func escaparRegex(lexema string) string {
+	var resultado []byte
+	for i := 0; i < len(lexema); i++ {
+		switch lexema[i] {
+		case '\\', '.', '*', '+', '?', '|', '(', ')', '[', ']':
+			resultado = append(resultado, '\\')
+		}
+		resultado = append(resultado, lexema[i])
+	}
+	return string(resultado)
+}
+
+func clavesOrdenadas(m map[string]lexer.TokenType) []string {
+	claves := make([]string, 0, len(m))
+	for k := range m {
+		claves = append(claves, k)
+	}
+	sort.Strings(claves)
+	return claves
+}
@@ -0,0 +1,18 @@
+package automata
+
+import "errors"
+
+// Límites de la construcción. Las definiciones pueden llegar de cualquier
+// cliente de la API y la construcción de subconjuntos puede producir un AFD
+// con una cantidad de estados exponencial en el tamaño del AFN, por
+// ejemplo con (a|b)*a(a|b)(a|b)...(a|b).
+const (
+	MaxDefiniciones   = 64   // definiciones de tokens por construcción
+	MaxLongitudPatron = 256  // bytes de cada patrón
+	MaxEstadosAFN     = 2000 // estados del AFN de todas las definiciones juntas
+	MaxEstadosAFD     = 1000 // estados del AFD antes de minimizar
+)
+
+// ErrLimite indica que las definiciones superan alguno de los límites de la
+// construcción
+var ErrLimite = errors.New("límite de la construcción superado")
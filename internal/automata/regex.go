@@ -0,0 +1,304 @@
+package automata
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Rango es un intervalo cerrado de bytes [Desde, Hasta]
+type Rango struct {
+	Desde byte
+	Hasta byte
+}
+
+// String muestra el rango como en una clase de caracteres
+func (r Rango) String() string {
+	if r.Desde == r.Hasta {
+		return mostrarByte(r.Desde)
+	}
+	return mostrarByte(r.Desde) + "-" + mostrarByte(r.Hasta)
+}
+
+// nodoRegex es un nodo del árbol de una expresión regular
+type nodoRegex interface{}
+
+type regexClase struct{ rangos []Rango }
+type regexConcatenacion struct{ izquierda, derecha nodoRegex }
+type regexAlternativa struct{ izquierda, derecha nodoRegex }
+type regexEstrella struct{ sub nodoRegex }
+type regexMas struct{ sub nodoRegex }
+type regexOpcional struct{ sub nodoRegex }
+type regexVacia struct{}
+
+// parserRegex analiza expresiones regulares con la sintaxis:
+//
+//	alternativa   := concatenacion ('|' concatenacion)*
+//	concatenacion := repeticion*
+//	repeticion    := atomo ('*' | '+' | '?')*
+//	atomo         := '(' alternativa ')' | '[' clase ']' | '.' | '\' escape | caracter
+type parserRegex struct {
+	patron string
+	pos    int
+}
+
+// analizarRegex construye el árbol de una expresión regular
+func analizarRegex(patron string) (nodoRegex, error) {
+	p := &parserRegex{patron: patron}
+	nodo, err := p.alternativa()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.patron) {
+		return nil, p.error("')' sin '(' correspondiente")
+	}
+	return nodo, nil
+}
+
+func (p *parserRegex) error(mensaje string) error {
+	return fmt.Errorf("patrón '%s', posición %d: %s", p.patron, p.pos, mensaje)
+}
+
+func (p *parserRegex) fin() bool {
+	return p.pos >= len(p.patron)
+}
+
+func (p *parserRegex) alternativa() (nodoRegex, error) {
+	izquierda, err := p.concatenacion()
+	if err != nil {
+		return nil, err
+	}
+	for !p.fin() && p.patron[p.pos] == '|' {
+		p.pos++
+		derecha, err := p.concatenacion()
+		if err != nil {
+			return nil, err
+		}
+		izquierda = &regexAlternativa{izquierda: izquierda, derecha: derecha}
+	}
+	return izquierda, nil
+}
+
+func (p *parserRegex) concatenacion() (nodoRegex, error) {
+	var resultado nodoRegex = &regexVacia{}
+	primero := true
+	for !p.fin() && p.patron[p.pos] != '|' && p.patron[p.pos] != ')' {
+		nodo, err := p.repeticion()
+		if err != nil {
+			return nil, err
+		}
+		if primero {
+			resultado = nodo
+			primero = false
+		} else {
+			resultado = &regexConcatenacion{izquierda: resultado, derecha: nodo}
+		}
+	}
+	return resultado, nil
+}
+
+func (p *parserRegex) repeticion() (nodoRegex, error) {
+	nodo, err := p.atomo()
+	if err != nil {
+		return nil, err
+	}
+	for !p.fin() {
+		switch p.patron[p.pos] {
+		case '*':
+			nodo = &regexEstrella{sub: nodo}
+		case '+':
+			nodo = &regexMas{sub: nodo}
+		case '?':
+			nodo = &regexOpcional{sub: nodo}
+		default:
+			return nodo, nil
+		}
+		p.pos++
+	}
+	return nodo, nil
+}
+
+func (p *parserRegex) atomo() (nodoRegex, error) {
+	ch := p.patron[p.pos]
+	switch ch {
+	case '(':
+		p.pos++
+		nodo, err := p.alternativa()
+		if err != nil {
+			return nil, err
+		}
+		if p.fin() || p.patron[p.pos] != ')' {
+			return nil, p.error("se esperaba ')'")
+		}
+		p.pos++
+		return nodo, nil
+	case '[':
+		p.pos++
+		return p.clase()
+	case '.':
+		p.pos++
+		// Cualquier byte excepto el salto de línea
+		return &regexClase{rangos: []Rango{{1, '\n' - 1}, {'\n' + 1, 255}}}, nil
+	case '*', '+', '?':
+		return nil, p.error(fmt.Sprintf("'%c' sin operando", ch))
+	case '\\':
+		p.pos++
+		rangos, err := p.escape()
+		if err != nil {
+			return nil, err
+		}
+		return &regexClase{rangos: rangos}, nil
+	default:
+		p.pos++
+		return &regexClase{rangos: []Rango{{ch, ch}}}, nil
+	}
+}
+
+// clase analiza una clase de caracteres ya consumido el '['
+func (p *parserRegex) clase() (nodoRegex, error) {
+	negada := false
+	if !p.fin() && p.patron[p.pos] == '^' {
+		negada = true
+		p.pos++
+	}
+
+	var rangos []Rango
+	primero := true
+	for {
+		if p.fin() {
+			return nil, p.error("se esperaba ']'")
+		}
+		ch := p.patron[p.pos]
+		if ch == ']' && !primero {
+			p.pos++
+			break
+		}
+		primero = false
+
+		var desde []Rango
+		if ch == '\\' {
+			p.pos++
+			var err error
+			if desde, err = p.escape(); err != nil {
+				return nil, err
+			}
+		} else {
+			p.pos++
+			desde = []Rango{{ch, ch}}
+		}
+
+		// Un intervalo a-z solo es posible entre caracteres simples
+		if len(desde) == 1 && desde[0].Desde == desde[0].Hasta &&
+			p.pos+1 < len(p.patron) && p.patron[p.pos] == '-' && p.patron[p.pos+1] != ']' {
+			p.pos++
+			hasta := p.patron[p.pos]
+			p.pos++
+			if hasta == '\\' {
+				esc, err := p.escape()
+				if err != nil {
+					return nil, err
+				}
+				if len(esc) != 1 || esc[0].Desde != esc[0].Hasta {
+					return nil, p.error("intervalo inválido")
+				}
+				hasta = esc[0].Desde
+			}
+			if hasta < desde[0].Desde {
+				return nil, p.error("intervalo invertido")
+			}
+			desde = []Rango{{desde[0].Desde, hasta}}
+		}
+		rangos = append(rangos, desde...)
+	}
+
+	rangos = normalizarRangos(rangos)
+	if negada {
+		rangos = complementarRangos(rangos)
+	}
+	if len(rangos) == 0 {
+		return nil, p.error("clase de caracteres vacía")
+	}
+	return &regexClase{rangos: rangos}, nil
+}
+
+// escape interpreta la secuencia que sigue a '\'
+func (p *parserRegex) escape() ([]Rango, error) {
+	if p.fin() {
+		return nil, p.error("'\\' al final del patrón")
+	}
+	ch := p.patron[p.pos]
+	p.pos++
+	switch ch {
+	case 'n':
+		return []Rango{{'\n', '\n'}}, nil
+	case 't':
+		return []Rango{{'\t', '\t'}}, nil
+	case 'r':
+		return []Rango{{'\r', '\r'}}, nil
+	case 'd':
+		return []Rango{{'0', '9'}}, nil
+	case 'w':
+		return []Rango{{'0', '9'}, {'A', 'Z'}, {'_', '_'}, {'a', 'z'}}, nil
+	case 's':
+		return []Rango{{'\t', '\n'}, {'\r', '\r'}, {' ', ' '}}, nil
+	default:
+		return []Rango{{ch, ch}}, nil
+	}
+}
+
+// normalizarRangos ordena y fusiona rangos superpuestos o contiguos
+func normalizarRangos(rangos []Rango) []Rango {
+	if len(rangos) == 0 {
+		return rangos
+	}
+	ordenados := append([]Rango(nil), rangos...)
+	sort.Slice(ordenados, func(i, j int) bool { return ordenados[i].Desde < ordenados[j].Desde })
+
+	resultado := []Rango{ordenados[0]}
+	for _, r := range ordenados[1:] {
+		ultimo := &resultado[len(resultado)-1]
+		if int(r.Desde) <= int(ultimo.Hasta)+1 {
+			if r.Hasta > ultimo.Hasta {
+				ultimo.Hasta = r.Hasta
+			}
+		} else {
+			resultado = append(resultado, r)
+		}
+	}
+	return resultado
+}
+
+// complementarRangos devuelve los bytes (excepto NUL) fuera de los rangos dados
+func complementarRangos(rangos []Rango) []Rango {
+	var resultado []Rango
+	siguiente := 1
+	for _, r := range rangos {
+		if int(r.Desde) > siguiente {
+			resultado = append(resultado, Rango{byte(siguiente), r.Desde - 1})
+		}
+		if int(r.Hasta)+1 > siguiente {
+			siguiente = int(r.Hasta) + 1
+		}
+	}
+	if siguiente <= 255 {
+		resultado = append(resultado, Rango{byte(siguiente), 255})
+	}
+	return resultado
+}
+
+// mostrarByte representa un byte de forma legible en tablas y diagramas
+func mostrarByte(b byte) string {
+	switch b {
+	case '\n':
+		return `\n`
+	case '\t':
+		return `\t`
+	case '\r':
+		return `\r`
+	case ' ':
+		return "' '"
+	}
+	if b < 32 || b >= 127 {
+		return fmt.Sprintf(`\x%02x`, b)
+	}
+	return string(b)
+}
@@ -0,0 +1,159 @@
+package automata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Epsilon es el símbolo con que se muestran las transiciones vacías
+const Epsilon = "ε"
+
+// FilaTabla es la fila de un estado en una tabla de transiciones
+type FilaTabla struct {
+	Estado       int     `json:"estado"`
+	Acepta       string  `json:"acepta,omitempty"`   // nombre de la definición aceptada
+	Conjunto     []int   `json:"conjunto,omitempty"` // estados de origen de la construcción
+	Transiciones [][]int `json:"transiciones"`       // destinos por símbolo
+}
+
+// TablaTransiciones representa un autómata como tabla: una columna por
+// símbolo (o clase de bytes equivalentes) y una fila por estado
+type TablaTransiciones struct {
+	Simbolos []string    `json:"simbolos"`
+	Inicial  int         `json:"inicial"`
+	Filas    []FilaTabla `json:"filas"`
+}
+
+// Tabla devuelve la tabla de transiciones del AFN, con la columna ε al final
+func (afn *AFN) Tabla() *TablaTransiciones {
+	clases := afn.particionAlfabeto()
+	columnas := make([][][]int, len(clases))
+	for c, clase := range clases {
+		columnas[c] = make([][]int, len(afn.Estados))
+		for e, estado := range afn.Estados {
+			for _, t := range estado.Transiciones {
+				if t.Rango.Desde <= clase.Desde && clase.Hasta <= t.Rango.Hasta {
+					columnas[c][e] = append(columnas[c][e], t.Destino)
+				}
+			}
+		}
+	}
+	simbolos, columnas := agruparColumnas(clases, columnas)
+
+	tabla := &TablaTransiciones{
+		Simbolos: append(simbolos, Epsilon),
+		Inicial:  afn.Inicial,
+	}
+	for e, estado := range afn.Estados {
+		fila := FilaTabla{Estado: e, Acepta: afn.nombreDefinicion(estado.Acepta)}
+		for c := range columnas {
+			fila.Transiciones = append(fila.Transiciones, vacioSiNil(columnas[c][e]))
+		}
+		fila.Transiciones = append(fila.Transiciones, vacioSiNil(estado.Epsilon))
+		tabla.Filas = append(tabla.Filas, fila)
+	}
+	return tabla
+}
+
+// Tabla devuelve la tabla de transiciones del AFD
+func (afd *AFD) Tabla() *TablaTransiciones {
+	columnas := make([][][]int, len(afd.Clases))
+	for c := range afd.Clases {
+		columnas[c] = make([][]int, len(afd.Estados))
+		for e, estado := range afd.Estados {
+			if destino := estado.Transiciones[c]; destino >= 0 {
+				columnas[c][e] = []int{destino}
+			}
+		}
+	}
+	simbolos, columnas := agruparColumnas(afd.Clases, columnas)
+
+	tabla := &TablaTransiciones{Simbolos: simbolos, Inicial: afd.Inicial}
+	for e, estado := range afd.Estados {
+		fila := FilaTabla{
+			Estado:   e,
+			Acepta:   nombreDefinicion(afd.Definiciones, estado.Acepta),
+			Conjunto: estado.Conjunto,
+		}
+		for c := range columnas {
+			fila.Transiciones = append(fila.Transiciones, vacioSiNil(columnas[c][e]))
+		}
+		tabla.Filas = append(tabla.Filas, fila)
+	}
+	return tabla
+}
+
+// DOT genera la descripción Graphviz de la tabla de transiciones
+func (t *TablaTransiciones) DOT(nombre string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "digraph %s {\n", nombre)
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=circle];\n")
+	sb.WriteString("  inicio [shape=point];\n")
+	for _, fila := range t.Filas {
+		if fila.Acepta != "" {
+			fmt.Fprintf(&sb, "  q%d [shape=doublecircle, xlabel=%q];\n", fila.Estado, fila.Acepta)
+		}
+	}
+	fmt.Fprintf(&sb, "  inicio -> q%d;\n", t.Inicial)
+
+	for _, fila := range t.Filas {
+		// Agrupar en una sola arista todos los símbolos hacia el mismo destino
+		etiquetas := map[int][]string{}
+		var destinos []int
+		for c, celda := range fila.Transiciones {
+			for _, destino := range celda {
+				if _, ok := etiquetas[destino]; !ok {
+					destinos = append(destinos, destino)
+				}
+				etiquetas[destino] = append(etiquetas[destino], t.Simbolos[c])
+			}
+		}
+		sort.Ints(destinos)
+		for _, destino := range destinos {
+			fmt.Fprintf(&sb, "  q%d -> q%d [label=%q];\n",
+				fila.Estado, destino, strings.Join(etiquetas[destino], ","))
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func (afn *AFN) nombreDefinicion(indice int) string {
+	return nombreDefinicion(afn.Definiciones, indice)
+}
+
+func nombreDefinicion(definiciones []Definicion, indice int) string {
+	if indice < 0 || indice >= len(definiciones) {
+		return ""
+	}
+	return definiciones[indice].Nombre
+}
+
+// agruparColumnas une las clases de bytes cuyas columnas son idénticas en
+// todas las filas, para que la tabla muestre por ejemplo "a-h,j-z" en una
+// sola columna
+func agruparColumnas(clases []Rango, columnas [][][]int) ([]string, [][][]int) {
+	var simbolos []string
+	var agrupadas [][][]int
+	indice := map[string]int{}
+	for c, columna := range columnas {
+		clave := fmt.Sprint(columna)
+		if i, ok := indice[clave]; ok {
+			simbolos[i] += "," + clases[c].String()
+			continue
+		}
+		indice[clave] = len(simbolos)
+		simbolos = append(simbolos, clases[c].String())
+		agrupadas = append(agrupadas, columna)
+	}
+	return simbolos, agrupadas
+}
+
+func vacioSiNil(valores []int) []int {
+	if valores == nil {
+		return []int{}
+	}
+	return valores
+}
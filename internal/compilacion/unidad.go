@@ -0,0 +1,155 @@
+// Package compilacion reúne las fases del analizador en una unidad de
+// compilación: el código fuente de un programa junto con lo que produce
+// cada fase. Cada fase se ejecuta una sola vez, cuando se pide por primera
+// vez algo que depende de ella, y sus resultados quedan guardados.
+package compilacion
+
+import (
+	"analyzer-api/internal/lexer"
+	"analyzer-api/internal/parser"
+	"analyzer-api/internal/semantic"
+	"analyzer-api/internal/traza"
+)
+
+// Unidad es un programa y los resultados de las fases que ya se ejecutaron
+// sobre él
+type Unidad struct {
+	codigo string
+
+	opcionesLexer     []lexer.Opcion
+	opcionesParser    []parser.Opcion
+	opcionesSemantica []semantic.Opcion
+
+	lex    *lexer.Lexer
+	tokens []lexer.Token // nil hasta terminar el análisis léxico
+
+	parser   *parser.Parser // nil hasta ejecutar el análisis sintáctico
+	programa *parser.Programa
+
+	sem               *semantic.Analizador // nil hasta ejecutar el análisis semántico
+	erroresSemanticos []semantic.ErrorSemantico
+}
+
+// Opcion configura una unidad de compilación al crearla
+type Opcion func(*Unidad)
+
+// ConDialecto analiza el código con el dialecto d en lugar del dialecto base
+func ConDialecto(d *lexer.Dialecto) Opcion {
+	return func(u *Unidad) {
+		u.opcionesLexer = append(u.opcionesLexer, lexer.ConDialecto(d))
+	}
+}
+
+// ConTraza registra en t los eventos de las tres fases
+func ConTraza(t traza.Trazador) Opcion {
+	return func(u *Unidad) {
+		u.opcionesLexer = append(u.opcionesLexer, lexer.ConTraza(t))
+		u.opcionesParser = append(u.opcionesParser, parser.ConTraza(t))
+		u.opcionesSemantica = append(u.opcionesSemantica, semantic.ConTraza(t))
+	}
+}
+
+// ConArbolConcreto construye también el árbol concreto durante el análisis
+// sintáctico
+func ConArbolConcreto() Opcion {
+	return func(u *Unidad) {
+		u.opcionesParser = append(u.opcionesParser, parser.ConArbolConcreto())
+	}
+}
+
+// Nueva crea una unidad de compilación para el código dado. No ejecuta
+// ninguna fase todavía.
+func Nueva(codigo string, opciones ...Opcion) *Unidad {
+	u := &Unidad{codigo: codigo}
+	for _, opcion := range opciones {
+		opcion(u)
+	}
+	u.lex = lexer.New(codigo, u.opcionesLexer...)
+	return u
+}
+
+// Codigo devuelve el código fuente de la unidad
+func (u *Unidad) Codigo() string {
+	return u.codigo
+}
+
+// Tokens devuelve todos los tokens del programa, terminando con EOF
+func (u *Unidad) Tokens() []lexer.Token {
+	if u.tokens == nil {
+		u.tokens = u.lex.Analizar()
+	}
+	return u.tokens
+}
+
+// ConteoTokens devuelve el número de tokens por categoría canónica
+func (u *Unidad) ConteoTokens() map[string]int {
+	u.Tokens()
+	return u.lex.ContarTokens()
+}
+
+// ErroresLexicos devuelve los caracteres no reconocidos y el comentario sin
+// cerrar, si lo hay
+func (u *Unidad) ErroresLexicos() []lexer.ErrorLexico {
+	u.Tokens()
+	return u.lex.Errores()
+}
+
+// Programa devuelve el árbol sintáctico, que puede estar incompleto si el
+// código tiene errores de sintaxis
+func (u *Unidad) Programa() *parser.Programa {
+	if u.parser != nil {
+		return u.programa
+	}
+	// Si el análisis léxico no terminó, el parser pide los tokens al lexer
+	// a medida que los necesita, de modo que la traza intercala las dos
+	// fases; si ya terminó, recibe los tokens guardados
+	fuente := lexer.Fuente(u.lex)
+	if u.tokens != nil {
+		fuente = lexer.DesdeTokens(u.tokens)
+	}
+	u.parser = parser.New(fuente, u.opcionesParser...)
+	u.programa = u.parser.Parse()
+	return u.programa
+}
+
+// ErroresSintacticos devuelve los errores de sintaxis
+func (u *Unidad) ErroresSintacticos() []parser.ErrorSintactico {
+	u.Programa()
+	return u.parser.Errores()
+}
+
+// ArbolConcreto devuelve el árbol concreto, o nil si la unidad no se creó
+// con ConArbolConcreto
+func (u *Unidad) ArbolConcreto() *parser.NodoConcreto {
+	u.Programa()
+	return u.parser.ArbolConcreto()
+}
+
+// analizarSemantica ejecuta el análisis semántico si todavía no se hizo
+func (u *Unidad) analizarSemantica() {
+	if u.sem != nil {
+		return
+	}
+	u.sem = semantic.New(u.Programa(), u.opcionesSemantica...)
+	u.erroresSemanticos = u.sem.Analizar()
+}
+
+// ErroresSemanticos devuelve los errores semánticos
+func (u *Unidad) ErroresSemanticos() []semantic.ErrorSemantico {
+	u.analizarSemantica()
+	return u.erroresSemanticos
+}
+
+// Advertencias devuelve las advertencias del análisis semántico, en el
+// orden del código fuente
+func (u *Unidad) Advertencias() []semantic.Advertencia {
+	u.analizarSemantica()
+	return u.sem.Advertencias()
+}
+
+// TablaSimbolos devuelve la tabla de símbolos que llenó el análisis
+// semántico
+func (u *Unidad) TablaSimbolos() *semantic.TablaSimbolos {
+	u.analizarSemantica()
+	return u.sem.TablaSimbolos()
+}
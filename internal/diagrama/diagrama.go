@@ -0,0 +1,245 @@
+package diagrama
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"analyzer-api/internal/lexer"
+	"analyzer-api/internal/parser"
+)
+
+// Medidas del dibujo, en píxeles
+const (
+	Radio         = 10 // radio de las curvas de las bifurcaciones
+	AltoCaja      = 24
+	AnchoCaracter = 8  // ancho aproximado de un caracter de la fuente monoespaciada
+	Relleno       = 10 // espacio entre el texto y el borde de su caja
+	Separacion    = 10 // espacio entre elementos consecutivos o alternativas
+	Margen        = 20
+	AltoTitulo    = 20 // espacio para el nombre de la regla
+	AnchoMarca    = 10 // marcas de comienzo y fin de la regla
+)
+
+const estilo = `<style>
+path { fill: none; stroke: #333; stroke-width: 1.5 }
+rect { fill: #fff; stroke: #333; stroke-width: 1.5 }
+rect.terminal { fill: #e6f2e6 }
+text { font: 13px monospace; fill: #000 }
+text.regla { font-weight: bold }
+</style>
+`
+
+// caja es el tamaño de un elemento dibujado. La línea principal entra por la
+// izquierda y sale por la derecha a la altura 0; arriba y abajo son cuánto
+// se extiende el dibujo por encima y por debajo de ella.
+type caja struct {
+	ancho, arriba, abajo int
+}
+
+// dibujo acumula los elementos SVG de una o más reglas
+type dibujo struct {
+	sb       strings.Builder
+	dialecto *lexer.Dialecto
+}
+
+// Regla devuelve el diagrama de una regla como documento SVG. Los terminales
+// se muestran con los lexemas del dialecto.
+func Regla(regla parser.Regla, dialecto *lexer.Dialecto) string {
+	return Reglas([]parser.Regla{regla}, dialecto)
+}
+
+// Reglas devuelve un documento SVG con los diagramas de sintaxis (diagramas
+// de ferrocarril) de las reglas, uno debajo del otro
+func Reglas(reglas []parser.Regla, dialecto *lexer.Dialecto) string {
+	if dialecto == nil {
+		dialecto = lexer.DialectoBase()
+	}
+	d := &dibujo{dialecto: dialecto}
+	ancho, alto := 0, 0
+	for _, r := range reglas {
+		a, h := d.regla(r, alto)
+		ancho = max(ancho, a)
+		alto += h
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		ancho, alto, ancho, alto)
+	sb.WriteString(estilo)
+	sb.WriteString(d.sb.String())
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+// regla dibuja el nombre y el diagrama de una regla a partir de la altura y
+// devuelve el ancho y el alto que ocupa
+func (d *dibujo) regla(r parser.Regla, y int) (int, int) {
+	c := d.medir(r.Cuerpo)
+	fmt.Fprintf(&d.sb, `<g id="%s">`+"\n", html.EscapeString(r.Nombre))
+	fmt.Fprintf(&d.sb, `<text class="regla" x="%d" y="%d">%s</text>`+"\n",
+		Margen, y+Margen+AltoTitulo/2, html.EscapeString(r.Nombre))
+
+	linea := y + Margen + AltoTitulo + c.arriba
+	x := Margen
+	// Marca de comienzo: dos barras verticales
+	d.camino("M%d %d v%d M%d %d v%d M%d %d h%d", x, linea-8, 16, x+4, linea-8, 16, x+4, linea, AnchoMarca-4)
+	x += AnchoMarca
+	d.elemento(r.Cuerpo, x, linea)
+	x += c.ancho
+	// Marca de fin
+	d.camino("M%d %d h%d M%d %d v%d M%d %d v%d", x, linea, AnchoMarca-4, x+AnchoMarca-4, linea-8, 16, x+AnchoMarca, linea-8, 16)
+	d.sb.WriteString("</g>\n")
+
+	return x + AnchoMarca + Margen, Margen + AltoTitulo + c.arriba + c.abajo + Margen
+}
+
+// medir calcula el tamaño de un elemento
+func (d *dibujo) medir(e parser.Elemento) caja {
+	switch e.Tipo {
+	case parser.ELEMENTO_TERMINAL, parser.ELEMENTO_NO_TERMINAL:
+		return caja{ancho: utf8.RuneCountInString(d.texto(e))*AnchoCaracter + 2*Relleno, arriba: AltoCaja / 2, abajo: AltoCaja / 2}
+
+	case parser.ELEMENTO_ALTERNATIVA:
+		cajas, desplazamientos := d.alternativas(e)
+		c := caja{arriba: cajas[0].arriba}
+		for i, h := range cajas {
+			c.ancho = max(c.ancho, h.ancho)
+			c.abajo = max(c.abajo, desplazamientos[i]+h.abajo)
+		}
+		c.ancho += 4 * Radio
+		return c
+
+	case parser.ELEMENTO_REPETICION:
+		h := d.medir(e.Hijos[0])
+		_, bucle := desplazamientosRepeticion(h)
+		return caja{ancho: h.ancho + 4*Radio, abajo: bucle}
+	}
+
+	if len(e.Hijos) == 0 {
+		return caja{ancho: 2 * Separacion}
+	}
+	c := caja{ancho: Separacion * (len(e.Hijos) - 1)}
+	for _, h := range e.Hijos {
+		ch := d.medir(h)
+		c.ancho += ch.ancho
+		c.arriba = max(c.arriba, ch.arriba)
+		c.abajo = max(c.abajo, ch.abajo)
+	}
+	return c
+}
+
+// alternativas mide las alternativas y calcula a qué distancia de la línea
+// principal se dibuja cada una; la primera va sobre la línea
+func (d *dibujo) alternativas(e parser.Elemento) ([]caja, []int) {
+	cajas := make([]caja, len(e.Hijos))
+	desplazamientos := make([]int, len(e.Hijos))
+	for i, h := range e.Hijos {
+		cajas[i] = d.medir(h)
+		if i > 0 {
+			anterior := desplazamientos[i-1] + cajas[i-1].abajo
+			desplazamientos[i] = max(anterior+Separacion+cajas[i].arriba, 2*Radio)
+		}
+	}
+	return cajas, desplazamientos
+}
+
+// desplazamientosRepeticion devuelve a qué distancia de la línea principal
+// se dibujan el elemento repetido y la línea de vuelta
+func desplazamientosRepeticion(h caja) (int, int) {
+	rama := max(2*Radio, Separacion+h.arriba)
+	bucle := max(rama+2*Radio, rama+h.abajo+Separacion)
+	return rama, bucle
+}
+
+// elemento dibuja e con la línea principal entrando en (x, y)
+func (d *dibujo) elemento(e parser.Elemento, x, y int) {
+	c := d.medir(e)
+	switch e.Tipo {
+	case parser.ELEMENTO_TERMINAL, parser.ELEMENTO_NO_TERMINAL:
+		clase, redondeo := "no-terminal", 0
+		if e.Tipo == parser.ELEMENTO_TERMINAL {
+			clase, redondeo = "terminal", AltoCaja/2
+		}
+		fmt.Fprintf(&d.sb, `<rect class="%s" x="%d" y="%d" width="%d" height="%d" rx="%d"/>`+"\n",
+			clase, x, y-AltoCaja/2, c.ancho, AltoCaja, redondeo)
+		fmt.Fprintf(&d.sb, `<text x="%d" y="%d" text-anchor="middle">%s</text>`+"\n",
+			x+c.ancho/2, y+4, html.EscapeString(d.texto(e)))
+
+	case parser.ELEMENTO_ALTERNATIVA:
+		cajas, desplazamientos := d.alternativas(e)
+		interior := c.ancho - 4*Radio
+		for i, h := range e.Hijos {
+			yi := y + desplazamientos[i]
+			if i == 0 {
+				d.camino("M%d %d h%d", x, y, 2*Radio)
+				d.camino("M%d %d h%d", x+2*Radio+interior, y, 2*Radio)
+			} else {
+				d.bajar(x, y, yi)
+				d.subir(x+2*Radio+interior, yi, y)
+			}
+			d.elemento(h, x+2*Radio, yi)
+			if resto := interior - cajas[i].ancho; resto > 0 {
+				d.camino("M%d %d h%d", x+2*Radio+cajas[i].ancho, yi, resto)
+			}
+		}
+
+	case parser.ELEMENTO_REPETICION:
+		h := d.medir(e.Hijos[0])
+		rama, bucle := desplazamientosRepeticion(h)
+		fin := x + 2*Radio + h.ancho
+		// Línea principal para cero repeticiones
+		d.camino("M%d %d h%d", x, y, c.ancho)
+		d.bajar(x, y, y+rama)
+		d.elemento(e.Hijos[0], x+2*Radio, y+rama)
+		d.subir(fin, y+rama, y)
+		// Vuelta desde el final del elemento hasta su comienzo
+		d.camino("M%d %d Q%d %d %d %d V%d Q%d %d %d %d H%d Q%d %d %d %d V%d Q%d %d %d %d",
+			fin, y+rama, fin+Radio, y+rama, fin+Radio, y+rama+Radio,
+			y+bucle-Radio, fin+Radio, y+bucle, fin, y+bucle,
+			x+2*Radio, x+Radio, y+bucle, x+Radio, y+bucle-Radio,
+			y+rama+Radio, x+Radio, y+rama, x+2*Radio, y+rama)
+
+	default:
+		if len(e.Hijos) == 0 {
+			d.camino("M%d %d h%d", x, y, c.ancho)
+			return
+		}
+		for i, h := range e.Hijos {
+			if i > 0 {
+				d.camino("M%d %d h%d", x, y, Separacion)
+				x += Separacion
+			}
+			d.elemento(h, x, y)
+			x += d.medir(h).ancho
+		}
+	}
+}
+
+// bajar dibuja la curva que sale de la línea en (x, y) y llega a la altura
+// destino en x+2·Radio
+func (d *dibujo) bajar(x, y, destino int) {
+	d.camino("M%d %d Q%d %d %d %d V%d Q%d %d %d %d",
+		x, y, x+Radio, y, x+Radio, y+Radio, destino-Radio, x+Radio, destino, x+2*Radio, destino)
+}
+
+// subir dibuja la curva que sale de (x, y) y vuelve a la línea en x+2·Radio
+func (d *dibujo) subir(x, y, destino int) {
+	d.camino("M%d %d Q%d %d %d %d V%d Q%d %d %d %d",
+		x, y, x+Radio, y, x+Radio, y-Radio, destino+Radio, x+Radio, destino, x+2*Radio, destino)
+}
+
+func (d *dibujo) camino(formato string, args ...interface{}) {
+	fmt.Fprintf(&d.sb, `<path d="%s"/>`+"\n", fmt.Sprintf(formato, args...))
+}
+
+// texto es lo que se escribe dentro de la caja de un terminal o no terminal
+func (d *dibujo) texto(e parser.Elemento) string {
+	if e.Tipo == parser.ELEMENTO_TERMINAL {
+		if lexema, ok := d.dialecto.Lexema(lexer.TokenType(e.Simbolo)); ok {
+			return lexema
+		}
+	}
+	return e.Simbolo
+}
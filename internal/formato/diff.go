@@ -0,0 +1,190 @@
+package formato
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaximoComparacion limita el tamaño de la tabla de la subsecuencia común;
+// por encima de él las líneas distintas se muestran como un solo reemplazo
+const MaximoComparacion = 4_000_000
+
+// LineasContexto es el número de líneas sin cambios que rodean cada bloque
+// de cambios en Diff
+const LineasContexto = 3
+
+// operacion es una línea del diff: ' ' igual, '-' eliminada o '+' agregada
+type operacion struct {
+	tipo  byte
+	texto string
+}
+
+// Diff compara dos textos línea por línea y devuelve las diferencias en
+// formato unificado, o una cadena vacía si son iguales
+func Diff(original, formateado string) string {
+	if original == formateado {
+		return ""
+	}
+	ops := compararLineas(lineas(original), lineas(formateado))
+	if !hayCambios(ops) {
+		// Solo difiere el salto de línea final
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("--- original\n+++ formateado\n")
+	for inicio := 0; inicio < len(ops); {
+		// Buscar el próximo cambio
+		for inicio < len(ops) && ops[inicio].tipo == ' ' {
+			inicio++
+		}
+		if inicio == len(ops) {
+			break
+		}
+
+		// Extender el bloque mientras los cambios estén separados por menos
+		// del doble del contexto
+		fin := inicio
+		for k := inicio; k < len(ops); k++ {
+			if ops[k].tipo != ' ' {
+				fin = k + 1
+			} else if k-fin >= 2*LineasContexto {
+				break
+			}
+		}
+		desde := max(inicio-LineasContexto, 0)
+		hasta := min(fin+LineasContexto, len(ops))
+		escribirBloque(&sb, ops, desde, hasta)
+		inicio = hasta
+	}
+	return sb.String()
+}
+
+// escribirBloque escribe el encabezado @@ y las líneas ops[desde:hasta]
+func escribirBloque(sb *strings.Builder, ops []operacion, desde, hasta int) {
+	// Líneas de cada texto anteriores al bloque
+	lineaA, lineaB := 1, 1
+	for _, op := range ops[:desde] {
+		if op.tipo != '+' {
+			lineaA++
+		}
+		if op.tipo != '-' {
+			lineaB++
+		}
+	}
+	cantidadA, cantidadB := 0, 0
+	for _, op := range ops[desde:hasta] {
+		if op.tipo != '+' {
+			cantidadA++
+		}
+		if op.tipo != '-' {
+			cantidadB++
+		}
+	}
+	// Un rango vacío se numera con la línea anterior, como en diff -u
+	if cantidadA == 0 {
+		lineaA--
+	}
+	if cantidadB == 0 {
+		lineaB--
+	}
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", lineaA, cantidadA, lineaB, cantidadB)
+	for _, op := range ops[desde:hasta] {
+		sb.WriteByte(op.tipo)
+		sb.WriteString(op.texto)
+		sb.WriteString("\n")
+	}
+}
+
+// compararLineas calcula la subsecuencia común más larga entre a y b y
+// devuelve la secuencia de operaciones que transforma a en b
+func compararLineas(a, b []string) []operacion {
+	// Las líneas iguales al principio y al final no necesitan la tabla
+	prefijo := 0
+	for prefijo < len(a) && prefijo < len(b) && a[prefijo] == b[prefijo] {
+		prefijo++
+	}
+	sufijo := 0
+	for sufijo < len(a)-prefijo && sufijo < len(b)-prefijo && a[len(a)-1-sufijo] == b[len(b)-1-sufijo] {
+		sufijo++
+	}
+	medioA, medioB := a[prefijo:len(a)-sufijo], b[prefijo:len(b)-sufijo]
+
+	ops := make([]operacion, 0, len(a)+len(b))
+	for _, linea := range a[:prefijo] {
+		ops = append(ops, operacion{' ', linea})
+	}
+	if len(medioA)*len(medioB) > MaximoComparacion {
+		for _, linea := range medioA {
+			ops = append(ops, operacion{'-', linea})
+		}
+		for _, linea := range medioB {
+			ops = append(ops, operacion{'+', linea})
+		}
+	} else {
+		ops = append(ops, subsecuenciaComun(medioA, medioB)...)
+	}
+	for _, linea := range a[len(a)-sufijo:] {
+		ops = append(ops, operacion{' ', linea})
+	}
+	return ops
+}
+
+// subsecuenciaComun compara a y b con programación dinámica
+func subsecuenciaComun(medioA, medioB []string) []operacion {
+	// lcs[i][j] es la longitud de la subsecuencia común de medioA[i:] y medioB[j:]
+	lcs := make([][]int, len(medioA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(medioB)+1)
+	}
+	for i := len(medioA) - 1; i >= 0; i-- {
+		for j := len(medioB) - 1; j >= 0; j-- {
+			if medioA[i] == medioB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]operacion, 0, len(medioA)+len(medioB))
+	i, j := 0, 0
+	for i < len(medioA) && j < len(medioB) {
+		switch {
+		case medioA[i] == medioB[j]:
+			ops = append(ops, operacion{' ', medioA[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, operacion{'-', medioA[i]})
+			i++
+		default:
+			ops = append(ops, operacion{'+', medioB[j]})
+			j++
+		}
+	}
+	for ; i < len(medioA); i++ {
+		ops = append(ops, operacion{'-', medioA[i]})
+	}
+	for ; j < len(medioB); j++ {
+		ops = append(ops, operacion{'+', medioB[j]})
+	}
+	return ops
+}
+
+func hayCambios(ops []operacion) bool {
+	for _, op := range ops {
+		if op.tipo != ' ' {
+			return true
+		}
+	}
+	return false
+}
+
+// lineas divide un texto en líneas sin el salto final
+func lineas(texto string) []string {
+	if texto == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(texto, "\n"), "\n")
+}
@@ -0,0 +1,268 @@
+package formato
+
+import (
+	"math"
+	"strings"
+
+	"analyzer-api/internal/lexer"
+	"analyzer-api/internal/parser"
+)
+
+// Sangria es el texto con que se indenta cada nivel de anidamiento
+const Sangria = "    "
+
+// Formatear analiza el código y lo vuelve a escribir con el estilo canónico:
+// una declaración por línea, un espacio alrededor de los operadores y el
+// cuerpo de cada do-while y de cada función indentado. Los comentarios se conservan: los que
+// ocupaban su propia línea siguen en su propia línea antes de la declaración
+// que los seguía, y los demás quedan al final de la línea de la declaración
+// en que aparecían. Se conserva como máximo una línea en blanco entre
+// declaraciones.
+//
+// Si el código tiene errores léxicos o de sintaxis no se formatea, porque el
+// parser descarta las declaraciones inválidas y un comentario sin cerrar se
+// perdería; se devuelven los errores.
+func Formatear(codigo string, dialecto *lexer.Dialecto) (string, []lexer.ErrorLexico, []parser.ErrorSintactico) {
+	if dialecto == nil {
+		dialecto = lexer.DialectoBase()
+	}
+	l := lexer.New(codigo, lexer.ConDialecto(dialecto))
+	p := parser.New(l)
+	programa := p.Parse()
+	if lexicos, sintacticos := l.Errores(), p.Errores(); len(lexicos) > 0 || len(sintacticos) > 0 {
+		return codigo, lexicos, sintacticos
+	}
+
+	imp := &impresor{dialecto: dialecto, comentarios: l.Comentarios()}
+	imp.declaraciones(programa.Declaraciones, math.MaxInt)
+	imp.comentariosAntes(math.MaxInt)
+	return imp.sb.String(), nil, nil
+}
+
+// impresor escribe el programa formateado intercalando los comentarios según
+// su posición en el código original
+type impresor struct {
+	sb          strings.Builder
+	dialecto    *lexer.Dialecto
+	comentarios []lexer.Comentario
+	siguiente   int // próximo comentario por escribir
+	nivel       int // nivel de indentación actual
+	ultimaLinea int // línea original donde terminó lo último escrito
+}
+
+// declaraciones escribe una lista de declaraciones, cada una precedida por
+// los comentarios que la anteceden. limite es el offset donde termina la
+// lista: el de la llave o la condición que la cierra.
+func (imp *impresor) declaraciones(declaraciones []parser.Declaracion, limite int) {
+	for i, decl := range declaraciones {
+		inicio := decl.Ubicacion().Inicio
+		imp.comentariosAntes(inicio.Offset)
+		imp.separar(inicio.Linea)
+		siguiente := limite
+		if i+1 < len(declaraciones) {
+			siguiente = declaraciones[i+1].Ubicacion().Inicio.Offset
+		}
+		imp.declaracion(decl, siguiente)
+	}
+}
+
+// declaracion escribe una declaración completa, terminada en salto de línea.
+// Los comentarios desde limite en adelante pertenecen a lo que sigue y no se
+// escriben al final de su línea.
+func (imp *impresor) declaracion(decl parser.Declaracion, limite int) {
+	imp.sangrar()
+	switch d := decl.(type) {
+	case *parser.DeclaracionVariable:
+		if d.Constante {
+			imp.escribir(imp.lexema(lexer.TOKEN_CONST), " ")
+		}
+		imp.escribir(d.Tipo, " ", d.Nombre, " ", imp.lexema(lexer.TOKEN_ASSIGN), " ")
+		imp.expresion(d.Valor)
+		imp.escribir(imp.lexema(lexer.TOKEN_SEMI))
+	case *parser.DeclaracionAsignacion:
+		imp.expresion(d.Asignacion)
+		imp.escribir(imp.lexema(lexer.TOKEN_SEMI))
+	case *parser.DeclaracionDoWhile:
+		imp.escribir(imp.lexema(lexer.TOKEN_DO), " ", imp.lexema(lexer.TOKEN_LBRACE))
+		imp.sb.WriteString("\n")
+		imp.ultimaLinea = d.Inicio.Linea
+
+		// Los comentarios después de la última declaración del cuerpo
+		// quedan dentro de las llaves
+		cierre := limite
+		if d.Condicion != nil {
+			cierre = d.Condicion.Ubicacion().Inicio.Offset
+		}
+		imp.nivel++
+		imp.declaraciones(d.Cuerpo, cierre)
+		imp.comentariosAntes(cierre)
+		imp.nivel--
+
+		imp.sangrar()
+		imp.escribir(imp.lexema(lexer.TOKEN_RBRACE), " ", imp.lexema(lexer.TOKEN_WHILE), " ", imp.lexema(lexer.TOKEN_LPAREN))
+		imp.expresion(d.Condicion)
+		imp.escribir(imp.lexema(lexer.TOKEN_RPAREN), imp.lexema(lexer.TOKEN_SEMI))
+	case *parser.DeclaracionImpresion:
+		imp.escribir(imp.lexema(lexer.TOKEN_PRINT), imp.lexema(lexer.TOKEN_LPAREN))
+		imp.expresion(d.Valor)
+		imp.escribir(imp.lexema(lexer.TOKEN_RPAREN), imp.lexema(lexer.TOKEN_SEMI))
+	case *parser.DeclaracionLectura:
+		imp.escribir(imp.lexema(lexer.TOKEN_READ), imp.lexema(lexer.TOKEN_LPAREN), d.Nombre)
+		imp.indice(d.Indice)
+		imp.escribir(imp.lexema(lexer.TOKEN_RPAREN), imp.lexema(lexer.TOKEN_SEMI))
+	case *parser.DeclaracionArreglo:
+		imp.escribir(d.Tipo, " ", d.Nombre, imp.lexema(lexer.TOKEN_LBRACKET))
+		imp.expresion(d.Tamano)
+		imp.escribir(imp.lexema(lexer.TOKEN_RBRACKET))
+		if d.Elementos != nil {
+			imp.escribir(" ", imp.lexema(lexer.TOKEN_ASSIGN), " ", imp.lexema(lexer.TOKEN_LBRACE))
+			imp.lista(d.Elementos)
+			imp.escribir(imp.lexema(lexer.TOKEN_RBRACE))
+		}
+		imp.escribir(imp.lexema(lexer.TOKEN_SEMI))
+	case *parser.DeclaracionFuncion:
+		imp.escribir(d.Tipo, " ", d.Nombre, imp.lexema(lexer.TOKEN_LPAREN))
+		for i, parametro := range d.Parametros {
+			if i > 0 {
+				imp.escribir(imp.lexema(lexer.TOKEN_COMMA), " ")
+			}
+			imp.escribir(parametro.Tipo, " ", parametro.Nombre)
+		}
+		imp.escribir(imp.lexema(lexer.TOKEN_RPAREN), " ", imp.lexema(lexer.TOKEN_LBRACE))
+		imp.sb.WriteString("\n")
+		imp.ultimaLinea = d.Inicio.Linea
+
+		// Los comentarios antes de la llave de cierre quedan dentro de la
+		// función
+		imp.nivel++
+		imp.declaraciones(d.Cuerpo, d.Fin.Offset-1)
+		imp.comentariosAntes(d.Fin.Offset - 1)
+		imp.nivel--
+
+		imp.sangrar()
+		imp.escribir(imp.lexema(lexer.TOKEN_RBRACE))
+	case *parser.DeclaracionRetorno:
+		imp.escribir(imp.lexema(lexer.TOKEN_RETURN), " ")
+		imp.expresion(d.Valor)
+		imp.escribir(imp.lexema(lexer.TOKEN_SEMI))
+	case *parser.DeclaracionLlamada:
+		imp.expresion(d.Llamada)
+		imp.escribir(imp.lexema(lexer.TOKEN_SEMI))
+	}
+	imp.comentariosFinales(decl.Ubicacion().Fin, limite)
+	imp.sb.WriteString("\n")
+}
+
+// expresion escribe una expresión con un espacio alrededor de cada operador
+func (imp *impresor) expresion(expr parser.Expresion) {
+	switch e := expr.(type) {
+	case *parser.ExpresionIdentificador:
+		imp.escribir(e.Valor)
+	case *parser.ExpresionNumero:
+		imp.escribir(e.Valor)
+	case *parser.ExpresionBinaria:
+		imp.expresion(e.Izquierda)
+		imp.escribir(" ", e.Operador, " ")
+		imp.expresion(e.Derecha)
+	case *parser.ExpresionAsignacion:
+		imp.escribir(e.Nombre)
+		imp.indice(e.Indice)
+		imp.escribir(" ", imp.lexema(lexer.TOKEN_ASSIGN), " ")
+		imp.expresion(e.Valor)
+	case *parser.ExpresionLlamada:
+		imp.escribir(e.Nombre, imp.lexema(lexer.TOKEN_LPAREN))
+		imp.lista(e.Argumentos)
+		imp.escribir(imp.lexema(lexer.TOKEN_RPAREN))
+	case *parser.ExpresionIndice:
+		imp.escribir(e.Nombre)
+		imp.indice(e.Indice)
+	}
+}
+
+// indice escribe un índice entre corchetes, si lo hay
+func (imp *impresor) indice(indice parser.Expresion) {
+	if indice == nil {
+		return
+	}
+	imp.escribir(imp.lexema(lexer.TOKEN_LBRACKET))
+	imp.expresion(indice)
+	imp.escribir(imp.lexema(lexer.TOKEN_RBRACKET))
+}
+
+// lista escribe expresiones separadas por comas
+func (imp *impresor) lista(expresiones []parser.Expresion) {
+	for i, e := range expresiones {
+		if i > 0 {
+			imp.escribir(imp.lexema(lexer.TOKEN_COMMA), " ")
+		}
+		imp.expresion(e)
+	}
+}
+
+// comentariosAntes escribe en líneas propias los comentarios pendientes que
+// comienzan antes de offset
+func (imp *impresor) comentariosAntes(offset int) {
+	for imp.siguiente < len(imp.comentarios) && imp.comentarios[imp.siguiente].Offset < offset {
+		c := imp.comentarios[imp.siguiente]
+		imp.separar(c.Linea)
+		imp.sangrar()
+		imp.sb.WriteString(c.Texto)
+		imp.sb.WriteString("\n")
+		imp.ultimaLinea = c.LineaFinal()
+		imp.siguiente++
+	}
+}
+
+// comentariosFinales escribe al final de la línea actual los comentarios
+// pendientes que aparecían dentro de la declaración que termina en fin o en
+// su misma línea después de ella y antes de limite
+func (imp *impresor) comentariosFinales(fin parser.Posicion, limite int) {
+	imp.ultimaLinea = fin.Linea
+	despuesDeLinea := false // el último comentario escrito fue de línea
+	for imp.siguiente < len(imp.comentarios) {
+		c := imp.comentarios[imp.siguiente]
+		if c.Offset >= fin.Offset && (c.Linea != fin.Linea || c.Offset >= limite) {
+			break
+		}
+		if despuesDeLinea {
+			// Nada puede seguir a un comentario de línea en la misma línea
+			imp.sb.WriteString("\n")
+			imp.sangrar()
+		} else {
+			imp.sb.WriteString(" ")
+		}
+		imp.sb.WriteString(c.Texto)
+		despuesDeLinea = !c.Bloque
+		if c.LineaFinal() > imp.ultimaLinea {
+			imp.ultimaLinea = c.LineaFinal()
+		}
+		imp.siguiente++
+	}
+}
+
+// separar escribe una línea en blanco si en el código original había al
+// menos una entre lo último escrito y lo que comienza en linea
+func (imp *impresor) separar(linea int) {
+	if imp.ultimaLinea > 0 && linea > imp.ultimaLinea+1 {
+		imp.sb.WriteString("\n")
+	}
+}
+
+func (imp *impresor) sangrar() {
+	imp.sb.WriteString(strings.Repeat(Sangria, imp.nivel))
+}
+
+func (imp *impresor) escribir(partes ...string) {
+	for _, parte := range partes {
+		imp.sb.WriteString(parte)
+	}
+}
+
+// lexema devuelve cómo se escribe un símbolo en el dialecto del código
+func (imp *impresor) lexema(tipo lexer.TokenType) string {
+	if lexema, ok := imp.dialecto.Lexema(tipo); ok {
+		return lexema
+	}
+	lexema, _ := lexer.DialectoBase().Lexema(tipo)
+	return lexema
+}
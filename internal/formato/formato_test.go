@@ -0,0 +1,89 @@
+package formato
+
+import "testing"
+
+func TestFormatear(t *testing.T) {
+	casos := []struct {
+		nombre   string
+		codigo   string
+		esperado string
+	}{
+		{"espacios y una declaración por línea", "int x=1;int y=x*2;",
+			"int x = 1;\nint y = x * 2;\n"},
+		{"comentario antes de una declaración", "// cabecera\nint x=1;",
+			"// cabecera\nint x = 1;\n"},
+		{"comentario al final de la línea", "int x=1; // final\nint y=2;",
+			"int x = 1; // final\nint y = 2;\n"},
+		{"comentario dentro de una expresión", "int x=1 /* dentro */ +2;",
+			"int x = 1 + 2; /* dentro */\n"},
+		{"comentario antes del punto y coma", "int x=1 /* c */;",
+			"int x = 1; /* c */\n"},
+		{"comentario de bloque y de línea seguidos", "int x=1; /* uno */ // dos\n",
+			"int x = 1; /* uno */ // dos\n"},
+		{"comentario final y otro en su propia línea", "int x=1; // a\n// b\nint y=2;",
+			"int x = 1; // a\n// b\nint y = 2;\n"},
+		{"comentario de varias líneas entre declaraciones", "int x=1; /* a\nb */ int y=2;",
+			"int x = 1; /* a\nb */\nint y = 2;\n"},
+		{"comentario al final del archivo", "int x=1;\n// fin",
+			"int x = 1;\n// fin\n"},
+		{"una línea en blanco como máximo", "int x=1;\n\n\n\nint y=2;",
+			"int x = 1;\n\nint y = 2;\n"},
+		{"línea en blanco después de un comentario", "// a\n\nint x=1;",
+			"// a\n\nint x = 1;\n"},
+		{"comentarios dentro de un do-while",
+			"int x = 0;\ndo{\n// antes\nx=x+1; // tras\n// al final del cuerpo\n}while(x==1);",
+			"int x = 0;\ndo {\n    // antes\n    x = x + 1; // tras\n    // al final del cuerpo\n} while (x == 1);\n"},
+		{"comentario después de la llave de un do-while", "int x=0; do { // abre\n} while (x==1);",
+			"int x = 0;\ndo {\n    // abre\n} while (x == 1);\n"},
+		{"comentario en la condición de un do-while", "int x=0;\ndo {\n} while (x /* c */ == 1);",
+			"int x = 0;\ndo {\n} while (x == 1); /* c */\n"},
+		{"comentarios dentro de una función",
+			"int f(int a,int b){\n// primero\nreturn a+b; // ret\n// antes de la llave\n}",
+			"int f(int a, int b) {\n    // primero\n    return a + b; // ret\n    // antes de la llave\n}\n"},
+		{"función con solo un comentario", "int f() {\n/* vacio */\n}",
+			"int f() {\n    /* vacio */\n}\n"},
+		{"arreglos, lectura e impresión", "int v[2]={1,2};\nread(v[0]);\nprint(v[1]);",
+			"int v[2] = {1, 2};\nread(v[0]);\nprint(v[1]);\n"},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			formateado, lexicos, sintacticos := Formatear(c.codigo, nil)
+			if len(lexicos) > 0 || len(sintacticos) > 0 {
+				t.Fatalf("errores: %v %v", lexicos, sintacticos)
+			}
+			if formateado != c.esperado {
+				t.Errorf("Formatear(%q) =\n%s\nse esperaba\n%s", c.codigo, formateado, c.esperado)
+			}
+			// Formatear código ya formateado no lo cambia
+			if otra, _, _ := Formatear(formateado, nil); otra != formateado {
+				t.Errorf("el formato no es idempotente:\n%s\nse volvió\n%s", formateado, otra)
+			}
+		})
+	}
+}
+
+// El código con errores se devuelve sin cambios, porque el parser descarta
+// las declaraciones inválidas
+func TestFormatearConErrores(t *testing.T) {
+	casos := []struct {
+		nombre      string
+		codigo      string
+		lexicos     bool
+		sintacticos bool
+	}{
+		{"error de sintaxis", "int x  =  ;\nint y=1;", false, true},
+		{"error léxico", "int x=1; @", true, true},
+		{"comentario sin cerrar", "int x=1; /* abc", true, false},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			formateado, lexicos, sintacticos := Formatear(c.codigo, nil)
+			if formateado != c.codigo {
+				t.Errorf("Formatear(%q) = %q, se esperaba el código sin cambios", c.codigo, formateado)
+			}
+			if (len(lexicos) > 0) != c.lexicos || (len(sintacticos) > 0) != c.sintacticos {
+				t.Errorf("errores léxicos %v y sintácticos %v", lexicos, sintacticos)
+			}
+		})
+	}
+}
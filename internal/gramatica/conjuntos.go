@@ -0,0 +1,89 @@
+package gramatica
+
+// calcularPrimeros calcula PRIMERO de cada no terminal por punto fijo
+func (g *Gramatica) calcularPrimeros() {
+	g.primeros = map[string]conjunto{}
+	for _, nt := range g.NoTerminales {
+		g.primeros[nt] = conjunto{}
+	}
+	for cambio := true; cambio; {
+		cambio = false
+		for _, p := range g.Producciones {
+			if g.primeros[p.Izquierda].agregar(g.primerosCadena(p.Derecha), false) {
+				cambio = true
+			}
+		}
+	}
+}
+
+// primerosCadena calcula PRIMERO de una secuencia de símbolos con los
+// conjuntos actuales. Incluye ε si toda la secuencia puede anularse.
+func (g *Gramatica) primerosCadena(cadena []string) conjunto {
+	resultado := conjunto{}
+	for _, s := range cadena {
+		if !g.esNoTerminal[s] {
+			resultado[s] = true
+			return resultado
+		}
+		resultado.agregar(g.primeros[s], true)
+		if !g.primeros[s][Epsilon] {
+			return resultado
+		}
+	}
+	resultado[Epsilon] = true
+	return resultado
+}
+
+// calcularSiguientes calcula SIGUIENTE de cada no terminal por punto fijo
+func (g *Gramatica) calcularSiguientes() {
+	g.siguientes = map[string]conjunto{}
+	for _, nt := range g.NoTerminales {
+		g.siguientes[nt] = conjunto{}
+	}
+	g.siguientes[g.Inicial][FinEntrada] = true
+	for cambio := true; cambio; {
+		cambio = false
+		for _, p := range g.Producciones {
+			for i, s := range p.Derecha {
+				if !g.esNoTerminal[s] {
+					continue
+				}
+				resto := g.primerosCadena(p.Derecha[i+1:])
+				if g.siguientes[s].agregar(resto, true) {
+					cambio = true
+				}
+				if resto[Epsilon] && g.siguientes[s].agregar(g.siguientes[p.Izquierda], false) {
+					cambio = true
+				}
+			}
+		}
+	}
+}
+
+// Primeros devuelve el conjunto PRIMERO de cada no terminal, ordenado
+func (g *Gramatica) Primeros() map[string][]string {
+	return ordenarConjuntos(g.primeros)
+}
+
+// Siguientes devuelve el conjunto SIGUIENTE de cada no terminal, ordenado
+func (g *Gramatica) Siguientes() map[string][]string {
+	return ordenarConjuntos(g.siguientes)
+}
+
+// PrimerosDe devuelve PRIMERO de una secuencia de símbolos
+func (g *Gramatica) PrimerosDe(cadena []string) []string {
+	return g.primerosCadena(cadena).ordenado()
+}
+
+// Anulable indica si el no terminal deriva la cadena vacía
+func (g *Gramatica) Anulable(noTerminal string) bool {
+	return g.primeros[noTerminal][Epsilon]
+}
+
+func ordenarConjuntos(conjuntos map[string]conjunto) map[string][]string {
+	resultado := make(map[string][]string, len(conjuntos))
+	for nt, c := range conjuntos {
+		resultado[nt] = c.ordenado()
+	}
+	return resultado
+}
@@ -0,0 +1,236 @@
+package gramatica
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Símbolos especiales
+const (
+	Epsilon    = "ε" // cadena vacía
+	FinEntrada = "$" // fin de la entrada en los conjuntos SIGUIENTE y las tablas
+)
+
+// Produccion es una regla de la gramática en BNF. Derecha vacía representa ε.
+type Produccion struct {
+	Izquierda string   `json:"izquierda"`
+	Derecha   []string `json:"derecha"`
+}
+
+// String muestra la producción como "a → b c"
+func (p Produccion) String() string {
+	if len(p.Derecha) == 0 {
+		return p.Izquierda + " → " + Epsilon
+	}
+	return p.Izquierda + " → " + strings.Join(p.Derecha, " ")
+}
+
+// Gramatica es una gramática libre de contexto en BNF. Las construcciones
+// EBNF del texto original se reemplazan por no terminales auxiliares.
+//
+// Un símbolo es no terminal si tiene al menos una regla; el resto son
+// terminales. Un terminal escrito como identificador (IDENT, SEMI) coincide
+// con el tipo de un token y uno entre comillas ("int", ";") con su lexema.
+type Gramatica struct {
+	Inicial      string       `json:"inicial"`
+	Producciones []Produccion `json:"producciones"`
+	NoTerminales []string     `json:"noTerminales"` // en orden de aparición
+	Terminales   []string     `json:"terminales"`   // en orden de aparición
+	Advertencias []string     `json:"advertencias,omitempty"`
+	esNoTerminal map[string]bool
+	porIzquierda map[string][]int // índices de las producciones de cada no terminal
+	primeros     map[string]conjunto
+	siguientes   map[string]conjunto
+}
+
+// EsNoTerminal indica si el símbolo tiene reglas en la gramática
+func (g *Gramatica) EsNoTerminal(simbolo string) bool {
+	return g.esNoTerminal[simbolo]
+}
+
+// ProduccionesDe devuelve los índices de las producciones del no terminal
+func (g *Gramatica) ProduccionesDe(noTerminal string) []int {
+	return g.porIzquierda[noTerminal]
+}
+
+// String devuelve la gramática en BNF, con las alternativas de cada no
+// terminal agrupadas en una regla
+func (g *Gramatica) String() string {
+	var sb strings.Builder
+	ancho := 0
+	for _, nt := range g.NoTerminales {
+		ancho = max(ancho, len([]rune(nt)))
+	}
+	for _, nt := range g.NoTerminales {
+		var alternativas []string
+		for _, i := range g.porIzquierda[nt] {
+			if derecha := g.Producciones[i].Derecha; len(derecha) > 0 {
+				alternativas = append(alternativas, strings.Join(derecha, " "))
+			} else {
+				alternativas = append(alternativas, Epsilon)
+			}
+		}
+		relleno := strings.Repeat(" ", ancho-len([]rune(nt)))
+		fmt.Fprintf(&sb, "%s%s → %s\n", nt, relleno, strings.Join(alternativas, " | "))
+	}
+	return sb.String()
+}
+
+// Cargar lee una gramática escrita en BNF o EBNF. Cada regla tiene la forma
+//
+//	nombre → alternativa | alternativa ...
+//
+// donde la flecha puede escribirse también "->" o "::=" y cada regla puede
+// terminar opcionalmente en ";". Dentro de las alternativas se admiten
+// ( ) para agrupar, [ ] o el sufijo ? para lo opcional, { } o el sufijo *
+// para cero o más repeticiones y el sufijo + para una o más. La cadena vacía
+// se escribe ε, epsilon o como una alternativa vacía. Los comentarios
+// comienzan con # o // y llegan hasta el fin de la línea. El símbolo inicial
+// es el de la primera regla. El texto no puede superar MaxLongitudGramatica
+// bytes.
+func Cargar(texto string) (*Gramatica, error) {
+	if len(texto) > MaxLongitudGramatica {
+		return nil, fmt.Errorf("%w: la gramática tiene %d bytes y el máximo es %d", ErrLimite, len(texto), MaxLongitudGramatica)
+	}
+	piezas, err := separarPiezas(texto)
+	if err != nil {
+		return nil, err
+	}
+	l := &lectorGramatica{piezas: piezas, nombres: map[string]bool{}}
+	for _, p := range piezas {
+		if p.tipo == piezaNombre {
+			l.nombres[p.texto] = true
+		}
+	}
+	if err := l.reglas(); err != nil {
+		return nil, err
+	}
+	if len(l.producciones) == 0 {
+		return nil, fmt.Errorf("la gramática no tiene reglas")
+	}
+	return Nueva(l.producciones[0].Izquierda, l.producciones)
+}
+
+// Nueva crea una gramática a partir de sus producciones en BNF. Devuelve un
+// error que envuelve ErrLimite si hay más de MaxProducciones.
+func Nueva(inicial string, producciones []Produccion) (*Gramatica, error) {
+	if len(producciones) > MaxProducciones {
+		return nil, fmt.Errorf("%w: hay %d producciones y el máximo es %d", ErrLimite, len(producciones), MaxProducciones)
+	}
+	g := &Gramatica{
+		Inicial:      inicial,
+		Producciones: producciones,
+		esNoTerminal: map[string]bool{},
+		porIzquierda: map[string][]int{},
+	}
+	for i, p := range producciones {
+		if p.Izquierda == "" {
+			return nil, fmt.Errorf("producción %d sin lado izquierdo", i)
+		}
+		if !g.esNoTerminal[p.Izquierda] {
+			g.esNoTerminal[p.Izquierda] = true
+			g.NoTerminales = append(g.NoTerminales, p.Izquierda)
+		}
+		g.porIzquierda[p.Izquierda] = append(g.porIzquierda[p.Izquierda], i)
+	}
+	if !g.esNoTerminal[inicial] {
+		return nil, fmt.Errorf("el símbolo inicial '%s' no tiene reglas", inicial)
+	}
+
+	vistos := map[string]bool{}
+	for _, p := range producciones {
+		for _, s := range p.Derecha {
+			if s == Epsilon || s == FinEntrada {
+				return nil, fmt.Errorf("%s: el símbolo '%s' está reservado", p, s)
+			}
+			if !g.esNoTerminal[s] && !vistos[s] {
+				vistos[s] = true
+				g.Terminales = append(g.Terminales, s)
+				if !esLiteral(s) && s != strings.ToUpper(s) {
+					g.Advertencias = append(g.Advertencias,
+						fmt.Sprintf("'%s' no tiene reglas; se trata como terminal", s))
+				}
+			}
+		}
+	}
+	g.verificarAlcanzables()
+
+	g.calcularPrimeros()
+	g.calcularSiguientes()
+	return g, nil
+}
+
+// verificarAlcanzables advierte sobre los no terminales que no se pueden
+// derivar desde el símbolo inicial
+func (g *Gramatica) verificarAlcanzables() {
+	alcanzados := map[string]bool{g.Inicial: true}
+	pendientes := []string{g.Inicial}
+	for len(pendientes) > 0 {
+		nt := pendientes[len(pendientes)-1]
+		pendientes = pendientes[:len(pendientes)-1]
+		for _, i := range g.porIzquierda[nt] {
+			for _, s := range g.Producciones[i].Derecha {
+				if g.esNoTerminal[s] && !alcanzados[s] {
+					alcanzados[s] = true
+					pendientes = append(pendientes, s)
+				}
+			}
+		}
+	}
+	for _, nt := range g.NoTerminales {
+		if !alcanzados[nt] {
+			g.Advertencias = append(g.Advertencias,
+				fmt.Sprintf("el no terminal '%s' no es alcanzable desde '%s'", nt, g.Inicial))
+		}
+	}
+}
+
+// esLiteral indica si el terminal se escribió entre comillas
+func esLiteral(simbolo string) bool {
+	return len(simbolo) >= 2 && simbolo[0] == '"' && simbolo[len(simbolo)-1] == '"'
+}
+
+// conjunto es un conjunto de símbolos
+type conjunto map[string]bool
+
+// agregar une otro conjunto, sin ε si sinEpsilon, y devuelve si cambió
+func (c conjunto) agregar(otro conjunto, sinEpsilon bool) bool {
+	cambio := false
+	for s := range otro {
+		if sinEpsilon && s == Epsilon {
+			continue
+		}
+		if !c[s] {
+			c[s] = true
+			cambio = true
+		}
+	}
+	return cambio
+}
+
+// ordenado devuelve los símbolos ordenados, con ε y $ al final
+func (c conjunto) ordenado() []string {
+	simbolos := make([]string, 0, len(c))
+	for s := range c {
+		simbolos = append(simbolos, s)
+	}
+	sort.Slice(simbolos, func(i, j int) bool {
+		pi, pj := pesoOrden(simbolos[i]), pesoOrden(simbolos[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return simbolos[i] < simbolos[j]
+	})
+	return simbolos
+}
+
+func pesoOrden(s string) int {
+	switch s {
+	case FinEntrada:
+		return 1
+	case Epsilon:
+		return 2
+	}
+	return 0
+}
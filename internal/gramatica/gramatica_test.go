@@ -0,0 +1,305 @@
+package gramatica
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"analyzer-api/internal/lexer"
+)
+
+// Gramáticas de ejemplo del libro del dragón
+const (
+	// gramaticaAsignacion no es LL(1) ni SLR(1), por el conflicto en "="
+	// tras L, pero sí LALR(1)
+	gramaticaAsignacion = `
+S → L "=" R | R
+L → "*" R | id
+R → L
+`
+	// gramaticaExpresiones es la gramática de expresiones sin recursión
+	// por la izquierda
+	gramaticaExpresiones = `
+E  → T E2
+E2 → "+" T E2 | ε
+T  → F T2
+T2 → "*" F T2 | ε
+F  → "(" E ")" | id
+`
+	// gramaticaTokens es la gramática de expresiones con los terminales
+	// escritos como tipos de token del lenguaje
+	gramaticaTokens = `
+E  → T E2
+E2 → "+" T E2 | ε
+T  → F T2
+T2 → "*" F T2 | ε
+F  → "(" E ")" | IDENT | NUMBER
+`
+)
+
+func cargar(t *testing.T, texto string) *Gramatica {
+	t.Helper()
+	g, err := Cargar(texto)
+	if err != nil {
+		t.Fatalf("Cargar: %v", err)
+	}
+	return g
+}
+
+func tablaLR(t *testing.T, g *Gramatica, metodo MetodoLR) *TablaLR {
+	t.Helper()
+	tabla, err := g.TablaLR(metodo)
+	if err != nil {
+		t.Fatalf("TablaLR: %v", err)
+	}
+	return tabla
+}
+
+func TestConjuntosExpresiones(t *testing.T) {
+	g := cargar(t, gramaticaExpresiones)
+	primeros, siguientes := g.Primeros(), g.Siguientes()
+
+	casos := []struct {
+		noTerminal string
+		primeros   []string
+		siguientes []string
+		anulable   bool
+	}{
+		{"E", []string{`"("`, "id"}, []string{`")"`, "$"}, false},
+		{"E2", []string{`"+"`, "ε"}, []string{`")"`, "$"}, true},
+		{"T", []string{`"("`, "id"}, []string{`")"`, `"+"`, "$"}, false},
+		{"T2", []string{`"*"`, "ε"}, []string{`")"`, `"+"`, "$"}, true},
+		{"F", []string{`"("`, "id"}, []string{`")"`, `"*"`, `"+"`, "$"}, false},
+	}
+	for _, c := range casos {
+		t.Run(c.noTerminal, func(t *testing.T) {
+			if got := primeros[c.noTerminal]; !reflect.DeepEqual(got, c.primeros) {
+				t.Errorf("PRIMERO(%s) = %v, se esperaba %v", c.noTerminal, got, c.primeros)
+			}
+			if got := siguientes[c.noTerminal]; !reflect.DeepEqual(got, c.siguientes) {
+				t.Errorf("SIGUIENTE(%s) = %v, se esperaba %v", c.noTerminal, got, c.siguientes)
+			}
+			if got := g.Anulable(c.noTerminal); got != c.anulable {
+				t.Errorf("Anulable(%s) = %v, se esperaba %v", c.noTerminal, got, c.anulable)
+			}
+		})
+	}
+
+	if got, want := g.PrimerosDe([]string{"T2", "E2"}), []string{`"*"`, `"+"`, "ε"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PRIMERO(T2 E2) = %v, se esperaba %v", got, want)
+	}
+	if !g.TablaLL1().EsLL1() {
+		t.Errorf("la gramática de expresiones debería ser LL(1)")
+	}
+}
+
+func TestTablaLL1(t *testing.T) {
+	casos := []struct {
+		nombre     string
+		gramatica  string
+		conflictos []string // "no terminal, terminal: producciones"
+	}{
+		{"expresiones", gramaticaExpresiones, nil},
+		{"asignacion", gramaticaAsignacion, []string{`S, "*": [0 1]`, "S, id: [0 1]"}},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			tabla := cargar(t, c.gramatica).TablaLL1()
+			var conflictos []string
+			for _, cf := range tabla.Conflictos {
+				conflictos = append(conflictos, fmt.Sprintf("%s, %s: %v", cf.NoTerminal, cf.Terminal, cf.Producciones))
+			}
+			if !reflect.DeepEqual(conflictos, c.conflictos) {
+				t.Errorf("conflictos %v, se esperaban %v", conflictos, c.conflictos)
+			}
+			if tabla.EsLL1() != (len(c.conflictos) == 0) {
+				t.Errorf("EsLL1() = %v", tabla.EsLL1())
+			}
+		})
+	}
+}
+
+func TestAnalizarLL1(t *testing.T) {
+	tabla := cargar(t, gramaticaTokens).TablaLL1()
+	casos := []struct {
+		entrada  string
+		aceptada bool
+		error    string
+		columna  int
+	}{
+		{"a + b * (c + 1)", true, "", 0},
+		{"a + * b", false, "T no puede comenzar con '*'", 5},
+		{"(a", false, `se esperaba ")", se encontró el fin de la entrada`, 3},
+		{"", false, "E no puede comenzar con el fin de la entrada", 1},
+	}
+	for _, c := range casos {
+		t.Run(c.entrada, func(t *testing.T) {
+			traza := tabla.Analizar(lexer.New(c.entrada).Analizar())
+			if traza.Aceptada != c.aceptada || traza.Error != c.error || traza.Columna != c.columna {
+				t.Errorf("aceptada=%v error=%q columna=%d, se esperaba aceptada=%v error=%q columna=%d",
+					traza.Aceptada, traza.Error, traza.Columna, c.aceptada, c.error, c.columna)
+			}
+			ultima := "error"
+			if c.aceptada {
+				ultima = "aceptar"
+			}
+			if n := len(traza.Pasos); n == 0 || traza.Pasos[n-1].Accion != ultima {
+				t.Errorf("el último paso no es %s: %+v", ultima, traza.Pasos)
+			}
+		})
+	}
+}
+
+// Cada paso de la traza muestra solo la cima de la pila, así que su tamaño
+// no crece con la profundidad aunque la gramática apile un símbolo por token
+func TestTrazaLL1PilaProfunda(t *testing.T) {
+	tabla := cargar(t, "S → IDENT S NUMBER | ε").TablaLL1()
+	entrada := strings.Repeat("a ", 5000) + strings.Repeat("1 ", 5000)
+	traza := tabla.Analizar(lexer.New(entrada).Analizar())
+
+	profundidad := 0
+	for i, paso := range traza.Pasos {
+		if len(paso.Pila) > VentanaPila || len(paso.Entrada) > VentanaEntrada+1 {
+			t.Fatalf("paso %d: pila de %d y entrada de %d símbolos", i, len(paso.Pila), len(paso.Entrada))
+		}
+		if len(paso.Pila) != min(paso.ProfundidadPila, VentanaPila) {
+			t.Fatalf("paso %d: pila %v con profundidad %d", i, paso.Pila, paso.ProfundidadPila)
+		}
+		profundidad = max(profundidad, paso.ProfundidadPila)
+	}
+	if profundidad < 5000 {
+		t.Errorf("profundidad máxima %d, se esperaba al menos 5000", profundidad)
+	}
+	if len(traza.Pasos) != MaximoPasos {
+		t.Errorf("%d pasos, se esperaba el máximo de %d", len(traza.Pasos), MaximoPasos)
+	}
+}
+
+func TestConflictosLR(t *testing.T) {
+	casos := []struct {
+		nombre     string
+		gramatica  string
+		metodo     MetodoLR
+		estados    int
+		conflictos []string // "tipo en terminal"
+	}{
+		{"asignacion LR(0)", gramaticaAsignacion, LR0, 10, []string{`desplazamiento/reducción en "="`}},
+		{"asignacion SLR", gramaticaAsignacion, SLR, 10, []string{`desplazamiento/reducción en "="`}},
+		{"asignacion LALR", gramaticaAsignacion, LALR1, 10, nil},
+		{"expresiones LR(0)", gramaticaExpresiones, LR0, 16, []string{
+			`desplazamiento/reducción en "+"`, `desplazamiento/reducción en "*"`,
+			`desplazamiento/reducción en "+"`, `desplazamiento/reducción en "*"`,
+		}},
+		{"expresiones SLR", gramaticaExpresiones, SLR, 16, nil},
+		{"expresiones LALR", gramaticaExpresiones, LALR1, 16, nil},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			tabla := tablaLR(t, cargar(t, c.gramatica), c.metodo)
+			if len(tabla.Estados) != c.estados {
+				t.Errorf("%d estados, se esperaban %d", len(tabla.Estados), c.estados)
+			}
+			var conflictos []string
+			for _, cf := range tabla.Conflictos {
+				conflictos = append(conflictos, cf.Tipo+" en "+cf.Terminal)
+			}
+			if !reflect.DeepEqual(conflictos, c.conflictos) {
+				t.Errorf("conflictos %v, se esperaban %v", conflictos, c.conflictos)
+			}
+			if tabla.SinConflictos() != (len(c.conflictos) == 0) {
+				t.Errorf("SinConflictos() = %v", tabla.SinConflictos())
+			}
+		})
+	}
+}
+
+func TestAnalizarLR(t *testing.T) {
+	g := cargar(t, gramaticaTokens)
+	casos := []struct {
+		entrada  string
+		aceptada bool
+		error    string
+		columna  int
+	}{
+		{"a + b * (c + 1)", true, "", 0},
+		{"a + * b", false, `se encontró '*', se esperaba uno de: "(", IDENT, NUMBER`, 5},
+		{"(a", false, `se encontró el fin de la entrada, se esperaba ")"`, 3},
+	}
+	for _, metodo := range []MetodoLR{SLR, LALR1} {
+		tabla := tablaLR(t, g, metodo)
+		for _, c := range casos {
+			t.Run(string(metodo)+" "+c.entrada, func(t *testing.T) {
+				traza := tabla.Analizar(lexer.New(c.entrada).Analizar())
+				if traza.Aceptada != c.aceptada || traza.Error != c.error || traza.Columna != c.columna {
+					t.Errorf("aceptada=%v error=%q columna=%d, se esperaba aceptada=%v error=%q columna=%d",
+						traza.Aceptada, traza.Error, traza.Columna, c.aceptada, c.error, c.columna)
+				}
+			})
+		}
+	}
+}
+
+// Con una gramática recursiva por la derecha la pila crece con cada token
+// hasta el final de la entrada; cada paso de la traza muestra solo su cima
+func TestTrazaLRPilaProfunda(t *testing.T) {
+	tabla := tablaLR(t, cargar(t, "S → IDENT S | IDENT"), LALR1)
+	traza := tabla.Analizar(lexer.New(strings.Repeat("a ", 4000)).Analizar())
+	if !traza.Aceptada {
+		t.Fatalf("entrada rechazada: %s", traza.Error)
+	}
+
+	profundidad := 0
+	for i, paso := range traza.Pasos {
+		if len(paso.Estados) != min(paso.ProfundidadPila, VentanaPila) || len(paso.Simbolos) != len(paso.Estados)-1 {
+			t.Fatalf("paso %d: %d estados y %d símbolos con profundidad %d",
+				i, len(paso.Estados), len(paso.Simbolos), paso.ProfundidadPila)
+		}
+		profundidad = max(profundidad, paso.ProfundidadPila)
+	}
+	if profundidad <= 4000 {
+		t.Errorf("profundidad máxima %d, se esperaba más de 4000", profundidad)
+	}
+}
+
+func TestLimites(t *testing.T) {
+	var muchas strings.Builder
+	for i := 0; i <= MaxProducciones; i++ {
+		fmt.Fprintf(&muchas, "S → a%d\n", i)
+	}
+	// Cada posición de la única producción es un estado LR(0)
+	larga := "S → " + strings.Repeat("x ", MaxEstadosLR)
+	// Con un terminal distinto en cada posición la tabla crece con el
+	// cuadrado de la longitud
+	var distintos strings.Builder
+	distintos.WriteString("S →")
+	for i := 0; i < 1100; i++ {
+		fmt.Fprintf(&distintos, " t%d", i)
+	}
+
+	casos := []struct {
+		nombre    string
+		gramatica string
+		cargar    bool // el límite se supera al cargar; si no, al construir la tabla LR
+	}{
+		{"texto largo", "S → x\n# " + strings.Repeat("-", MaxLongitudGramatica), true},
+		{"demasiadas producciones", muchas.String(), true},
+		{"demasiados estados", larga, false},
+		{"tabla demasiado grande", distintos.String(), false},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			g, err := Cargar(c.gramatica)
+			if !c.cargar {
+				if err != nil {
+					t.Fatalf("Cargar: %v", err)
+				}
+				_, err = g.TablaLR(LALR1)
+			}
+			if !errors.Is(err, ErrLimite) {
+				t.Errorf("error %v, se esperaba ErrLimite", err)
+			}
+		})
+	}
+}
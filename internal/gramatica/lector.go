@@ -0,0 +1,275 @@
+package gramatica
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tipoPieza clasifica las piezas léxicas del texto de una gramática
+type tipoPieza int
+
+const (
+	piezaNombre  tipoPieza = iota // identificador de un símbolo
+	piezaLiteral                  // terminal entre comillas
+	piezaFlecha                   // → -> ::=
+	piezaEpsilon                  // ε epsilon
+	piezaSimbolo                  // | ( ) [ ] { } * + ? ;
+	piezaFin
+)
+
+type pieza struct {
+	tipo  tipoPieza
+	texto string
+	linea int
+}
+
+// separarPiezas divide el texto de la gramática en piezas léxicas
+func separarPiezas(texto string) ([]pieza, error) {
+	var piezas []pieza
+	linea := 1
+	for i := 0; i < len(texto); {
+		r, ancho := utf8.DecodeRuneInString(texto[i:])
+		switch {
+		case r == '\n':
+			linea++
+			i++
+		case unicode.IsSpace(r):
+			i += ancho
+		case r == '#' || strings.HasPrefix(texto[i:], "//"):
+			for i < len(texto) && texto[i] != '\n' {
+				i++
+			}
+		case r == '→':
+			piezas = append(piezas, pieza{piezaFlecha, "→", linea})
+			i += ancho
+		case strings.HasPrefix(texto[i:], "->"):
+			piezas = append(piezas, pieza{piezaFlecha, "->", linea})
+			i += 2
+		case strings.HasPrefix(texto[i:], "::="):
+			piezas = append(piezas, pieza{piezaFlecha, "::=", linea})
+			i += 3
+		case r == 'ε':
+			piezas = append(piezas, pieza{piezaEpsilon, "ε", linea})
+			i += ancho
+		case strings.ContainsRune("|()[]{}*+?;", r):
+			piezas = append(piezas, pieza{piezaSimbolo, string(r), linea})
+			i++
+		case r == '"' || r == '\'':
+			fin := strings.IndexRune(texto[i+1:], r)
+			if fin < 0 || strings.Contains(texto[i+1:i+1+fin], "\n") {
+				return nil, fmt.Errorf("línea %d: literal sin cerrar", linea)
+			}
+			literal := texto[i+1 : i+1+fin]
+			if literal == "" {
+				return nil, fmt.Errorf("línea %d: literal vacío", linea)
+			}
+			piezas = append(piezas, pieza{piezaLiteral, `"` + literal + `"`, linea})
+			i += fin + 2
+		case unicode.IsLetter(r) || r == '_':
+			inicio := i
+			for i < len(texto) {
+				r, ancho := utf8.DecodeRuneInString(texto[i:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '\'' {
+					break
+				}
+				i += ancho
+			}
+			nombre := texto[inicio:i]
+			if nombre == "epsilon" {
+				piezas = append(piezas, pieza{piezaEpsilon, nombre, linea})
+			} else {
+				piezas = append(piezas, pieza{piezaNombre, nombre, linea})
+			}
+		default:
+			return nil, fmt.Errorf("línea %d: caracter inesperado '%c'", linea, r)
+		}
+	}
+	return append(piezas, pieza{piezaFin, "", linea}), nil
+}
+
+// lectorGramatica analiza las piezas por descenso recursivo y traduce las
+// construcciones EBNF a producciones BNF con no terminales auxiliares
+type lectorGramatica struct {
+	piezas       []pieza
+	pos          int
+	nombres      map[string]bool // nombres usados, para no repetirlos
+	producciones []Produccion
+	auxiliares   []Produccion // producciones auxiliares de la regla actual
+	regla        string       // no terminal de la regla actual
+	contador     int
+}
+
+func (l *lectorGramatica) actual() pieza    { return l.piezas[l.pos] }
+func (l *lectorGramatica) siguiente() pieza { return l.piezas[min(l.pos+1, len(l.piezas)-1)] }
+
+func (l *lectorGramatica) es(tipo tipoPieza, texto string) bool {
+	p := l.actual()
+	return p.tipo == tipo && (texto == "" || p.texto == texto)
+}
+
+func (l *lectorGramatica) errorf(formato string, args ...interface{}) error {
+	return fmt.Errorf("línea %d: %s", l.actual().linea, fmt.Sprintf(formato, args...))
+}
+
+// inicioRegla indica si en la posición actual comienza una regla nueva
+func (l *lectorGramatica) inicioRegla() bool {
+	return l.actual().tipo == piezaNombre && l.siguiente().tipo == piezaFlecha
+}
+
+// reglas: regla*
+func (l *lectorGramatica) reglas() error {
+	for !l.es(piezaFin, "") {
+		if l.es(piezaSimbolo, ";") {
+			l.pos++
+			continue
+		}
+		if !l.inicioRegla() {
+			return l.errorf("se esperaba el comienzo de una regla, se encontró '%s'", l.actual().texto)
+		}
+		l.regla = l.actual().texto
+		l.contador = 0
+		l.auxiliares = nil
+		l.pos += 2
+
+		alternativas, err := l.expresion()
+		if err != nil {
+			return err
+		}
+		if !l.es(piezaFin, "") && !l.es(piezaSimbolo, ";") && !l.inicioRegla() {
+			return l.errorf("símbolo inesperado '%s'", l.actual().texto)
+		}
+		for _, alt := range alternativas {
+			l.producciones = append(l.producciones, Produccion{Izquierda: l.regla, Derecha: alt})
+		}
+		l.producciones = append(l.producciones, l.auxiliares...)
+	}
+	return nil
+}
+
+// expresion: secuencia ('|' secuencia)*
+func (l *lectorGramatica) expresion() ([][]string, error) {
+	var alternativas [][]string
+	for {
+		secuencia, err := l.secuencia()
+		if err != nil {
+			return nil, err
+		}
+		alternativas = append(alternativas, secuencia)
+		if !l.es(piezaSimbolo, "|") {
+			return alternativas, nil
+		}
+		l.pos++
+	}
+}
+
+// secuencia: elemento*
+func (l *lectorGramatica) secuencia() ([]string, error) {
+	secuencia := []string{}
+	for {
+		p := l.actual()
+		switch {
+		case p.tipo == piezaFin, l.inicioRegla():
+			return secuencia, nil
+		case p.tipo == piezaSimbolo && strings.Contains("|)]};", p.texto):
+			return secuencia, nil
+		case p.tipo == piezaEpsilon:
+			l.pos++
+			continue
+		}
+		simbolo, err := l.elemento()
+		if err != nil {
+			return nil, err
+		}
+		secuencia = append(secuencia, simbolo...)
+	}
+}
+
+// elemento: atomo ('*' | '+' | '?')*
+func (l *lectorGramatica) elemento() ([]string, error) {
+	simbolos, err := l.atomo()
+	if err != nil {
+		return nil, err
+	}
+	for l.es(piezaSimbolo, "*") || l.es(piezaSimbolo, "+") || l.es(piezaSimbolo, "?") {
+		operador := l.actual().texto
+		l.pos++
+		switch operador {
+		case "*":
+			simbolos = []string{l.repeticion([][]string{simbolos})}
+		case "+":
+			simbolos = append(simbolos, l.repeticion([][]string{simbolos}))
+		case "?":
+			simbolos = []string{l.auxiliar("opc", [][]string{simbolos, {}})}
+		}
+	}
+	return simbolos, nil
+}
+
+// atomo: nombre | literal | '(' expresion ')' | '[' expresion ']' | '{' expresion '}'
+func (l *lectorGramatica) atomo() ([]string, error) {
+	p := l.actual()
+	switch {
+	case p.tipo == piezaNombre || p.tipo == piezaLiteral:
+		l.pos++
+		return []string{p.texto}, nil
+	case p.tipo == piezaSimbolo && (p.texto == "(" || p.texto == "[" || p.texto == "{"):
+		l.pos++
+		alternativas, err := l.expresion()
+		if err != nil {
+			return nil, err
+		}
+		cierre := map[string]string{"(": ")", "[": "]", "{": "}"}[p.texto]
+		if !l.es(piezaSimbolo, cierre) {
+			return nil, l.errorf("se esperaba '%s'", cierre)
+		}
+		l.pos++
+		switch p.texto {
+		case "(":
+			// Un grupo con una sola alternativa no necesita no terminal
+			if len(alternativas) == 1 {
+				return alternativas[0], nil
+			}
+			return []string{l.auxiliar("grupo", alternativas)}, nil
+		case "[":
+			return []string{l.auxiliar("opc", append(alternativas, []string{}))}, nil
+		default:
+			return []string{l.repeticion(alternativas)}, nil
+		}
+	}
+	return nil, l.errorf("símbolo inesperado '%s'", p.texto)
+}
+
+// repeticion crea N → alternativa N | ε para cero o más repeticiones
+func (l *lectorGramatica) repeticion(alternativas [][]string) string {
+	nombre := l.nuevoNombre("rep")
+	for _, alt := range alternativas {
+		derecha := append(append([]string{}, alt...), nombre)
+		l.auxiliares = append(l.auxiliares, Produccion{Izquierda: nombre, Derecha: derecha})
+	}
+	l.auxiliares = append(l.auxiliares, Produccion{Izquierda: nombre, Derecha: []string{}})
+	return nombre
+}
+
+// auxiliar crea un no terminal con las alternativas dadas
+func (l *lectorGramatica) auxiliar(tipo string, alternativas [][]string) string {
+	nombre := l.nuevoNombre(tipo)
+	for _, alt := range alternativas {
+		l.auxiliares = append(l.auxiliares, Produccion{Izquierda: nombre, Derecha: alt})
+	}
+	return nombre
+}
+
+// nuevoNombre genera un nombre de no terminal auxiliar que no se usa en la
+// gramática, como "expresion_rep1"
+func (l *lectorGramatica) nuevoNombre(tipo string) string {
+	for {
+		l.contador++
+		nombre := fmt.Sprintf("%s_%s%d", l.regla, tipo, l.contador)
+		if !l.nombres[nombre] {
+			l.nombres[nombre] = true
+			return nombre
+		}
+	}
+}
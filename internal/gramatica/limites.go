@@ -0,0 +1,17 @@
+package gramatica
+
+import "errors"
+
+// Límites de la construcción. Las gramáticas pueden llegar de cualquier
+// cliente de la API y la colección canónica LR(0) puede tener una cantidad
+// de estados exponencial en el tamaño de la gramática.
+const (
+	MaxLongitudGramatica = 16 << 10 // bytes del texto de la gramática
+	MaxProducciones      = 500      // producciones en BNF, con las auxiliares del EBNF
+	MaxEstadosLR         = 2000     // estados de la colección canónica LR(0)
+	MaxCeldasLR          = 1 << 20  // celdas de la tabla ACCION, estados por terminales
+)
+
+// ErrLimite indica que la gramática supera alguno de los límites de la
+// construcción
+var ErrLimite = errors.New("límite de la construcción superado")
@@ -0,0 +1,249 @@
+package gramatica
+
+import (
+	"fmt"
+	"strings"
+
+	"analyzer-api/internal/lexer"
+)
+
+// MaximoPasos limita los pasos de las trazas de análisis
+const MaximoPasos = 10000
+
+// VentanaEntrada es cuántos símbolos de la entrada restante muestra cada
+// paso de una traza
+const VentanaEntrada = 12
+
+// VentanaPila es cuántos elementos de la cima de la pila muestra cada paso
+// de una traza. Sin este recorte una pila profunda se copiaría entera en
+// cada paso.
+const VentanaPila = 12
+
+// ConflictoLL1 es una celda de la tabla LL(1) con más de una producción
+type ConflictoLL1 struct {
+	NoTerminal   string `json:"noTerminal"`
+	Terminal     string `json:"terminal"`
+	Producciones []int  `json:"producciones"`
+}
+
+// TablaLL1 es la tabla de análisis predictivo: para cada no terminal y
+// terminal de anticipación, las producciones aplicables (índices en
+// Gramatica.Producciones)
+type TablaLL1 struct {
+	Terminales []string                    `json:"terminales"` // columnas, con $ al final
+	Celdas     map[string]map[string][]int `json:"celdas"`
+	Conflictos []ConflictoLL1              `json:"conflictos"`
+	gramatica  *Gramatica
+}
+
+// EsLL1 indica si la tabla no tiene conflictos
+func (t *TablaLL1) EsLL1() bool {
+	return len(t.Conflictos) == 0
+}
+
+// TablaLL1 construye la tabla de análisis predictivo. Una gramática que no es
+// LL(1) produce conflictos; al analizar se usa la primera producción de la
+// celda.
+func (g *Gramatica) TablaLL1() *TablaLL1 {
+	t := &TablaLL1{
+		Terminales: append(append([]string{}, g.Terminales...), FinEntrada),
+		Celdas:     map[string]map[string][]int{},
+		Conflictos: []ConflictoLL1{},
+		gramatica:  g,
+	}
+	agregar := func(nt, terminal string, produccion int) {
+		if t.Celdas[nt] == nil {
+			t.Celdas[nt] = map[string][]int{}
+		}
+		for _, p := range t.Celdas[nt][terminal] {
+			if p == produccion {
+				return
+			}
+		}
+		t.Celdas[nt][terminal] = append(t.Celdas[nt][terminal], produccion)
+	}
+	for i, p := range g.Producciones {
+		primeros := g.primerosCadena(p.Derecha)
+		for _, terminal := range primeros.ordenado() {
+			if terminal != Epsilon {
+				agregar(p.Izquierda, terminal, i)
+			}
+		}
+		if primeros[Epsilon] {
+			for _, terminal := range g.siguientes[p.Izquierda].ordenado() {
+				agregar(p.Izquierda, terminal, i)
+			}
+		}
+	}
+
+	for _, nt := range g.NoTerminales {
+		for _, terminal := range t.Terminales {
+			if celda := t.Celdas[nt][terminal]; len(celda) > 1 {
+				t.Conflictos = append(t.Conflictos, ConflictoLL1{NoTerminal: nt, Terminal: terminal, Producciones: celda})
+			}
+		}
+	}
+	return t
+}
+
+// PasoLL1 es un paso del análisis predictivo
+type PasoLL1 struct {
+	Pila            []string `json:"pila"`            // hacia la cima, recortada a VentanaPila
+	ProfundidadPila int      `json:"profundidadPila"` // elementos de la pila completa
+	Entrada         []string `json:"entrada"`         // símbolos restantes, recortados a VentanaEntrada
+	Accion          string   `json:"accion"`
+}
+
+// TrazaAnalisis es el resultado de analizar una entrada con una tabla
+type TrazaAnalisis[P any] struct {
+	Aceptada bool   `json:"aceptada"`
+	Pasos    []P    `json:"pasos"`
+	Error    string `json:"error,omitempty"`
+	Linea    int    `json:"linea,omitempty"`
+	Columna  int    `json:"columna,omitempty"`
+}
+
+// Analizar recorre los tokens con el algoritmo predictivo dirigido por la
+// tabla y registra cada paso. Se detiene en el primer error.
+func (t *TablaLL1) Analizar(tokens []lexer.Token) *TrazaAnalisis[PasoLL1] {
+	g := t.gramatica
+	entrada := g.simbolosEntrada(tokens)
+	traza := &TrazaAnalisis[PasoLL1]{Pasos: []PasoLL1{}}
+	pila := []string{FinEntrada, g.Inicial}
+	pos := 0
+
+	for len(traza.Pasos) < MaximoPasos {
+		paso := PasoLL1{
+			Pila:            cimaPila(pila, VentanaPila),
+			ProfundidadPila: len(pila),
+			Entrada:         ventana(entrada[pos:]),
+		}
+		cima := pila[len(pila)-1]
+		actual := entrada[pos]
+
+		switch {
+		case cima == FinEntrada && actual.simbolo == FinEntrada:
+			paso.Accion = "aceptar"
+			traza.Pasos = append(traza.Pasos, paso)
+			traza.Aceptada = true
+			return traza
+
+		case !g.esNoTerminal[cima]:
+			if cima != actual.simbolo {
+				esperado := cima
+				if cima == FinEntrada {
+					esperado = "el fin de la entrada"
+				}
+				paso.Accion = "error"
+				traza.Pasos = append(traza.Pasos, paso)
+				traza.fallar(actual, fmt.Sprintf("se esperaba %s, se encontró %s", esperado, actual.mostrar()))
+				return traza
+			}
+			paso.Accion = "emparejar " + cima
+			pila = pila[:len(pila)-1]
+			pos++
+
+		default:
+			celda := t.Celdas[cima][actual.simbolo]
+			if len(celda) == 0 {
+				paso.Accion = "error"
+				traza.Pasos = append(traza.Pasos, paso)
+				traza.fallar(actual, fmt.Sprintf("%s no puede comenzar con %s", cima, actual.mostrar()))
+				return traza
+			}
+			produccion := g.Producciones[celda[0]]
+			paso.Accion = produccion.String()
+			pila = pila[:len(pila)-1]
+			for i := len(produccion.Derecha) - 1; i >= 0; i-- {
+				pila = append(pila, produccion.Derecha[i])
+			}
+		}
+		traza.Pasos = append(traza.Pasos, paso)
+	}
+	traza.Error = fmt.Sprintf("se superó el máximo de %d pasos", MaximoPasos)
+	return traza
+}
+
+func (t *TrazaAnalisis[P]) fallar(s simboloEntrada, mensaje string) {
+	t.Error = mensaje
+	t.Linea = s.token.Line
+	t.Columna = s.token.Column
+}
+
+// simboloEntrada es un token junto con el terminal de la gramática que le
+// corresponde
+type simboloEntrada struct {
+	simbolo string
+	token   lexer.Token
+}
+
+func (s simboloEntrada) mostrar() string {
+	if s.simbolo == FinEntrada {
+		return "el fin de la entrada"
+	}
+	return fmt.Sprintf("'%s'", s.token.Lexeme)
+}
+
+// simbolosEntrada traduce los tokens a terminales de la gramática: el lexema
+// entre comillas si la gramática lo usa como terminal y si no el tipo del
+// token. La entrada termina siempre en $.
+func (g *Gramatica) simbolosEntrada(tokens []lexer.Token) []simboloEntrada {
+	literales := map[string]bool{}
+	for _, terminal := range g.Terminales {
+		if esLiteral(terminal) {
+			literales[terminal] = true
+		}
+	}
+	entrada := make([]simboloEntrada, 0, len(tokens)+1)
+	for _, tok := range tokens {
+		if tok.Type == lexer.TOKEN_EOF {
+			entrada = append(entrada, simboloEntrada{FinEntrada, tok})
+			return entrada
+		}
+		simbolo := string(tok.Type)
+		if literal := `"` + tok.Lexeme + `"`; literales[literal] {
+			simbolo = literal
+		}
+		entrada = append(entrada, simboloEntrada{simbolo, tok})
+	}
+	fin := lexer.Token{Type: lexer.TOKEN_EOF}
+	if n := len(tokens); n > 0 {
+		fin.Line, fin.Column = tokens[n-1].Line, tokens[n-1].Column+len(tokens[n-1].Lexeme)
+	}
+	return append(entrada, simboloEntrada{FinEntrada, fin})
+}
+
+// ventana devuelve los primeros símbolos de la entrada restante
+func ventana(entrada []simboloEntrada) []string {
+	n := min(len(entrada), VentanaEntrada)
+	simbolos := make([]string, 0, n+1)
+	for _, s := range entrada[:n] {
+		simbolos = append(simbolos, s.simbolo)
+	}
+	if len(entrada) > n {
+		simbolos = append(simbolos, "…")
+	}
+	return simbolos
+}
+
+// cimaPila copia los últimos n elementos de la pila, del más profundo de
+// ellos a la cima
+func cimaPila[T any](pila []T, n int) []T {
+	return append([]T{}, pila[len(pila)-min(len(pila), n):]...)
+}
+
+// String muestra la tabla con una fila por no terminal y una columna por
+// terminal; cada celda lista los números de producción
+func (t *TablaLL1) String() string {
+	var sb strings.Builder
+	for _, nt := range t.gramatica.NoTerminales {
+		sb.WriteString(nt)
+		for _, terminal := range t.Terminales {
+			if celda := t.Celdas[nt][terminal]; len(celda) > 0 {
+				fmt.Fprintf(&sb, "  %s:%v", terminal, celda)
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,530 @@
+package gramatica
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"analyzer-api/internal/lexer"
+)
+
+// MetodoLR es el método con el que se calculan las reducciones de la tabla LR
+type MetodoLR string
+
+const (
+	LR0   MetodoLR = "LR(0)"   // reduce con cualquier anticipación
+	SLR   MetodoLR = "SLR(1)"  // reduce con los símbolos de SIGUIENTE
+	LALR1 MetodoLR = "LALR(1)" // reduce con las anticipaciones LALR
+)
+
+// Tipos de acción de la tabla ACCION
+const (
+	ACCION_DESPLAZAR = "desplazar"
+	ACCION_REDUCIR   = "reducir"
+	ACCION_ACEPTAR   = "aceptar"
+)
+
+// AccionLR es una entrada de la tabla ACCION. Numero es el estado destino de
+// un desplazamiento o la producción de una reducción.
+type AccionLR struct {
+	Tipo   string `json:"tipo"`
+	Numero int    `json:"numero,omitempty"`
+}
+
+// String muestra la acción en la notación de los libros: d5, r3 o acc
+func (a AccionLR) String() string {
+	switch a.Tipo {
+	case ACCION_DESPLAZAR:
+		return fmt.Sprintf("d%d", a.Numero)
+	case ACCION_REDUCIR:
+		return fmt.Sprintf("r%d", a.Numero)
+	}
+	return "acc"
+}
+
+// EstadoLR es un conjunto de ítems de la colección canónica. Los ítems del
+// núcleo van primero; en LALR(1) cada ítem muestra sus anticipaciones.
+type EstadoLR struct {
+	Numero       int            `json:"numero"`
+	Items        []string       `json:"items"`
+	Nucleo       int            `json:"nucleo"` // cantidad de ítems del núcleo
+	Transiciones map[string]int `json:"transiciones"`
+}
+
+// ConflictoLR es una celda de la tabla ACCION con más de una acción
+type ConflictoLR struct {
+	Estado   int        `json:"estado"`
+	Terminal string     `json:"terminal"`
+	Tipo     string     `json:"tipo"` // "desplazamiento/reducción" o "reducción/reducción"
+	Acciones []AccionLR `json:"acciones"`
+}
+
+// TablaLR son las tablas ACCION e IR_A de un analizador ascendente. La
+// gramática se aumenta con una producción S' → S que no forma parte de
+// Gramatica.Producciones; reducirla es la acción aceptar.
+type TablaLR struct {
+	Metodo       MetodoLR                `json:"metodo"`
+	Aumentada    Produccion              `json:"aumentada"`
+	Estados      []EstadoLR              `json:"estados"`
+	Terminales   []string                `json:"terminales"` // columnas de ACCION, con $ al final
+	NoTerminales []string                `json:"noTerminales"`
+	Accion       []map[string][]AccionLR `json:"accion"` // por estado y terminal
+	IrA          []map[string]int        `json:"irA"`    // por estado y no terminal
+	Conflictos   []ConflictoLR           `json:"conflictos"`
+	gramatica    *Gramatica
+}
+
+// SinConflictos indica si la gramática es del tipo del método de la tabla
+func (t *TablaLR) SinConflictos() bool {
+	return len(t.Conflictos) == 0
+}
+
+// item es una producción con un punto; la producción len(g.Producciones) es
+// la aumentada
+type item struct {
+	produccion int
+	punto      int
+}
+
+// estadoLR0 es un estado de la colección canónica LR(0)
+type estadoLR0 struct {
+	nucleo       []item
+	cierre       []item // núcleo seguido de los ítems agregados por el cierre
+	transiciones map[string]int
+	orden        []string // símbolos de las transiciones en orden de aparición
+}
+
+// automataLR es la colección canónica LR(0) de la gramática aumentada
+type automataLR struct {
+	g            *Gramatica
+	producciones []Produccion // las de la gramática más la aumentada
+	estados      []estadoLR0
+}
+
+// TablaLR construye la colección canónica LR(0) y las tablas ACCION e IR_A
+// con el método dado. Al analizar, los conflictos se resuelven como en yacc:
+// se prefiere desplazar y, entre reducciones, la primera producción.
+// Devuelve un error que envuelve ErrLimite si la colección supera
+// MaxEstadosLR estados o la tabla MaxCeldasLR celdas.
+func (g *Gramatica) TablaLR(metodo MetodoLR) (*TablaLR, error) {
+	a, err := g.automataLR()
+	if err != nil {
+		return nil, err
+	}
+	if celdas := len(a.estados) * (len(g.Terminales) + 1); celdas > MaxCeldasLR {
+		return nil, fmt.Errorf("%w: la tabla ACCION tendría %d celdas y el máximo es %d", ErrLimite, celdas, MaxCeldasLR)
+	}
+	aumentada := len(g.Producciones)
+	t := &TablaLR{
+		Metodo:       metodo,
+		Aumentada:    a.producciones[aumentada],
+		Terminales:   append(append([]string{}, g.Terminales...), FinEntrada),
+		NoTerminales: g.NoTerminales,
+		Accion:       make([]map[string][]AccionLR, len(a.estados)),
+		IrA:          make([]map[string]int, len(a.estados)),
+		Conflictos:   []ConflictoLR{},
+		gramatica:    g,
+	}
+
+	var anticipaciones [][]conjunto
+	if metodo == LALR1 {
+		anticipaciones = a.anticipacionesLALR()
+	}
+
+	for i, estado := range a.estados {
+		t.Accion[i] = map[string][]AccionLR{}
+		t.IrA[i] = map[string]int{}
+		agregar := func(terminal string, accion AccionLR) {
+			for _, existente := range t.Accion[i][terminal] {
+				if existente == accion {
+					return
+				}
+			}
+			t.Accion[i][terminal] = append(t.Accion[i][terminal], accion)
+		}
+
+		for _, simbolo := range estado.orden {
+			destino := estado.transiciones[simbolo]
+			if g.esNoTerminal[simbolo] {
+				t.IrA[i][simbolo] = destino
+			} else {
+				agregar(simbolo, AccionLR{Tipo: ACCION_DESPLAZAR, Numero: destino})
+			}
+		}
+
+		var completos map[item]conjunto
+		if metodo == LALR1 {
+			completos = a.completosLALR(i, anticipaciones[i])
+		}
+		for _, it := range estado.cierre {
+			p := a.producciones[it.produccion]
+			if it.punto < len(p.Derecha) {
+				continue
+			}
+			if it.produccion == aumentada {
+				agregar(FinEntrada, AccionLR{Tipo: ACCION_ACEPTAR})
+				continue
+			}
+			var terminales []string
+			switch metodo {
+			case LR0:
+				terminales = t.Terminales
+			case SLR:
+				terminales = g.siguientes[p.Izquierda].ordenado()
+			default:
+				terminales = completos[it].ordenado()
+			}
+			for _, terminal := range terminales {
+				agregar(terminal, AccionLR{Tipo: ACCION_REDUCIR, Numero: it.produccion})
+			}
+		}
+
+		t.Estados = append(t.Estados, a.mostrarEstado(i, completos))
+	}
+
+	for i := range t.Accion {
+		for _, terminal := range t.Terminales {
+			celda := t.Accion[i][terminal]
+			if len(celda) < 2 {
+				continue
+			}
+			tipo := "reducción/reducción"
+			for _, accion := range celda {
+				if accion.Tipo == ACCION_DESPLAZAR {
+					tipo = "desplazamiento/reducción"
+				}
+			}
+			t.Conflictos = append(t.Conflictos, ConflictoLR{Estado: i, Terminal: terminal, Tipo: tipo, Acciones: celda})
+		}
+	}
+	return t, nil
+}
+
+// automataLR construye la colección canónica de conjuntos de ítems LR(0)
+func (g *Gramatica) automataLR() (*automataLR, error) {
+	a := &automataLR{g: g, producciones: append([]Produccion{}, g.Producciones...)}
+	a.producciones = append(a.producciones, Produccion{Izquierda: g.nombreAumentado(), Derecha: []string{g.Inicial}})
+
+	porNucleo := map[string]int{}
+	nuevo := func(nucleo []item) int {
+		// El mismo núcleo puede alcanzarse con los ítems en otro orden
+		sort.Slice(nucleo, func(i, j int) bool {
+			if nucleo[i].produccion != nucleo[j].produccion {
+				return nucleo[i].produccion < nucleo[j].produccion
+			}
+			return nucleo[i].punto < nucleo[j].punto
+		})
+		clave := fmt.Sprint(nucleo)
+		if i, ok := porNucleo[clave]; ok {
+			return i
+		}
+		porNucleo[clave] = len(a.estados)
+		a.estados = append(a.estados, estadoLR0{nucleo: nucleo, cierre: a.cierre(nucleo), transiciones: map[string]int{}})
+		return len(a.estados) - 1
+	}
+	nuevo([]item{{produccion: len(g.Producciones)}})
+
+	for i := 0; i < len(a.estados); i++ {
+		// Agrupar los ítems por el símbolo que sigue al punto
+		var orden []string
+		avances := map[string][]item{}
+		for _, it := range a.estados[i].cierre {
+			derecha := a.producciones[it.produccion].Derecha
+			if it.punto == len(derecha) {
+				continue
+			}
+			simbolo := derecha[it.punto]
+			if _, ok := avances[simbolo]; !ok {
+				orden = append(orden, simbolo)
+			}
+			avances[simbolo] = append(avances[simbolo], item{it.produccion, it.punto + 1})
+		}
+		for _, simbolo := range orden {
+			destino := nuevo(avances[simbolo])
+			a.estados[i].transiciones[simbolo] = destino
+		}
+		a.estados[i].orden = orden
+		if len(a.estados) > MaxEstadosLR {
+			return nil, fmt.Errorf("%w: la colección LR(0) supera los %d estados", ErrLimite, MaxEstadosLR)
+		}
+	}
+	return a, nil
+}
+
+// cierre agrega al núcleo los ítems A → •γ de cada no terminal A que sigue
+// a un punto
+func (a *automataLR) cierre(nucleo []item) []item {
+	cierre := append([]item{}, nucleo...)
+	agregados := map[string]bool{}
+	for k := 0; k < len(cierre); k++ {
+		derecha := a.producciones[cierre[k].produccion].Derecha
+		if cierre[k].punto == len(derecha) {
+			continue
+		}
+		simbolo := derecha[cierre[k].punto]
+		if !a.g.esNoTerminal[simbolo] || agregados[simbolo] {
+			continue
+		}
+		agregados[simbolo] = true
+		for _, p := range a.g.porIzquierda[simbolo] {
+			cierre = append(cierre, item{produccion: p})
+		}
+	}
+	return cierre
+}
+
+// marcaPropagacion es la anticipación ficticia con la que se distinguen las
+// anticipaciones propagadas de las espontáneas. No puede aparecer en una
+// gramática leída con Cargar porque # comienza un comentario.
+const marcaPropagacion = "#"
+
+// itemLR1 es un ítem con un símbolo de anticipación
+type itemLR1 struct {
+	item
+	anticipacion string
+}
+
+// cierreLR1 agrega [B → •γ, b] para cada [A → α•Bβ, a] y cada b de PRIMERO(βa)
+func (a *automataLR) cierreLR1(items []itemLR1) []itemLR1 {
+	cierre := append([]itemLR1{}, items...)
+	vistos := map[itemLR1]bool{}
+	for _, it := range items {
+		vistos[it] = true
+	}
+	for k := 0; k < len(cierre); k++ {
+		it := cierre[k]
+		derecha := a.producciones[it.produccion].Derecha
+		if it.punto == len(derecha) || !a.g.esNoTerminal[derecha[it.punto]] {
+			continue
+		}
+		resto := append(append([]string{}, derecha[it.punto+1:]...), it.anticipacion)
+		for _, b := range a.g.primerosCadena(resto).ordenado() {
+			for _, p := range a.g.porIzquierda[derecha[it.punto]] {
+				nuevo := itemLR1{item{produccion: p}, b}
+				if !vistos[nuevo] {
+					vistos[nuevo] = true
+					cierre = append(cierre, nuevo)
+				}
+			}
+		}
+	}
+	return cierre
+}
+
+// anticipacionesLALR calcula las anticipaciones de los ítems del núcleo de
+// cada estado generándolas espontáneamente y propagándolas hasta un punto
+// fijo (algoritmo 4.63 del libro del dragón)
+func (a *automataLR) anticipacionesLALR() [][]conjunto {
+	type posicion struct{ estado, item int }
+	anticipaciones := make([][]conjunto, len(a.estados))
+	indice := make([]map[item]int, len(a.estados))
+	for i, estado := range a.estados {
+		anticipaciones[i] = make([]conjunto, len(estado.nucleo))
+		indice[i] = map[item]int{}
+		for k, it := range estado.nucleo {
+			anticipaciones[i][k] = conjunto{}
+			indice[i][it] = k
+		}
+	}
+	anticipaciones[0][0][FinEntrada] = true
+
+	propagaciones := map[posicion][]posicion{}
+	for i, estado := range a.estados {
+		for k, nucleo := range estado.nucleo {
+			for _, it := range a.cierreLR1([]itemLR1{{nucleo, marcaPropagacion}}) {
+				derecha := a.producciones[it.produccion].Derecha
+				if it.punto == len(derecha) {
+					continue
+				}
+				destino := estado.transiciones[derecha[it.punto]]
+				avanzado := indice[destino][item{it.produccion, it.punto + 1}]
+				if it.anticipacion == marcaPropagacion {
+					origen := posicion{i, k}
+					propagaciones[origen] = append(propagaciones[origen], posicion{destino, avanzado})
+				} else {
+					anticipaciones[destino][avanzado][it.anticipacion] = true
+				}
+			}
+		}
+	}
+
+	for cambio := true; cambio; {
+		cambio = false
+		for origen, destinos := range propagaciones {
+			for _, destino := range destinos {
+				if anticipaciones[destino.estado][destino.item].agregar(anticipaciones[origen.estado][origen.item], false) {
+					cambio = true
+				}
+			}
+		}
+	}
+	return anticipaciones
+}
+
+// completosLALR devuelve las anticipaciones de cada ítem del cierre del
+// estado a partir de las de su núcleo
+func (a *automataLR) completosLALR(estado int, nucleo []conjunto) map[item]conjunto {
+	var items []itemLR1
+	for k, it := range a.estados[estado].nucleo {
+		for _, s := range nucleo[k].ordenado() {
+			items = append(items, itemLR1{it, s})
+		}
+	}
+	resultado := map[item]conjunto{}
+	for _, it := range a.cierreLR1(items) {
+		if resultado[it.item] == nil {
+			resultado[it.item] = conjunto{}
+		}
+		resultado[it.item][it.anticipacion] = true
+	}
+	return resultado
+}
+
+// mostrarEstado describe un estado con sus ítems como "A → α • β", con las
+// anticipaciones de cada ítem si se dan
+func (a *automataLR) mostrarEstado(i int, completos map[item]conjunto) EstadoLR {
+	estado := a.estados[i]
+	items := make([]string, 0, len(estado.cierre))
+	for _, it := range estado.cierre {
+		p := a.producciones[it.produccion]
+		simbolos := append(append(append([]string{}, p.Derecha[:it.punto]...), "•"), p.Derecha[it.punto:]...)
+		texto := p.Izquierda + " → " + strings.Join(simbolos, " ")
+		if completos != nil {
+			texto = fmt.Sprintf("[%s, %s]", texto, strings.Join(completos[it].ordenado(), "/"))
+		}
+		items = append(items, texto)
+	}
+	return EstadoLR{Numero: i, Items: items, Nucleo: len(estado.nucleo), Transiciones: estado.transiciones}
+}
+
+// nombreAumentado devuelve el símbolo inicial de la gramática aumentada,
+// S' si no está en uso
+func (g *Gramatica) nombreAumentado() string {
+	nombre := g.Inicial + "'"
+	for g.esNoTerminal[nombre] || g.esTerminal(nombre) {
+		nombre += "'"
+	}
+	return nombre
+}
+
+func (g *Gramatica) esTerminal(simbolo string) bool {
+	for _, t := range g.Terminales {
+		if t == simbolo {
+			return true
+		}
+	}
+	return false
+}
+
+// PasoLR es un paso del análisis por desplazamiento y reducción
+type PasoLR struct {
+	Estados         []int    `json:"estados"`         // pila de estados hacia la cima, recortada a VentanaPila
+	Simbolos        []string `json:"simbolos"`        // símbolos entre los estados mostrados
+	ProfundidadPila int      `json:"profundidadPila"` // estados de la pila completa
+	Entrada         []string `json:"entrada"`         // símbolos restantes, recortados a VentanaEntrada
+	Accion          string   `json:"accion"`
+}
+
+// Analizar recorre los tokens con las tablas ACCION e IR_A y registra cada
+// desplazamiento y reducción. Se detiene en el primer error.
+func (t *TablaLR) Analizar(tokens []lexer.Token) *TrazaAnalisis[PasoLR] {
+	g := t.gramatica
+	entrada := g.simbolosEntrada(tokens)
+	traza := &TrazaAnalisis[PasoLR]{Pasos: []PasoLR{}}
+	estados := []int{0}
+	simbolos := []string{}
+	pos := 0
+
+	for len(traza.Pasos) < MaximoPasos {
+		paso := PasoLR{
+			Estados:         cimaPila(estados, VentanaPila),
+			Simbolos:        cimaPila(simbolos, VentanaPila-1),
+			ProfundidadPila: len(estados),
+			Entrada:         ventana(entrada[pos:]),
+		}
+		estado := estados[len(estados)-1]
+		actual := entrada[pos]
+		celda := t.Accion[estado][actual.simbolo]
+		if len(celda) == 0 {
+			paso.Accion = "error"
+			traza.Pasos = append(traza.Pasos, paso)
+			traza.fallar(actual, fmt.Sprintf("se encontró %s, se esperaba %s", actual.mostrar(), t.esperados(estado)))
+			return traza
+		}
+
+		accion := elegirAccion(celda)
+		switch accion.Tipo {
+		case ACCION_ACEPTAR:
+			paso.Accion = "aceptar"
+			traza.Pasos = append(traza.Pasos, paso)
+			traza.Aceptada = true
+			return traza
+
+		case ACCION_DESPLAZAR:
+			paso.Accion = fmt.Sprintf("desplazar %d", accion.Numero)
+			estados = append(estados, accion.Numero)
+			simbolos = append(simbolos, actual.simbolo)
+			pos++
+
+		case ACCION_REDUCIR:
+			p := g.Producciones[accion.Numero]
+			paso.Accion = "reducir " + p.String()
+			estados = estados[:len(estados)-len(p.Derecha)]
+			simbolos = append(simbolos[:len(simbolos)-len(p.Derecha)], p.Izquierda)
+			estados = append(estados, t.IrA[estados[len(estados)-1]][p.Izquierda])
+		}
+		traza.Pasos = append(traza.Pasos, paso)
+	}
+	traza.Error = fmt.Sprintf("se superó el máximo de %d pasos", MaximoPasos)
+	return traza
+}
+
+// elegirAccion resuelve un conflicto prefiriendo desplazar y, entre
+// reducciones, la de la primera producción
+func elegirAccion(celda []AccionLR) AccionLR {
+	elegida := celda[0]
+	for _, accion := range celda[1:] {
+		switch {
+		case elegida.Tipo == ACCION_DESPLAZAR:
+		case accion.Tipo != ACCION_REDUCIR:
+			elegida = accion
+		case accion.Numero < elegida.Numero:
+			elegida = accion
+		}
+	}
+	return elegida
+}
+
+// esperados lista los terminales con acción en el estado
+func (t *TablaLR) esperados(estado int) string {
+	var esperados []string
+	for _, terminal := range t.Terminales {
+		if len(t.Accion[estado][terminal]) > 0 {
+			esperados = append(esperados, terminal)
+		}
+	}
+	if len(esperados) == 1 {
+		return esperados[0]
+	}
+	return "uno de: " + strings.Join(esperados, ", ")
+}
+
+// String muestra una fila por estado con sus acciones y transiciones IR_A
+func (t *TablaLR) String() string {
+	var sb strings.Builder
+	for i := range t.Estados {
+		fmt.Fprintf(&sb, "%d", i)
+		for _, terminal := range t.Terminales {
+			for _, accion := range t.Accion[i][terminal] {
+				fmt.Fprintf(&sb, "  %s:%s", terminal, accion)
+			}
+		}
+		for _, nt := range t.NoTerminales {
+			if destino, ok := t.IrA[i][nt]; ok {
+				fmt.Fprintf(&sb, "  %s:%d", nt, destino)
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,475 @@
+package incremental
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"analyzer-api/internal/lexer"
+	"analyzer-api/internal/parser"
+	"analyzer-api/internal/semantic"
+)
+
+// Posicion es una posición en el texto del documento: línea y columna
+// comienzan en 1 y la columna se cuenta en bytes
+type Posicion struct {
+	Linea   int `json:"linea"`
+	Columna int `json:"columna"`
+}
+
+// Edicion reemplaza el texto entre Inicio (inclusive) y Fin (exclusivo) por Texto
+type Edicion struct {
+	Inicio Posicion `json:"inicio"`
+	Fin    Posicion `json:"fin"`
+	Texto  string   `json:"texto"`
+}
+
+// Diagnostico es un error o una advertencia del documento, los mismos que
+// informa el análisis completo del texto
+type Diagnostico struct {
+	Tipo    string // "lexico", "sintactico" o "semantico"
+	Mensaje string
+	Linea   int
+	Columna int
+	Codigo  string // solo en las advertencias, p. ej. "W001"
+}
+
+// Cambios resume el efecto de una edición
+type Cambios struct {
+	Version                   int
+	Agregados                 []Diagnostico // diagnósticos que aparecieron
+	Eliminados                []Diagnostico // diagnósticos que desaparecieron
+	TokensReanalizados        int
+	TokensReutilizados        int
+	DeclaracionesReanalizadas int
+	DeclaracionesReutilizadas int
+}
+
+// sentencia es una declaración de nivel superior con el rango de tokens
+// [desde, hasta) que consumió y los errores que produjo
+type sentencia struct {
+	decl    parser.Declaracion
+	desde   int
+	hasta   int
+	errores []parser.ErrorSintactico
+}
+
+// Documento es un programa analizado que puede editarse. Cada edición
+// reanaliza solo los tokens y las declaraciones afectados.
+type Documento struct {
+	ID       string
+	mu       sync.Mutex
+	version  int
+	texto    string
+	dialecto *lexer.Dialecto
+
+	tokens       []lexer.Token
+	sentencias   []sentencia
+	programa     *parser.Programa
+	semanticos   []semantic.ErrorSemantico
+	tabla        *semantic.TablaSimbolos
+	diagnosticos []Diagnostico
+}
+
+// Abrir analiza por completo el texto y crea un documento editable
+func Abrir(id, texto string, dialecto *lexer.Dialecto) (*Documento, error) {
+	if err := verificarTamano(len(texto)); err != nil {
+		return nil, err
+	}
+	d := &Documento{ID: id, texto: texto, dialecto: dialecto}
+	d.tokens = lexer.New(texto, lexer.ConDialecto(dialecto)).Analizar()
+	d.sentencias, _ = analizarSentencias(d.tokens, 0, nil)
+	d.analizarSemantica()
+	return d, nil
+}
+
+func verificarTamano(tamano int) error {
+	if tamano > MaxTamanoDocumento {
+		return fmt.Errorf("%w: %d bytes (máximo %d)", ErrLimite, tamano, MaxTamanoDocumento)
+	}
+	return nil
+}
+
+// Version devuelve el número de ediciones aplicadas
+func (d *Documento) Version() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.version
+}
+
+// Texto devuelve el texto actual del documento
+func (d *Documento) Texto() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.texto
+}
+
+// Tokens devuelve los tokens actuales del documento
+func (d *Documento) Tokens() []lexer.Token {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.tokens
+}
+
+// Programa devuelve el AST actual del documento
+func (d *Documento) Programa() *parser.Programa {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.programa
+}
+
+// Diagnosticos devuelve todos los diagnósticos actuales del documento
+func (d *Documento) Diagnosticos() []Diagnostico {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.diagnosticos
+}
+
+// TablaSimbolos devuelve la tabla de símbolos del último análisis
+func (d *Documento) TablaSimbolos() *semantic.TablaSimbolos {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.tabla
+}
+
+// Aplicar edita el documento y lo reanaliza de forma incremental. Los
+// diagnósticos que solo se desplazaron con el texto no se informan como
+// cambios.
+func (d *Documento) Aplicar(e Edicion) (*Cambios, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	inicio, err := offsetDe(d.texto, e.Inicio)
+	if err != nil {
+		return nil, err
+	}
+	fin, err := offsetDe(d.texto, e.Fin)
+	if err != nil {
+		return nil, err
+	}
+	if fin < inicio {
+		return nil, fmt.Errorf("el fin de la edición está antes de su inicio")
+	}
+
+	if err := verificarTamano(len(d.texto) - (fin - inicio) + len(e.Texto)); err != nil {
+		return nil, err
+	}
+	texto := d.texto[:inicio] + e.Texto + d.texto[fin:]
+	reanalisis := d.reanalizarTokens(texto, inicio, fin, len(e.Texto))
+	cambios := &Cambios{
+		TokensReanalizados: reanalisis.reanalizados,
+		TokensReutilizados: len(reanalisis.tokens) - reanalisis.reanalizados,
+	}
+
+	sentencias, reanalizadas := d.reanalizarSentencias(reanalisis)
+	cambios.DeclaracionesReanalizadas = reanalizadas
+	cambios.DeclaracionesReutilizadas = len(sentencias) - reanalizadas
+
+	anteriores := make([]Diagnostico, len(d.diagnosticos))
+	for i, diag := range d.diagnosticos {
+		anteriores[i] = diag
+		anteriores[i].Linea, anteriores[i].Columna = reanalisis.desplazar(diag.Linea, diag.Columna)
+	}
+
+	d.texto = texto
+	d.tokens = reanalisis.tokens
+	d.sentencias = sentencias
+	d.analizarSemantica()
+	d.version++
+
+	cambios.Version = d.version
+	cambios.Agregados, cambios.Eliminados = diferencia(anteriores, d.diagnosticos)
+	return cambios, nil
+}
+
+// reanalisisLexico describe cómo se relacionan los tokens nuevos con los
+// anteriores tras una edición
+type reanalisisLexico struct {
+	tokens       []lexer.Token
+	prefijo      int // tokens [0, prefijo) idénticos a los anteriores
+	reanalizados int
+	// Si sincronizado, los tokens anteriores desde viejoSinc aparecen
+	// desplazados en tokens desde nuevoSinc
+	sincronizado bool
+	viejoSinc    int
+	nuevoSinc    int
+	lineaSinc    int // posición del token de sincronización antes de editar
+	columnaSinc  int
+	offsetSinc   int
+	deltaLinea   int
+	deltaColumna int
+	deltaOffset  int
+}
+
+// desplazar traslada una posición anterior a la edición a su posición actual
+func (r *reanalisisLexico) desplazar(linea, columna int) (int, int) {
+	if !r.sincronizado || linea < r.lineaSinc || (linea == r.lineaSinc && columna < r.columnaSinc) {
+		return linea, columna
+	}
+	if linea == r.lineaSinc {
+		columna += r.deltaColumna
+	}
+	return linea + r.deltaLinea, columna
+}
+
+// desplazarPosicion traslada una posición del AST anterior a la edición
+func (r *reanalisisLexico) desplazarPosicion(p parser.Posicion) parser.Posicion {
+	if r.sincronizado && p.Offset >= r.offsetSinc {
+		p.Offset += r.deltaOffset
+	}
+	p.Linea, p.Columna = r.desplazar(p.Linea, p.Columna)
+	return p
+}
+
+// reanalizarTokens vuelve a reconocer los tokens desde el último token que
+// termina antes de la edición hasta que la salida coincide de nuevo con los
+// tokens anteriores, desplazados
+func (d *Documento) reanalizarTokens(texto string, inicio, fin, largo int) *reanalisisLexico {
+	delta := largo - (fin - inicio)
+	finNuevo := inicio + largo
+
+	// Primer token que termina en o después del inicio de la edición: puede
+	// cambiar aunque la edición solo lo toque por el borde
+	k := sort.Search(len(d.tokens), func(i int) bool {
+		return d.tokens[i].Offset+len(d.tokens[i].Lexeme) >= inicio
+	})
+
+	desde := 0
+	opciones := []lexer.Opcion{lexer.ConDialecto(d.dialecto)}
+	if k > 0 {
+		previo := d.tokens[k-1]
+		desde = previo.Offset + len(previo.Lexeme)
+		opciones = append(opciones, lexer.DesdePosicion(desde, previo.Line, previo.Column+len(previo.Lexeme)-1))
+	}
+
+	r := &reanalisisLexico{prefijo: k}
+	r.tokens = append(r.tokens, d.tokens[:k]...)
+
+	l := lexer.New(texto[desde:], opciones...)
+	j := k
+	for {
+		tok := l.NextToken()
+
+		if tok.Offset >= finNuevo {
+			// Buscar un token anterior, posterior a la edición, que comience
+			// en la misma posición desplazada
+			for j < len(d.tokens) && (d.tokens[j].Offset < fin || d.tokens[j].Offset+delta < tok.Offset) {
+				j++
+			}
+			if j < len(d.tokens) && d.tokens[j].Offset+delta == tok.Offset &&
+				d.tokens[j].Type == tok.Type && d.tokens[j].Lexeme == tok.Lexeme {
+				viejo := d.tokens[j]
+				r.sincronizado = true
+				r.viejoSinc = j
+				r.nuevoSinc = len(r.tokens)
+				r.lineaSinc = viejo.Line
+				r.columnaSinc = viejo.Column
+				r.offsetSinc = viejo.Offset
+				r.deltaOffset = delta
+				r.deltaLinea = tok.Line - viejo.Line
+				r.deltaColumna = tok.Column - viejo.Column
+				for _, t := range d.tokens[j:] {
+					t.Offset += delta
+					t.Line, t.Column = r.desplazar(t.Line, t.Column)
+					r.tokens = append(r.tokens, t)
+				}
+				return r
+			}
+		}
+
+		r.tokens = append(r.tokens, tok)
+		r.reanalizados++
+		if tok.Type == lexer.TOKEN_EOF {
+			return r
+		}
+	}
+}
+
+// reanalizarSentencias conserva las declaraciones anteriores a la edición,
+// analiza de nuevo las afectadas y reutiliza, desplazadas, las posteriores a
+// partir de la primera cuyo inicio coincide con una declaración anterior
+func (d *Documento) reanalizarSentencias(r *reanalisisLexico) ([]sentencia, int) {
+	var sentencias []sentencia
+
+	// Una declaración se conserva si todos sus tokens son anteriores al
+	// cambio, incluidos los dos siguientes: al terminar, el parser queda con
+	// el primero como token actual y el segundo como anticipación, y pudo
+	// consultar ambos
+	i := 0
+	for i < len(d.sentencias) && d.sentencias[i].hasta+1 < r.prefijo {
+		sentencias = append(sentencias, d.sentencias[i])
+		i++
+	}
+	pos := 0
+	if len(sentencias) > 0 {
+		pos = sentencias[len(sentencias)-1].hasta
+	}
+
+	var reutilizar func(int) []sentencia
+	if r.sincronizado {
+		inicios := map[int]int{}
+		for m, s := range d.sentencias {
+			if s.desde >= r.viejoSinc {
+				inicios[s.desde] = m
+			}
+		}
+		reutilizar = func(nuevo int) []sentencia {
+			if nuevo < r.nuevoSinc {
+				return nil
+			}
+			m, ok := inicios[nuevo-r.nuevoSinc+r.viejoSinc]
+			if !ok {
+				return nil
+			}
+			var resto []sentencia
+			for _, s := range d.sentencias[m:] {
+				s.desde += r.nuevoSinc - r.viejoSinc
+				s.hasta += r.nuevoSinc - r.viejoSinc
+				errores := make([]parser.ErrorSintactico, len(s.errores))
+				for e, err := range s.errores {
+					errores[e] = err
+					errores[e].Linea, errores[e].Columna = r.desplazar(err.Linea, err.Columna)
+				}
+				s.errores = errores
+				if s.decl != nil {
+					s.decl, _ = parser.Trasladar(s.decl, r.desplazarPosicion).(parser.Declaracion)
+				}
+				resto = append(resto, s)
+			}
+			return resto
+		}
+	}
+
+	nuevas, reanalizadas := analizarSentencias(r.tokens, pos, reutilizar)
+	return append(sentencias, nuevas...), reanalizadas
+}
+
+// analizarSentencias analiza las declaraciones de nivel superior a partir del
+// token desde. Antes de cada declaración consulta reutilizar, que puede
+// devolver las declaraciones restantes ya analizadas. Devuelve también
+// cuántas declaraciones se analizaron realmente.
+func analizarSentencias(tokens []lexer.Token, desde int, reutilizar func(int) []sentencia) ([]sentencia, int) {
+	var sentencias []sentencia
+	p := parser.New(lexer.DesdeTokens(tokens[desde:]))
+	pos := desde
+	reanalizadas := 0
+	for !p.Terminado() {
+		if reutilizar != nil {
+			if resto := reutilizar(pos); resto != nil {
+				return append(sentencias, resto...), reanalizadas
+			}
+		}
+
+		erroresPrevios := len(p.Errores())
+		consumidosPrevios := p.Consumidos()
+		decl := p.ParseDeclaracion()
+		consumidos := p.Consumidos() - consumidosPrevios
+
+		sentencias = append(sentencias, sentencia{
+			decl:    decl,
+			desde:   pos,
+			hasta:   pos + consumidos,
+			errores: append([]parser.ErrorSintactico(nil), p.Errores()[erroresPrevios:]...),
+		})
+		pos += consumidos
+		reanalizadas++
+	}
+	return sentencias, reanalizadas
+}
+
+// analizarSemantica reconstruye el programa a partir de las declaraciones,
+// ejecuta el análisis semántico y recalcula los diagnósticos en el orden del
+// análisis completo: errores léxicos, sintácticos y semánticos, y después
+// las advertencias
+func (d *Documento) analizarSemantica() {
+	d.programa = &parser.Programa{Declaraciones: []parser.Declaracion{}}
+	if n := len(d.tokens); n > 0 {
+		d.programa.Inicio = parser.Posicion{Offset: d.tokens[0].Offset, Linea: d.tokens[0].Line, Columna: d.tokens[0].Column}
+		d.programa.Fin = parser.Posicion{Offset: d.tokens[n-1].Offset, Linea: d.tokens[n-1].Line, Columna: d.tokens[n-1].Column}
+	}
+	d.diagnosticos = []Diagnostico{}
+	for _, err := range lexer.ErroresDe(d.tokens) {
+		d.diagnosticos = append(d.diagnosticos, Diagnostico{
+			Tipo: "lexico", Mensaje: err.Mensaje, Linea: err.Linea, Columna: err.Columna,
+		})
+	}
+	for _, s := range d.sentencias {
+		if s.decl != nil {
+			d.programa.Declaraciones = append(d.programa.Declaraciones, s.decl)
+		}
+		for _, err := range s.errores {
+			d.diagnosticos = append(d.diagnosticos, Diagnostico{
+				Tipo: "sintactico", Mensaje: err.Mensaje, Linea: err.Linea, Columna: err.Columna,
+			})
+		}
+	}
+
+	sem := semantic.New(d.programa)
+	d.semanticos = sem.Analizar()
+	d.tabla = sem.TablaSimbolos()
+	for _, err := range d.semanticos {
+		d.diagnosticos = append(d.diagnosticos, Diagnostico{
+			Tipo: "semantico", Mensaje: err.Mensaje, Linea: err.Linea, Columna: err.Columna,
+		})
+	}
+	for _, adv := range sem.Advertencias() {
+		d.diagnosticos = append(d.diagnosticos, Diagnostico{
+			Tipo: "semantico", Mensaje: adv.Mensaje, Linea: adv.Linea, Columna: adv.Columna, Codigo: adv.Codigo,
+		})
+	}
+}
+
+// diferencia compara dos listas de diagnósticos como multiconjuntos
+func diferencia(anteriores, actuales []Diagnostico) (agregados, eliminados []Diagnostico) {
+	pendientes := map[Diagnostico]int{}
+	for _, diag := range anteriores {
+		pendientes[diag]++
+	}
+	agregados, eliminados = []Diagnostico{}, []Diagnostico{}
+	for _, diag := range actuales {
+		if pendientes[diag] > 0 {
+			pendientes[diag]--
+		} else {
+			agregados = append(agregados, diag)
+		}
+	}
+	for _, diag := range anteriores {
+		if pendientes[diag] > 0 {
+			pendientes[diag]--
+			eliminados = append(eliminados, diag)
+		}
+	}
+	return agregados, eliminados
+}
+
+// offsetDe convierte una posición de línea y columna en un offset de bytes
+func offsetDe(texto string, pos Posicion) (int, error) {
+	if pos.Linea < 1 || pos.Columna < 1 {
+		return 0, fmt.Errorf("posición inválida %d:%d", pos.Linea, pos.Columna)
+	}
+	offset := 0
+	for linea := 1; linea < pos.Linea; linea++ {
+		salto := indiceDesde(texto, offset, '\n')
+		if salto < 0 {
+			return 0, fmt.Errorf("la línea %d no existe", pos.Linea)
+		}
+		offset = salto + 1
+	}
+	finLinea := indiceDesde(texto, offset, '\n')
+	if finLinea < 0 {
+		finLinea = len(texto)
+	}
+	if offset+pos.Columna-1 > finLinea {
+		return 0, fmt.Errorf("la columna %d no existe en la línea %d", pos.Columna, pos.Linea)
+	}
+	return offset + pos.Columna - 1, nil
+}
+
+func indiceDesde(texto string, desde int, b byte) int {
+	for i := desde; i < len(texto); i++ {
+		if texto[i] == b {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,185 @@
+package incremental
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"analyzer-api/internal/compilacion"
+)
+
+const programaInicial = `int x = 10;
+const int N = 3;
+int arr[3];
+int suma(int a, int b) {
+	int t = a + b;
+	return t;
+}
+/* ciclo
+   principal */
+while (x != 0) {
+	x = x - 1; // cuenta
+	arr[x % N] = suma(x, N);
+}
+print(x);
+`
+
+// fragmentos que las ediciones aleatorias insertan en el texto
+var fragmentos = []string{
+	"", " ", "\n", ";", "{", "}", "(", ")", "x", "y", "1", "int ", "int y = 2;",
+	" = ", " + N", "while (x) {", "if (x == 1) { print(x); }", "/*", "*/", "//",
+	"return 0;", "arr[", "]", "@", "ñ", "suma(1, 2)", "const int M = N * 2;\n", "x = x;",
+}
+
+func abrir(t *testing.T, texto string) *Documento {
+	t.Helper()
+	doc, err := Abrir("prueba", texto, nil)
+	if err != nil {
+		t.Fatalf("Abrir: %v", err)
+	}
+	return doc
+}
+
+// diagnosticosCompletos devuelve los diagnósticos que informa el análisis
+// completo del texto con una unidad de compilación
+func diagnosticosCompletos(texto string) []Diagnostico {
+	u := compilacion.Nueva(texto)
+	diagnosticos := []Diagnostico{}
+	for _, err := range u.ErroresLexicos() {
+		diagnosticos = append(diagnosticos, Diagnostico{Tipo: "lexico", Mensaje: err.Mensaje, Linea: err.Linea, Columna: err.Columna})
+	}
+	for _, err := range u.ErroresSintacticos() {
+		diagnosticos = append(diagnosticos, Diagnostico{Tipo: "sintactico", Mensaje: err.Mensaje, Linea: err.Linea, Columna: err.Columna})
+	}
+	for _, err := range u.ErroresSemanticos() {
+		diagnosticos = append(diagnosticos, Diagnostico{Tipo: "semantico", Mensaje: err.Mensaje, Linea: err.Linea, Columna: err.Columna})
+	}
+	for _, adv := range u.Advertencias() {
+		diagnosticos = append(diagnosticos, Diagnostico{Tipo: "semantico", Mensaje: adv.Mensaje, Linea: adv.Linea, Columna: adv.Columna, Codigo: adv.Codigo})
+	}
+	return diagnosticos
+}
+
+// posicionDe convierte un offset del texto en línea y columna
+func posicionDe(texto string, offset int) Posicion {
+	antes := texto[:offset]
+	linea := strings.Count(antes, "\n") + 1
+	return Posicion{Linea: linea, Columna: offset - strings.LastIndex(antes, "\n")}
+}
+
+// edicionAleatoria reemplaza un tramo corto del texto por un fragmento
+func edicionAleatoria(r *rand.Rand, texto string) Edicion {
+	inicio := r.Intn(len(texto) + 1)
+	fin := min(inicio+r.Intn(8), len(texto))
+	if r.Intn(3) == 0 {
+		fin = inicio
+	}
+	return Edicion{
+		Inicio: posicionDe(texto, inicio),
+		Fin:    posicionDe(texto, fin),
+		Texto:  fragmentos[r.Intn(len(fragmentos))],
+	}
+}
+
+// Después de cada edición el documento debe coincidir con el resultado de
+// analizar su texto desde cero
+func TestEdicionesAleatoriasComoAnalisisCompleto(t *testing.T) {
+	casos := []struct {
+		semilla   int64
+		ediciones int
+	}{
+		{1, 50}, {2, 50}, {3, 100}, {42, 100}, {2024, 200},
+	}
+	for _, c := range casos {
+		r := rand.New(rand.NewSource(c.semilla))
+		doc := abrir(t, programaInicial)
+		for i := 0; i < c.ediciones; i++ {
+			texto := doc.Texto()
+			e := edicionAleatoria(r, texto)
+			if _, err := doc.Aplicar(e); err != nil {
+				t.Fatalf("semilla %d, edición %d %+v: %v", c.semilla, i, e, err)
+			}
+
+			completo := abrir(t, doc.Texto())
+			if !reflect.DeepEqual(doc.Tokens(), completo.Tokens()) {
+				t.Fatalf("semilla %d, edición %d %+v: los tokens difieren del análisis completo\ntexto:\n%s",
+					c.semilla, i, e, doc.Texto())
+			}
+			if !reflect.DeepEqual(doc.Diagnosticos(), completo.Diagnosticos()) {
+				t.Fatalf("semilla %d, edición %d %+v: diagnósticos\n  %v\nse esperaban\n  %v\ntexto:\n%s",
+					c.semilla, i, e, doc.Diagnosticos(), completo.Diagnosticos(), doc.Texto())
+			}
+			if esperados := diagnosticosCompletos(doc.Texto()); !reflect.DeepEqual(doc.Diagnosticos(), esperados) {
+				t.Fatalf("semilla %d, edición %d %+v: diagnósticos\n  %v\nel análisis completo informa\n  %v\ntexto:\n%s",
+					c.semilla, i, e, doc.Diagnosticos(), esperados, doc.Texto())
+			}
+			if !reflect.DeepEqual(doc.Programa(), completo.Programa()) {
+				t.Fatalf("semilla %d, edición %d %+v: el AST difiere del análisis completo\ntexto:\n%s",
+					c.semilla, i, e, doc.Texto())
+			}
+		}
+		if doc.Version() != c.ediciones {
+			t.Errorf("semilla %d: versión %d, se esperaba %d", c.semilla, doc.Version(), c.ediciones)
+		}
+	}
+}
+
+func TestAplicarPosicionInvalida(t *testing.T) {
+	casos := []struct {
+		nombre string
+		e      Edicion
+	}{
+		{"línea cero", Edicion{Inicio: Posicion{0, 1}, Fin: Posicion{1, 1}}},
+		{"línea inexistente", Edicion{Inicio: Posicion{100, 1}, Fin: Posicion{100, 1}}},
+		{"columna inexistente", Edicion{Inicio: Posicion{1, 50}, Fin: Posicion{1, 50}}},
+		{"fin antes del inicio", Edicion{Inicio: Posicion{2, 1}, Fin: Posicion{1, 1}}},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			doc := abrir(t, programaInicial)
+			if _, err := doc.Aplicar(c.e); err == nil {
+				t.Errorf("Aplicar(%+v) no devolvió error", c.e)
+			}
+			if doc.Texto() != programaInicial || doc.Version() != 0 {
+				t.Errorf("una edición inválida modificó el documento")
+			}
+		})
+	}
+}
+
+func TestTamanoMaximo(t *testing.T) {
+	grande := strings.Repeat("x", MaxTamanoDocumento+1)
+	if _, err := Abrir("prueba", grande, nil); !errors.Is(err, ErrLimite) {
+		t.Errorf("Abrir con %d bytes: error %v, se esperaba ErrLimite", len(grande), err)
+	}
+
+	doc := abrir(t, programaInicial)
+	e := Edicion{Inicio: Posicion{1, 1}, Fin: Posicion{1, 1}, Texto: grande[len(programaInicial):]}
+	if _, err := doc.Aplicar(e); !errors.Is(err, ErrLimite) {
+		t.Errorf("Aplicar hasta %d bytes: error %v, se esperaba ErrLimite", len(programaInicial)+len(e.Texto), err)
+	}
+	if doc.Texto() != programaInicial || doc.Version() != 0 {
+		t.Errorf("una edición demasiado grande modificó el documento")
+	}
+}
+
+func TestDiagnosticosLexicosYAdvertencias(t *testing.T) {
+	doc := abrir(t, "int x = 1;\nx = x;\nint y = 2 @;\n")
+	var lexicos, advertencias int
+	for _, diag := range doc.Diagnosticos() {
+		if diag.Tipo == "lexico" {
+			lexicos++
+		}
+		if diag.Codigo != "" {
+			advertencias++
+		}
+	}
+	if lexicos == 0 || advertencias == 0 {
+		t.Errorf("diagnósticos %v: se esperaban errores léxicos y advertencias", doc.Diagnosticos())
+	}
+	if esperados := diagnosticosCompletos(doc.Texto()); !reflect.DeepEqual(doc.Diagnosticos(), esperados) {
+		t.Errorf("diagnósticos\n  %v\nel análisis completo informa\n  %v", doc.Diagnosticos(), esperados)
+	}
+}
@@ -0,0 +1,11 @@
+package incremental
+
+import "errors"
+
+// MaxTamanoDocumento limita el tamaño en bytes del texto de un documento.
+// Cada edición vuelve a ejecutar el análisis semántico del programa
+// completo, y los documentos quedan en memoria entre ediciones.
+const MaxTamanoDocumento = 256 << 10
+
+// ErrLimite indica que el documento supera el tamaño máximo
+var ErrLimite = errors.New("límite de tamaño superado")
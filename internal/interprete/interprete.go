@@ -0,0 +1,438 @@
+package interprete
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"analyzer-api/internal/parser"
+)
+
+// Límites de la ejecución, para que un programa no termine nunca o agote la
+// memoria del servidor. MaximoElementos limita cada arreglo y
+// MaximoElementosTotal la suma de las variables y los elementos de los
+// arreglos de todos los marcos a la vez, que si no podría llegar a
+// MaximaProfundidad arreglos de MaximoElementos. Como reservar y poner en
+// cero un arreglo cuesta un solo paso, MaximoElementosReservados limita
+// además los elementos reservados en toda la ejecución, aunque se liberen.
+const (
+	MaximoPasos               = 100000   // sentencias y condiciones evaluadas
+	MaximaProfundidad         = 1000     // llamadas anidadas
+	MaximoElementos           = 1 << 20  // elementos de un arreglo
+	MaximoElementosTotal      = 4 << 20  // variables y elementos vivos en todos los marcos
+	MaximoElementosReservados = 16 << 20 // elementos reservados en toda la ejecución
+)
+
+// Los enteros tienen 32 bits, como int en C
+const (
+	MinimoEntero = math.MinInt32
+	MaximoEntero = math.MaxInt32
+)
+
+// ErrorEjecucion es un error que detuvo la ejecución del programa
+type ErrorEjecucion struct {
+	Mensaje string
+	Linea   int
+	Columna int
+}
+
+func (e *ErrorEjecucion) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Linea, e.Columna, e.Mensaje)
+}
+
+// Variable es el valor de una variable o arreglo al terminar la ejecución
+type Variable struct {
+	Nombre  string
+	Valores []int64 // un solo valor si no es un arreglo
+	Arreglo bool
+}
+
+// variable guarda el valor de una variable o los elementos de un arreglo
+type variable struct {
+	valores []int64
+	arreglo bool
+}
+
+// marco es el registro de activación de una llamada: los parámetros y las
+// variables locales de la función
+type marco struct {
+	funcion   string
+	variables map[string]*variable
+	elementos int // elementos de sus variables, que se liberan al volver
+}
+
+// Interprete ejecuta un programa recorriendo su AST. Cada llamada apila un
+// marco con sus variables, así que las funciones pueden ser recursivas. El
+// programa debe haber pasado el análisis semántico.
+type Interprete struct {
+	programa  *parser.Programa
+	funciones map[string]*parser.DeclaracionFuncion
+	globales  *marco
+	pila      []*marco
+
+	entrada  []string // enteros de la entrada sin leer
+	leidos   int
+	salida   strings.Builder
+	pasos    int
+	maxPasos int
+	ctx      context.Context
+
+	elementos  int // elementos vivos en todos los marcos
+	reservados int // elementos reservados desde el comienzo
+}
+
+// Opcion configura un intérprete al crearlo
+type Opcion func(*Interprete)
+
+// ConEntrada hace que read tome los enteros del texto, separados por
+// espacios o saltos de línea
+func ConEntrada(texto string) Opcion {
+	return func(in *Interprete) {
+		in.entrada = strings.Fields(texto)
+	}
+}
+
+// ConMaximoPasos cambia el número de pasos tras el cual se detiene la
+// ejecución
+func ConMaximoPasos(n int) Opcion {
+	return func(in *Interprete) {
+		if n > 0 {
+			in.maxPasos = n
+		}
+	}
+}
+
+// ConContexto detiene la ejecución cuando el contexto se cancela o vence su
+// plazo
+func ConContexto(ctx context.Context) Opcion {
+	return func(in *Interprete) {
+		in.ctx = ctx
+	}
+}
+
+// New crea un intérprete para el programa
+func New(programa *parser.Programa, opciones ...Opcion) *Interprete {
+	in := &Interprete{
+		programa:  programa,
+		funciones: map[string]*parser.DeclaracionFuncion{},
+		globales:  &marco{variables: map[string]*variable{}},
+		maxPasos:  MaximoPasos,
+		ctx:       context.Background(),
+	}
+	for _, opcion := range opciones {
+		opcion(in)
+	}
+	return in
+}
+
+// resultadoRetorno indica que una sentencia return terminó la función actual
+// con el valor dado
+type resultadoRetorno struct {
+	valor int64
+}
+
+// Ejecutar ejecuta las declaraciones de nivel superior en orden. Devuelve el
+// error que detuvo la ejecución, o nil si terminó normalmente.
+func (in *Interprete) Ejecutar() (err *ErrorEjecucion) {
+	if in.programa == nil {
+		return nil
+	}
+	// Los errores de ejecución se propagan con panic desde donde ocurren
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*ErrorEjecucion)
+			if !ok {
+				panic(r)
+			}
+			err = e
+		}
+	}()
+
+	// Las funciones pueden llamarse antes de su definición
+	for _, decl := range in.programa.Declaraciones {
+		if funcion, ok := decl.(*parser.DeclaracionFuncion); ok {
+			in.funciones[funcion.Nombre] = funcion
+		}
+	}
+	for _, decl := range in.programa.Declaraciones {
+		if retorno := in.ejecutar(decl); retorno != nil {
+			in.fallar(decl, "'return' fuera de una función")
+		}
+	}
+	return nil
+}
+
+// Salida devuelve lo que el programa escribió con print
+func (in *Interprete) Salida() string {
+	return in.salida.String()
+}
+
+// Pasos devuelve el número de pasos ejecutados
+func (in *Interprete) Pasos() int {
+	return in.pasos
+}
+
+// Globales devuelve las variables de nivel superior con su valor actual,
+// ordenadas por nombre
+func (in *Interprete) Globales() []Variable {
+	nombres := make([]string, 0, len(in.globales.variables))
+	for nombre := range in.globales.variables {
+		nombres = append(nombres, nombre)
+	}
+	sort.Strings(nombres)
+	variables := make([]Variable, 0, len(nombres))
+	for _, nombre := range nombres {
+		v := in.globales.variables[nombre]
+		variables = append(variables, Variable{
+			Nombre:  nombre,
+			Valores: append([]int64{}, v.valores...),
+			Arreglo: v.arreglo,
+		})
+	}
+	return variables
+}
+
+// ejecutar ejecuta una declaración. Si fue un return, devuelve su valor.
+func (in *Interprete) ejecutar(decl parser.Declaracion) *resultadoRetorno {
+	in.paso(decl)
+	switch d := decl.(type) {
+	case *parser.DeclaracionVariable:
+		valor := in.evaluar(d.Valor)
+		in.reservar(d, in.actual(), 1)
+		in.definir(d.Nombre, &variable{valores: []int64{valor}})
+	case *parser.DeclaracionArreglo:
+		tamano, err := strconv.Atoi(d.Tamano.Valor)
+		if err != nil || tamano <= 0 || tamano > MaximoElementos {
+			in.fallar(d, fmt.Sprintf("Tamaño no válido para el arreglo '%s': %s (máximo %d)", d.Nombre, d.Tamano.Valor, MaximoElementos))
+		}
+		in.reservar(d, in.actual(), tamano)
+		// Los elementos sin valor inicial valen 0
+		valores := make([]int64, tamano)
+		for i, elemento := range d.Elementos {
+			if i < tamano {
+				valores[i] = in.evaluar(elemento)
+			}
+		}
+		in.definir(d.Nombre, &variable{valores: valores, arreglo: true})
+	case *parser.DeclaracionAsignacion:
+		a := d.Asignacion
+		in.guardar(a, a.Nombre, a.Indice, in.evaluar(a.Valor))
+	case *parser.DeclaracionDoWhile:
+		for {
+			for _, sentencia := range d.Cuerpo {
+				if retorno := in.ejecutar(sentencia); retorno != nil {
+					return retorno
+				}
+			}
+			in.paso(d.Condicion)
+			if in.evaluar(d.Condicion) == 0 {
+				break
+			}
+		}
+	case *parser.DeclaracionRetorno:
+		return &resultadoRetorno{valor: in.evaluar(d.Valor)}
+	case *parser.DeclaracionLlamada:
+		in.evaluar(d.Llamada)
+	case *parser.DeclaracionImpresion:
+		in.salida.WriteString(strconv.FormatInt(in.evaluar(d.Valor), 10))
+		in.salida.WriteString("\n")
+	case *parser.DeclaracionLectura:
+		in.guardar(d, d.Nombre, d.Indice, in.leer(d))
+	case *parser.DeclaracionFuncion:
+		// Registrada al comenzar
+	}
+	return nil
+}
+
+// evaluar calcula el valor de una expresión. Las comparaciones valen 1 si
+// se cumplen y 0 si no.
+func (in *Interprete) evaluar(expr parser.Expresion) int64 {
+	switch e := expr.(type) {
+	case *parser.ExpresionNumero:
+		valor, err := strconv.ParseInt(e.Valor, 10, 64)
+		if err != nil || valor > MaximoEntero {
+			in.fallar(e, fmt.Sprintf("El literal %s está fuera del rango de int", e.Valor))
+		}
+		return valor
+	case *parser.ExpresionIdentificador:
+		return in.variable(e, e.Valor).valores[0]
+	case *parser.ExpresionIndice:
+		v := in.variable(e, e.Nombre)
+		return v.valores[in.posicion(e, e.Nombre, v, e.Indice)]
+	case *parser.ExpresionBinaria:
+		izquierda, derecha := in.evaluar(e.Izquierda), in.evaluar(e.Derecha)
+		switch e.Operador {
+		case "+":
+			return in.verificarRango(e, izquierda+derecha)
+		case "*":
+			return in.verificarRango(e, izquierda*derecha)
+		case "/", "%":
+			if derecha == 0 {
+				in.fallar(e.Derecha, "División por cero")
+			}
+			if e.Operador == "/" {
+				return in.verificarRango(e, izquierda/derecha)
+			}
+			return izquierda % derecha
+		case "==":
+			if izquierda == derecha {
+				return 1
+			}
+			return 0
+		}
+		in.fallar(e, fmt.Sprintf("Operador desconocido '%s'", e.Operador))
+	case *parser.ExpresionLlamada:
+		return in.llamar(e)
+	}
+	in.fallar(expr, fmt.Sprintf("Expresión no soportada %T", expr))
+	return 0
+}
+
+// llamar evalúa los argumentos en el marco actual, apila un marco nuevo con
+// los parámetros y ejecuta el cuerpo de la función hasta su return
+func (in *Interprete) llamar(llamada *parser.ExpresionLlamada) int64 {
+	funcion, ok := in.funciones[llamada.Nombre]
+	if !ok {
+		in.fallar(llamada, fmt.Sprintf("Función '%s' no declarada", llamada.Nombre))
+	}
+	if len(llamada.Argumentos) != len(funcion.Parametros) {
+		in.fallar(llamada, fmt.Sprintf("La función '%s' espera %d argumentos, pero recibió %d",
+			llamada.Nombre, len(funcion.Parametros), len(llamada.Argumentos)))
+	}
+	if len(in.pila) >= MaximaProfundidad {
+		in.fallar(llamada, fmt.Sprintf("Desbordamiento de pila: más de %d llamadas anidadas", MaximaProfundidad))
+	}
+
+	m := &marco{funcion: funcion.Nombre, variables: map[string]*variable{}}
+	for i, argumento := range llamada.Argumentos {
+		valor := in.evaluar(argumento)
+		in.reservar(argumento, m, 1)
+		m.variables[funcion.Parametros[i].Nombre] = &variable{valores: []int64{valor}}
+	}
+
+	in.pila = append(in.pila, m)
+	defer func() {
+		in.pila = in.pila[:len(in.pila)-1]
+		in.elementos -= m.elementos
+	}()
+	for _, decl := range funcion.Cuerpo {
+		if retorno := in.ejecutar(decl); retorno != nil {
+			return retorno.valor
+		}
+	}
+	in.fallar(funcion, fmt.Sprintf("La función '%s' terminó sin devolver un valor", funcion.Nombre))
+	return 0
+}
+
+// actual devuelve el marco de la llamada en curso, o el global
+func (in *Interprete) actual() *marco {
+	if n := len(in.pila); n > 0 {
+		return in.pila[n-1]
+	}
+	return in.globales
+}
+
+// definir crea una variable en el marco actual. Si reemplaza a otra del
+// mismo nombre, como al repetir un ciclo con una declaración, libera sus
+// elementos.
+func (in *Interprete) definir(nombre string, v *variable) {
+	m := in.actual()
+	if anterior, ok := m.variables[nombre]; ok {
+		m.elementos -= len(anterior.valores)
+		in.elementos -= len(anterior.valores)
+	}
+	m.variables[nombre] = v
+}
+
+// reservar cuenta cantidad elementos nuevos del marco m y detiene la
+// ejecución si el programa supera MaximoElementosTotal o
+// MaximoElementosReservados
+func (in *Interprete) reservar(n parser.Nodo, m *marco, cantidad int) {
+	if in.elementos+cantidad > MaximoElementosTotal {
+		in.fallar(n, fmt.Sprintf("Memoria agotada: las variables y arreglos vivos superarían los %d elementos", MaximoElementosTotal))
+	}
+	if in.reservados+cantidad > MaximoElementosReservados {
+		in.fallar(n, fmt.Sprintf("Memoria agotada: la ejecución reservaría más de %d elementos en total", MaximoElementosReservados))
+	}
+	in.elementos += cantidad
+	in.reservados += cantidad
+	m.elementos += cantidad
+}
+
+// variable busca una variable en el marco actual y después en el global
+func (in *Interprete) variable(n parser.Nodo, nombre string) *variable {
+	if v, ok := in.actual().variables[nombre]; ok {
+		return v
+	}
+	if v, ok := in.globales.variables[nombre]; ok {
+		return v
+	}
+	in.fallar(n, fmt.Sprintf("Variable '%s' sin valor", nombre))
+	return nil
+}
+
+// guardar asigna un valor a una variable o a un elemento de un arreglo
+func (in *Interprete) guardar(n parser.Nodo, nombre string, indice parser.Expresion, valor int64) {
+	v := in.variable(n, nombre)
+	if indice == nil {
+		v.valores[0] = valor
+		return
+	}
+	v.valores[in.posicion(n, nombre, v, indice)] = valor
+}
+
+// posicion evalúa un índice y verifica que esté dentro del arreglo
+func (in *Interprete) posicion(n parser.Nodo, nombre string, v *variable, indice parser.Expresion) int {
+	i := in.evaluar(indice)
+	if i < 0 || i >= int64(len(v.valores)) {
+		in.fallar(indice, fmt.Sprintf("Índice %d fuera de rango para el arreglo '%s' de %d elementos", i, nombre, len(v.valores)))
+	}
+	return int(i)
+}
+
+// leer toma el siguiente entero de la entrada
+func (in *Interprete) leer(n parser.Nodo) int64 {
+	if in.leidos >= len(in.entrada) {
+		in.fallar(n, "No hay más datos en la entrada")
+	}
+	texto := in.entrada[in.leidos]
+	in.leidos++
+	valor, err := strconv.ParseInt(texto, 10, 64)
+	if err != nil {
+		in.fallar(n, fmt.Sprintf("Se esperaba un entero en la entrada, se encontró '%s'", texto))
+	}
+	return in.verificarRango(n, valor)
+}
+
+// verificarRango detiene la ejecución si el valor no entra en un int
+func (in *Interprete) verificarRango(n parser.Nodo, valor int64) int64 {
+	if valor < MinimoEntero || valor > MaximoEntero {
+		in.fallar(n, fmt.Sprintf("Desbordamiento: %d está fuera del rango de int", valor))
+	}
+	return valor
+}
+
+// paso cuenta un paso de ejecución y detiene el programa si supera el límite
+// o si su contexto terminó
+func (in *Interprete) paso(n parser.Nodo) {
+	in.pasos++
+	if in.pasos > in.maxPasos {
+		in.fallar(n, fmt.Sprintf("Se superó el límite de %d pasos (posible bucle infinito)", in.maxPasos))
+	}
+	switch in.ctx.Err() {
+	case nil:
+	case context.DeadlineExceeded:
+		in.fallar(n, "Se superó el tiempo máximo de ejecución")
+	default:
+		in.fallar(n, "Ejecución cancelada")
+	}
+}
+
+// fallar detiene la ejecución con un error en la posición del nodo
+func (in *Interprete) fallar(n parser.Nodo, mensaje string) {
+	inicio := n.Ubicacion().Inicio
+	panic(&ErrorEjecucion{Mensaje: mensaje, Linea: inicio.Linea, Columna: inicio.Columna})
+}
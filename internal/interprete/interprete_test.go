@@ -0,0 +1,138 @@
+package interprete
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"analyzer-api/internal/lexer"
+	"analyzer-api/internal/parser"
+)
+
+// ejecutar analiza el código y lo ejecuta con las opciones dadas
+func ejecutar(t *testing.T, codigo string, opciones ...Opcion) (*Interprete, *ErrorEjecucion) {
+	t.Helper()
+	p := parser.New(lexer.New(codigo))
+	programa := p.Parse()
+	if errores := p.Errores(); len(errores) > 0 {
+		t.Fatalf("errores de sintaxis: %v", errores)
+	}
+	in := New(programa, opciones...)
+	return in, in.Ejecutar()
+}
+
+func TestMemoriaEntreMarcos(t *testing.T) {
+	// Cada llamada declara un arreglo del tamaño máximo; sin un límite
+	// común a todos los marcos la recursión agotaría la memoria antes de
+	// llegar a MaximaProfundidad
+	codigo := "int f(int n) { int v[1048576]; v[0] = n; return f(n + 1); } int r = f(0);"
+	_, err := ejecutar(t, codigo)
+	if err == nil || !strings.HasPrefix(err.Mensaje, "Memoria agotada") {
+		t.Fatalf("error %v, se esperaba memoria agotada", err)
+	}
+	if err.Linea != 1 || err.Columna != 16 {
+		t.Errorf("error en %d:%d, se esperaba en la declaración del arreglo, 1:16", err.Linea, err.Columna)
+	}
+}
+
+func TestMemoriaSeLiberaAlVolver(t *testing.T) {
+	// Los arreglos de cada llamada se liberan al volver, así que ocho
+	// llamadas seguidas nunca tienen vivo más de uno
+	codigo := `int f(int n) { int v[1048576]; v[n] = n; return v[n]; }
+int i = 0;
+int s = 0;
+int fin = 0;
+do {
+	s = s + f(i);
+	i = i + 1;
+	fin = i / 8;
+} while (fin == 0);
+print(s);`
+	in, err := ejecutar(t, codigo)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if got := in.Salida(); got != "28\n" {
+		t.Errorf("salida %q, se esperaba \"28\\n\"", got)
+	}
+}
+
+func TestMemoriaReservadaEnTotal(t *testing.T) {
+	// Cada llamada reserva y libera un arreglo del tamaño máximo en un solo
+	// paso; sin un límite de lo reservado en total el ciclo pondría en cero
+	// gigabytes de memoria antes de llegar al límite de pasos
+	codigo := "int f() { int v[1048576]; return 0; } int x = 0; do { x = f(); } while (x == 0);"
+	in, err := ejecutar(t, codigo)
+	if err == nil || !strings.HasPrefix(err.Mensaje, "Memoria agotada") {
+		t.Fatalf("error %v, se esperaba memoria agotada", err)
+	}
+	if err.Linea != 1 || err.Columna != 11 {
+		t.Errorf("error en %d:%d, se esperaba en la declaración del arreglo, 1:11", err.Linea, err.Columna)
+	}
+	if in.Pasos() > 100 {
+		t.Errorf("%d pasos, se esperaba que se detuviera tras %d arreglos", in.Pasos(), MaximoElementosReservados/MaximoElementos)
+	}
+}
+
+func TestContextoCancelado(t *testing.T) {
+	ctx, cancelar := context.WithCancel(context.Background())
+	cancelar()
+	_, err := ejecutar(t, "int x = 0; do { x = 1; } while (x == 1);", ConContexto(ctx))
+	if err == nil || err.Mensaje != "Ejecución cancelada" {
+		t.Fatalf("error %v, se esperaba la ejecución cancelada", err)
+	}
+}
+
+func TestEjecutar(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		codigo  string
+		entrada string
+		salida  string
+	}{
+		{"aritmética", "int a = 7; int b = a * 3; print(b / 2); print(b % 4);", "", "10\n1\n"},
+		{"arreglos", "int v[3] = {4, 5}; v[2] = v[0] + v[1]; print(v[2]); print(v[1]);", "", "9\n5\n"},
+		{"lectura", "int x = 0; read(x); int v[2]; read(v[1]); print(x + v[1]);", "20 22", "42\n"},
+		{"funciones antes de definirse", "print(doble(21)); int doble(int n) { return n * 2; }", "", "42\n"},
+		{"globales desde funciones", "int g = 1; int inc() { g = g + 1; return g; } int a = inc(); print(inc());", "", "3\n"},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			in, err := ejecutar(t, c.codigo, ConEntrada(c.entrada))
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if got := in.Salida(); got != c.salida {
+				t.Errorf("salida %q, se esperaba %q", got, c.salida)
+			}
+		})
+	}
+}
+
+func TestErroresEjecucion(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		codigo  string
+		mensaje string
+		columna int // 0 si no importa
+		pasos   int // límite de pasos; 0 para el predeterminado
+	}{
+		{"división por cero", "int a = 0; int b = 1 / a;", "División por cero", 24, 0},
+		{"índice fuera de rango", "int v[2]; v[2] = 1;", "Índice 2 fuera de rango para el arreglo 'v' de 2 elementos", 13, 0},
+		{"desbordamiento", "int a = 2147483647; int b = a + 1;", "Desbordamiento: 2147483648 está fuera del rango de int", 29, 0},
+		{"sin entrada", "int x = 0; read(x);", "No hay más datos en la entrada", 12, 0},
+		{"recursión infinita", "int f(int n) { return f(n); } int r = f(0);", "Desbordamiento de pila: más de 1000 llamadas anidadas", 23, 0},
+		{"bucle infinito", "int x = 0; do { x = 1; } while (x == 1);", "Se superó el límite de 100 pasos (posible bucle infinito)", 0, 100},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			_, err := ejecutar(t, c.codigo, ConMaximoPasos(c.pasos))
+			if err == nil {
+				t.Fatalf("la ejecución terminó sin error, se esperaba %q", c.mensaje)
+			}
+			if err.Mensaje != c.mensaje || (c.columna != 0 && err.Columna != c.columna) {
+				t.Errorf("error %q en la columna %d, se esperaba %q en la columna %d", err.Mensaje, err.Columna, c.mensaje, c.columna)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package lexer
+
+import "strings"
+
+// Comentario es un comentario del código fuente (// hasta el fin de línea o
+// /* ... */). No llega al parser como token, pero el analizador léxico lo
+// conserva como trivia para herramientas como el formateador.
+type Comentario struct {
+	Texto   string // incluye los delimitadores
+	Bloque  bool   // /* ... */ en lugar de // ...
+	Linea   int
+	Columna int
+	Offset  int
+}
+
+// LineaFinal devuelve la línea donde termina el comentario
+func (c Comentario) LineaFinal() int {
+	return c.Linea + strings.Count(c.Texto, "\n")
+}
+
+// esInicioComentario indica si en el caracter actual comienza un comentario
+func (l *Lexer) esInicioComentario() bool {
+	if l.ch != '/' {
+		return false
+	}
+	siguiente := l.peekChar()
+	return siguiente == '/' || siguiente == '*'
+}
+
+// readComment lee el comentario que comienza en el caracter actual y deja
+// como actual el caracter siguiente. Devuelve false si un comentario de
+// bloque no se cerró antes del final de la entrada.
+func (l *Lexer) readComment() (Comentario, bool) {
+	c := Comentario{Linea: l.line, Columna: l.column, Offset: l.position}
+	var sb strings.Builder
+	sb.WriteByte(l.ch)
+	l.readChar()
+	c.Bloque = l.ch == '*'
+	sb.WriteByte(l.ch)
+	l.readChar()
+
+	cerrado := true
+	if c.Bloque {
+		cerrado = false
+		for l.ch != 0 {
+			sb.WriteByte(l.ch)
+			if l.ch == '*' && l.peekChar() == '/' {
+				l.readChar()
+				sb.WriteByte(l.ch)
+				l.readChar()
+				cerrado = true
+				break
+			}
+			l.readChar()
+		}
+	} else {
+		for l.ch != '\n' && l.ch != 0 {
+			sb.WriteByte(l.ch)
+			l.readChar()
+		}
+	}
+	c.Texto = sb.String()
+	if !c.Bloque {
+		c.Texto = strings.TrimRight(c.Texto, "\r")
+	}
+	return c, cerrado
+}
+
+// Comentarios devuelve los comentarios encontrados hasta ahora, en orden.
+// Como los tokens, solo se conservan si el analizador se creó con New.
+func (l *Lexer) Comentarios() []Comentario {
+	return l.comentarios
+}
@@ -0,0 +1,327 @@
+package lexer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Patron describe los caracteres válidos al inicio y en el resto de un
+// identificador o número, cada uno como una clase de caracteres de expresión
+// regular (por ejemplo "[A-Za-z_]"). El analizador léxico avanza de a un
+// byte, así que las clases solo pueden contener caracteres ASCII.
+type Patron struct {
+	Inicio string `json:"inicio"`
+	Resto  string `json:"resto"`
+}
+
+// EspecificacionDialecto es la definición declarativa de un dialecto tal como
+// se escribe en un archivo JSON. Los campos omitidos toman el valor del
+// dialecto base.
+type EspecificacionDialecto struct {
+	Nombre             string               `json:"nombre"`
+	PalabrasReservadas map[string]TokenType `json:"palabrasReservadas"`
+	Operadores         map[string]TokenType `json:"operadores"`
+	Identificador      Patron               `json:"identificador"`
+	Numero             Patron               `json:"numero"`
+	Categorias         map[string]string    `json:"categorias"`
+}
+
+// Dialecto es una definición de lenguaje compilada y lista para configurar
+// el analizador léxico
+type Dialecto struct {
+	Nombre      string
+	reservadas  map[string]TokenType
+	operadores  []string // ordenados del más largo al más corto
+	tiposOp     map[string]TokenType
+	inicioIdent [256]bool
+	restoIdent  [256]bool
+	inicioNum   [256]bool
+	restoNum    [256]bool
+	categorias  map[string]string
+}
+
+// especificacionBase describe el lenguaje original del analizador
+var especificacionBase = EspecificacionDialecto{
+	Nombre:             "base",
+	PalabrasReservadas: MapaReservadas,
+	Operadores:         MapaOperadores,
+	Identificador:      Patron{Inicio: "[A-Za-z_]", Resto: "[A-Za-z0-9_]"},
+	Numero:             Patron{Inicio: "[0-9]", Resto: "[0-9]"},
+	Categorias:         map[string]string{},
+}
+
+var dialectoBase = mustDialecto(especificacionBase)
+
+// DialectoBase devuelve el dialecto por defecto (int, do, while, return, print, read, const)
+func DialectoBase() *Dialecto {
+	return dialectoBase
+}
+
+// EspecificacionBase devuelve una copia de la especificación del dialecto
+// base, útil como plantilla para escribir nuevos dialectos
+func EspecificacionBase() EspecificacionDialecto {
+	spec := especificacionBase
+	spec.PalabrasReservadas = copiarMapa(especificacionBase.PalabrasReservadas)
+	spec.Operadores = copiarMapa(especificacionBase.Operadores)
+	spec.Categorias = copiarMapa(especificacionBase.Categorias)
+	return spec
+}
+
+// NuevoDialecto valida una especificación y la compila en un Dialecto
+func NuevoDialecto(spec EspecificacionDialecto) (*Dialecto, error) {
+	if spec.Nombre == "" {
+		return nil, fmt.Errorf("el dialecto no tiene nombre")
+	}
+	if spec.PalabrasReservadas == nil {
+		spec.PalabrasReservadas = especificacionBase.PalabrasReservadas
+	}
+	if spec.Operadores == nil {
+		spec.Operadores = especificacionBase.Operadores
+	}
+	if spec.Identificador == (Patron{}) {
+		spec.Identificador = especificacionBase.Identificador
+	}
+	if spec.Numero == (Patron{}) {
+		spec.Numero = especificacionBase.Numero
+	}
+
+	d := &Dialecto{
+		Nombre:     spec.Nombre,
+		reservadas: map[string]TokenType{},
+		tiposOp:    map[string]TokenType{},
+		categorias: map[string]string{},
+	}
+
+	var err error
+	if d.inicioIdent, err = compilarClase(spec.Identificador.Inicio); err != nil {
+		return nil, fmt.Errorf("dialecto %s: identificador: %w", spec.Nombre, err)
+	}
+	if d.restoIdent, err = compilarClase(spec.Identificador.Resto); err != nil {
+		return nil, fmt.Errorf("dialecto %s: identificador: %w", spec.Nombre, err)
+	}
+	if d.inicioNum, err = compilarClase(spec.Numero.Inicio); err != nil {
+		return nil, fmt.Errorf("dialecto %s: número: %w", spec.Nombre, err)
+	}
+	if d.restoNum, err = compilarClase(spec.Numero.Resto); err != nil {
+		return nil, fmt.Errorf("dialecto %s: número: %w", spec.Nombre, err)
+	}
+
+	for lexema, tipo := range spec.PalabrasReservadas {
+		if categoriasTipo[tipo] != CATEGORIA_PR {
+			return nil, fmt.Errorf("dialecto %s: '%s' no es un tipo de palabra reservada", spec.Nombre, tipo)
+		}
+		if !d.esIdentificador(lexema) {
+			return nil, fmt.Errorf("dialecto %s: la palabra reservada '%s' no es un identificador válido", spec.Nombre, lexema)
+		}
+		d.reservadas[lexema] = tipo
+	}
+
+	for lexema, tipo := range spec.Operadores {
+		if categoriasTipo[tipo] != CATEGORIA_SIMBOLOS {
+			return nil, fmt.Errorf("dialecto %s: '%s' no es un tipo de operador", spec.Nombre, tipo)
+		}
+		if lexema == "" || d.inicioIdent[lexema[0]] || d.inicioNum[lexema[0]] || esEspacio(lexema[0]) {
+			return nil, fmt.Errorf("dialecto %s: el operador '%s' no puede comenzar como identificador, número o espacio", spec.Nombre, lexema)
+		}
+		if strings.HasPrefix(lexema, "//") || strings.HasPrefix(lexema, "/*") {
+			return nil, fmt.Errorf("dialecto %s: el operador '%s' no puede comenzar como un comentario", spec.Nombre, lexema)
+		}
+		d.tiposOp[lexema] = tipo
+		d.operadores = append(d.operadores, lexema)
+	}
+	sort.Slice(d.operadores, func(i, j int) bool {
+		if len(d.operadores[i]) != len(d.operadores[j]) {
+			return len(d.operadores[i]) > len(d.operadores[j])
+		}
+		return d.operadores[i] < d.operadores[j]
+	})
+
+	for canonica, nombre := range spec.Categorias {
+		if !esCategoria(canonica) {
+			return nil, fmt.Errorf("dialecto %s: categoría desconocida '%s'", spec.Nombre, canonica)
+		}
+		d.categorias[canonica] = nombre
+	}
+
+	return d, nil
+}
+
+// CargarDialecto lee una especificación JSON y la compila
+func CargarDialecto(r io.Reader) (*Dialecto, error) {
+	var spec EspecificacionDialecto
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("especificación de dialecto inválida: %w", err)
+	}
+	return NuevoDialecto(spec)
+}
+
+// CargarDialectoArchivo lee la especificación de un dialecto desde un archivo
+func CargarDialectoArchivo(ruta string) (*Dialecto, error) {
+	f, err := os.Open(ruta)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	d, err := CargarDialecto(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ruta, err)
+	}
+	return d, nil
+}
+
+// CargarDialectosDirectorio carga todos los archivos *.json de dir como
+// dialectos, indexados por nombre. Siempre incluye el dialecto base.
+func CargarDialectosDirectorio(dir string) (map[string]*Dialecto, error) {
+	dialectos := map[string]*Dialecto{dialectoBase.Nombre: dialectoBase}
+
+	rutas, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, ruta := range rutas {
+		d, err := CargarDialectoArchivo(ruta)
+		if err != nil {
+			return nil, err
+		}
+		if _, existe := dialectos[d.Nombre]; existe {
+			return nil, fmt.Errorf("%s: dialecto '%s' duplicado", ruta, d.Nombre)
+		}
+		dialectos[d.Nombre] = d
+	}
+	return dialectos, nil
+}
+
+// NombreCategoria devuelve el nombre que el dialecto da a una categoría canónica
+func (d *Dialecto) NombreCategoria(canonica string) string {
+	if nombre, ok := d.categorias[canonica]; ok {
+		return nombre
+	}
+	return canonica
+}
+
+// PalabrasReservadas devuelve las palabras reservadas del dialecto
+func (d *Dialecto) PalabrasReservadas() map[string]TokenType {
+	return copiarMapa(d.reservadas)
+}
+
+// Operadores devuelve los operadores y símbolos del dialecto
+func (d *Dialecto) Operadores() map[string]TokenType {
+	return copiarMapa(d.tiposOp)
+}
+
+// Lexema devuelve la forma escrita de una palabra reservada u operador del
+// dialecto. Si varias formas tienen el mismo tipo elige la más corta.
+func (d *Dialecto) Lexema(tipo TokenType) (string, bool) {
+	mejor := ""
+	for _, m := range []map[string]TokenType{d.reservadas, d.tiposOp} {
+		for lexema, t := range m {
+			if t == tipo && (mejor == "" || len(lexema) < len(mejor) || (len(lexema) == len(mejor) && lexema < mejor)) {
+				mejor = lexema
+			}
+		}
+	}
+	return mejor, mejor != ""
+}
+
+// esIdentificador indica si el lexema completo se reconoce como un
+// identificador del dialecto
+func (d *Dialecto) esIdentificador(lexema string) bool {
+	if lexema == "" || !d.inicioIdent[lexema[0]] {
+		return false
+	}
+	for i := 1; i < len(lexema); i++ {
+		if !d.restoIdent[lexema[i]] {
+			return false
+		}
+	}
+	return true
+}
+
+// compilarClase convierte una clase de caracteres en una tabla de bytes
+// válidos. Rechaza las clases con caracteres fuera de ASCII, que en UTF-8
+// ocupan más de un byte y nunca coincidirían.
+func compilarClase(clase string) ([256]bool, error) {
+	var tabla [256]bool
+	re, err := regexp.Compile("^(?:" + clase + ")$")
+	if err != nil {
+		return tabla, fmt.Errorf("patrón '%s' inválido: %w", clase, err)
+	}
+	arbol, err := syntax.Parse(clase, syntax.Perl)
+	if err != nil {
+		return tabla, fmt.Errorf("patrón '%s' inválido: %w", clase, err)
+	}
+	if !soloASCII(arbol) {
+		return tabla, fmt.Errorf("el patrón '%s' acepta caracteres que no son ASCII", clase)
+	}
+	validos := 0
+	for b := 1; b < 256; b++ {
+		if re.Match([]byte{byte(b)}) {
+			tabla[b] = true
+			validos++
+		}
+	}
+	if validos == 0 {
+		return tabla, fmt.Errorf("el patrón '%s' no acepta ningún caracter", clase)
+	}
+	return tabla, nil
+}
+
+// soloASCII indica si la expresión reconoce únicamente caracteres ASCII
+func soloASCII(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return false
+	case syntax.OpLiteral, syntax.OpCharClass:
+		for _, r := range re.Rune {
+			if r > unicode.MaxASCII {
+				return false
+			}
+		}
+	}
+	for _, sub := range re.Sub {
+		if !soloASCII(sub) {
+			return false
+		}
+	}
+	return true
+}
+
+func esCategoria(nombre string) bool {
+	for _, categoria := range categoriasTipo {
+		if categoria == nombre {
+			return true
+		}
+	}
+	return false
+}
+
+func esEspacio(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
+
+func copiarMapa[K comparable, V any](m map[K]V) map[K]V {
+	copia := make(map[K]V, len(m))
+	for k, v := range m {
+		copia[k] = v
+	}
+	return copia
+}
+
+func mustDialecto(spec EspecificacionDialecto) *Dialecto {
+	d, err := NuevoDialecto(spec)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
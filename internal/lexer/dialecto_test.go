@@ -0,0 +1,81 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNuevoDialectoRechaza(t *testing.T) {
+	casos := []struct {
+		nombre string
+		cambio func(*EspecificacionDialecto)
+		error  string
+	}{
+		{"sin nombre", func(s *EspecificacionDialecto) { s.Nombre = "" }, "no tiene nombre"},
+		{"patrón inválido", func(s *EspecificacionDialecto) { s.Identificador.Inicio = "[a-" }, "inválido"},
+		{"patrón vacío", func(s *EspecificacionDialecto) { s.Numero.Resto = "x{0}" }, "no acepta ningún caracter"},
+		{"clase no ASCII", func(s *EspecificacionDialecto) { s.Identificador.Resto = "[a-zñ]" }, "no son ASCII"},
+		{"clase negada", func(s *EspecificacionDialecto) { s.Identificador.Inicio = "[^0-9]" }, "no son ASCII"},
+		{"punto", func(s *EspecificacionDialecto) { s.Identificador.Resto = "." }, "no son ASCII"},
+		{"tipo de palabra reservada", func(s *EspecificacionDialecto) { s.PalabrasReservadas["mas"] = TOKEN_PLUS }, "no es un tipo de palabra reservada"},
+		{"palabra reservada no identificador", func(s *EspecificacionDialecto) { s.PalabrasReservadas["9int"] = TOKEN_INT }, "no es un identificador válido"},
+		{"palabra reservada con guion", func(s *EspecificacionDialecto) { s.PalabrasReservadas["mien-tras"] = TOKEN_WHILE }, "no es un identificador válido"},
+		{"palabra reservada con ñ", func(s *EspecificacionDialecto) { s.PalabrasReservadas["año"] = TOKEN_INT }, "no es un identificador válido"},
+		{"tipo de operador", func(s *EspecificacionDialecto) { s.Operadores["=>"] = TOKEN_INT }, "no es un tipo de operador"},
+		{"operador como identificador", func(s *EspecificacionDialecto) { s.Operadores["mas"] = TOKEN_PLUS }, "no puede comenzar como identificador"},
+		{"operador como comentario", func(s *EspecificacionDialecto) { s.Operadores["//"] = TOKEN_DIV }, "no puede comenzar como un comentario"},
+		{"categoría desconocida", func(s *EspecificacionDialecto) { s.Categorias["Otra"] = "x" }, "categoría desconocida"},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			spec := EspecificacionBase()
+			spec.Nombre = "prueba"
+			c.cambio(&spec)
+			_, err := NuevoDialecto(spec)
+			if err == nil || !strings.Contains(err.Error(), c.error) {
+				t.Errorf("NuevoDialecto: error %v, se esperaba uno con %q", err, c.error)
+			}
+		})
+	}
+}
+
+func TestCargarDialectoEspanol(t *testing.T) {
+	d, err := CargarDialectoArchivo("../../dialectos/espanol.json")
+	if err != nil {
+		t.Fatalf("CargarDialectoArchivo: %v", err)
+	}
+
+	casos := []struct {
+		entrada string
+		tokens  string // TIPO/categoría por token, sin EOF
+	}{
+		{"entero x = 1;", "INT/PalabraReservada IDENT/Identificador ASSIGN/Simbolo NUMBER/Numero SEMI/Simbolo"},
+		{"int enteros", "IDENT/Identificador IDENT/Identificador"},
+		{"hacer { } mientras (x == 1);", "DO/PalabraReservada LBRACE/Simbolo RBRACE/Simbolo WHILE/PalabraReservada LPAREN/Simbolo IDENT/Identificador EQUAL/Simbolo NUMBER/Numero RPAREN/Simbolo SEMI/Simbolo"},
+		{"x @", "IDENT/Identificador ERROR/Error"},
+	}
+	for _, c := range casos {
+		t.Run(c.entrada, func(t *testing.T) {
+			var tokens []string
+			for _, tok := range New(c.entrada, ConDialecto(d)).Analizar() {
+				if tok.Type != TOKEN_EOF {
+					tokens = append(tokens, string(tok.Type)+"/"+tok.Categoria())
+				}
+			}
+			if got := strings.Join(tokens, " "); got != c.tokens {
+				t.Errorf("tokens %q, se esperaban %q", got, c.tokens)
+			}
+		})
+	}
+
+	if lexema, ok := d.Lexema(TOKEN_WHILE); !ok || lexema != "mientras" {
+		t.Errorf("Lexema(WHILE) = %q, %v", lexema, ok)
+	}
+}
+
+func TestCargarDialectoCamposDesconocidos(t *testing.T) {
+	_, err := CargarDialecto(strings.NewReader(`{"nombre": "x", "palabras": {}}`))
+	if err == nil {
+		t.Errorf("CargarDialecto aceptó un campo desconocido")
+	}
+}
@@ -0,0 +1,32 @@
+package lexer
+
+// Fuente es cualquier productor de tokens que puede alimentar al analizador
+// sintáctico. Una vez agotada la entrada debe devolver siempre TOKEN_EOF.
+type Fuente interface {
+	NextToken() Token
+}
+
+// fuenteTokens entrega tokens ya reconocidos desde un slice
+type fuenteTokens struct {
+	tokens []Token
+	pos    int
+}
+
+// DesdeTokens devuelve una Fuente que entrega los tokens dados y después
+// TOKEN_EOF indefinidamente
+func DesdeTokens(tokens []Token) Fuente {
+	return &fuenteTokens{tokens: tokens}
+}
+
+func (f *fuenteTokens) NextToken() Token {
+	if f.pos < len(f.tokens) {
+		tok := f.tokens[f.pos]
+		f.pos++
+		return tok
+	}
+	if n := len(f.tokens); n > 0 {
+		ultimo := f.tokens[n-1]
+		return Token{Type: TOKEN_EOF, Line: ultimo.Line, Column: ultimo.Column, Offset: ultimo.Offset + len(ultimo.Lexeme)}
+	}
+	return Token{Type: TOKEN_EOF, Line: 1}
+}
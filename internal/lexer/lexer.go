@@ -1,183 +1,352 @@
 package lexer
 import (
-	"fmt"
+	"bufio"
+	"io"
+	"iter"
+	"strings"
+	"unicode/utf8"
+
+	"analyzer-api/internal/traza"
 )
 
+// TamanoBuffer es el tamaño en bytes del buffer de lectura del analizador
+const TamanoBuffer = 4096
+
 // Lexer estructura para el analizador léxico
 type Lexer struct {
-	input        string
+	reader       *bufio.Reader // fuente del programa
 	position     int  // posición actual en el input
 	readPosition int  // siguiente posición a leer
 	ch           byte // caracter actual
 	line         int  // línea actual
 	column       int  // columna actual
 	tokens       []Token // tokens encontrados
+	comentarios  []Comentario // comentarios encontrados, si registrar
+	registrar    bool    // conservar en tokens cada token emitido
+	terminado    bool    // ya se emitió el token EOF
+	err          error   // error de lectura distinto de io.EOF
+	dialecto     *Dialecto // definición del lenguaje reconocido
+	traza        traza.Trazador
+}
+
+// Opcion configura un analizador léxico al crearlo
+type Opcion func(*Lexer)
+
+// ConDialecto configura el analizador para reconocer el dialecto d en lugar
+// del dialecto base
+func ConDialecto(d *Dialecto) Opcion {
+	return func(l *Lexer) {
+		if d != nil {
+			l.dialecto = d
+		}
+	}
 }
 
-// New crea un nuevo analizador léxico
-func New(input string) *Lexer {
+// ConTraza registra en t cada token emitido
+func ConTraza(t traza.Trazador) Opcion {
+	return func(l *Lexer) {
+		if t != nil {
+			l.traza = t
+		}
+	}
+}
+
+// New crea un nuevo analizador léxico para un programa completo en memoria.
+// Los tokens emitidos se conservan para Analizar y ContarTokens.
+func New(input string, opciones ...Opcion) *Lexer {
+	l := NewReader(strings.NewReader(input), opciones...)
+	l.registrar = true
+	return l
+}
+
+// DesdePosicion indica que la entrada comienza a mitad de un programa, justo
+// después de un caracter ubicado en offset-1, línea y columna. Sirve para
+// reanalizar solo una parte del texto conservando las posiciones absolutas.
+func DesdePosicion(offset, linea, columna int) Opcion {
+	return func(l *Lexer) {
+		l.readPosition = offset
+		l.line = linea
+		l.column = columna
+	}
+}
+
+// NewReader crea un analizador léxico que lee el programa de r a través de un
+// buffer de TamanoBuffer bytes. No conserva los tokens emitidos, de modo que
+// la memoria usada no depende del tamaño de la entrada.
+func NewReader(r io.Reader, opciones ...Opcion) *Lexer {
 	l := &Lexer{
-		input:    input,
+		reader:   bufio.NewReaderSize(r, TamanoBuffer),
 		line:     1,
 		column:   0,
 		tokens:   []Token{},
+		dialecto: DialectoBase(),
+		traza:    traza.Nulo(),
+	}
+	for _, opcion := range opciones {
+		opcion(l)
 	}
 	l.readChar() // Lee el primer caracter
 	return l
 }
 
-// Analizar realiza el análisis léxico completo y devuelve todos los tokens
+// Analizar realiza el análisis léxico de lo que resta de la entrada y devuelve
+// todos los tokens encontrados. Llamarlo de nuevo no vuelve a analizar.
 func (l *Lexer) Analizar() []Token {
-	for {
+	for !l.terminado {
 		tok := l.NextToken()
-		l.tokens = append(l.tokens, tok)
-		if tok.Type == TOKEN_EOF {
-			break
+		if !l.registrar {
+			l.tokens = append(l.tokens, tok)
 		}
 	}
 	return l.tokens
 }
 
-// NextToken obtiene el siguiente token
+// Tokens devuelve un iterador que extrae los tokens bajo demanda hasta el
+// token EOF inclusive
+func (l *Lexer) Tokens() iter.Seq[Token] {
+	return func(yield func(Token) bool) {
+		for !l.terminado {
+			if !yield(l.NextToken()) {
+				return
+			}
+		}
+	}
+}
+
+// Err devuelve el primer error de lectura encontrado, si lo hubo
+func (l *Lexer) Err() error {
+	return l.err
+}
+
+// NextToken obtiene el siguiente token. Una vez alcanzado el final de la
+// entrada devuelve siempre EOF.
 func (l *Lexer) NextToken() Token {
-	l.skipWhitespace()
+	if l.terminado {
+		return Token{Type: TOKEN_EOF, Lexeme: "", Line: l.line, Column: l.column, Offset: l.position, categoria: l.dialecto.NombreCategoria(CATEGORIA_EOF)}
+	}
 
+	tok := l.leerToken()
+	tok.categoria = l.dialecto.NombreCategoria(tok.Clase())
+	if tok.Type == TOKEN_EOF {
+		l.terminado = true
+	}
+	if l.registrar {
+		l.tokens = append(l.tokens, tok)
+	}
+	l.traza.Registrar(traza.Evento{
+		Fase:      traza.FASE_LEXICA,
+		Tipo:      traza.TOKEN_LEIDO,
+		TipoToken: string(tok.Type),
+		Lexema:    tok.Lexeme,
+		Linea:     tok.Line,
+		Columna:   tok.Column,
+	})
+	return tok
+}
+
+// leerToken reconoce el token que comienza en el caracter actual
+func (l *Lexer) leerToken() Token {
 	var tok Token
+	if comentario, cerrado := l.skipTrivia(); !cerrado {
+		// Un comentario de bloque sin cerrar es un error que abarca el resto
+		// de la entrada
+		return Token{Type: TOKEN_ERROR_COMENTARIO, Lexeme: comentario.Texto, Line: comentario.Linea, Column: comentario.Columna, Offset: comentario.Offset}
+	}
+
 	// CORREGIDO: Capturar línea y columna ANTES de procesar
 	tok.Line = l.line
 	tok.Column = l.column
+	tok.Offset = l.position
 
-	// Añadir depuración
-	fmt.Printf("Analizando caracter: '%c' en posición %d, línea %d, columna %d\n", 
-		l.ch, l.position, l.line, l.column)
-
-	switch l.ch {
-	case '=':
-		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok = Token{Type: TOKEN_EQUAL, Lexeme: string(ch) + string(l.ch), Line: tok.Line, Column: tok.Column}
+	if l.ch == 0 {
+		tok = Token{Type: TOKEN_EOF, Lexeme: "", Line: tok.Line, Column: tok.Column, Offset: tok.Offset}
+	} else if lexema, tokenType, ok := l.readOperator(); ok {
+		tok = Token{Type: tokenType, Lexeme: lexema, Line: tok.Line, Column: tok.Column, Offset: tok.Offset}
+	} else if l.dialecto.inicioIdent[l.ch] {
+		tok.Lexeme = l.readIdentifier()
+		// Verifica si es una palabra reservada
+		if tokenType, ok := l.dialecto.reservadas[tok.Lexeme]; ok {
+			tok.Type = tokenType
 		} else {
-			tok = Token{Type: TOKEN_ASSIGN, Lexeme: string(l.ch), Line: tok.Line, Column: tok.Column}
-		}
-	case '+':
-		tok = Token{Type: TOKEN_PLUS, Lexeme: string(l.ch), Line: tok.Line, Column: tok.Column}
-	case '*':
-		tok = Token{Type: TOKEN_MULT, Lexeme: string(l.ch), Line: tok.Line, Column: tok.Column}
-	case ';':
-		tok = Token{Type: TOKEN_SEMI, Lexeme: string(l.ch), Line: tok.Line, Column: tok.Column}
-	case '{':
-		tok = Token{Type: TOKEN_LBRACE, Lexeme: string(l.ch), Line: tok.Line, Column: tok.Column}
-	case '}':
-		tok = Token{Type: TOKEN_RBRACE, Lexeme: string(l.ch), Line: tok.Line, Column: tok.Column}
-	case '(':
-		tok = Token{Type: TOKEN_LPAREN, Lexeme: string(l.ch), Line: tok.Line, Column: tok.Column}
-	case ')':
-		tok = Token{Type: TOKEN_RPAREN, Lexeme: string(l.ch), Line: tok.Line, Column: tok.Column}
-	case 0:
-		tok = Token{Type: TOKEN_EOF, Lexeme: "", Line: tok.Line, Column: tok.Column}
-	default:
-		if isLetter(l.ch) {
-			tok.Lexeme = l.readIdentifier()
-			// Verifica si es una palabra reservada
-			if tokenType, ok := MapaReservadas[tok.Lexeme]; ok {
-				tok.Type = tokenType
-			} else {
-				tok.Type = TOKEN_IDENT
-			}
-			return tok
-		} else if isDigit(l.ch) {
-			tok.Type = TOKEN_NUMBER
-			tok.Lexeme = l.readNumber()
-			return tok
-		} else {
-			tok = Token{Type: TOKEN_ERROR, Lexeme: string(l.ch), Line: tok.Line, Column: tok.Column}
+			tok.Type = TOKEN_IDENT
 		}
+		return tok
+	} else if l.dialecto.inicioNum[l.ch] {
+		tok.Type = TOKEN_NUMBER
+		tok.Lexeme = l.readNumber()
+		return tok
+	} else {
+		tok = Token{Type: TOKEN_ERROR, Lexeme: l.readRuna(), Line: tok.Line, Column: tok.Column, Offset: tok.Offset}
 	}
 
 	l.readChar()
 	return tok
 }
 
+// readOperator reconoce el operador más largo del dialecto que comienza en el
+// caracter actual. Deja el último caracter del operador como caracter actual.
+func (l *Lexer) readOperator() (string, TokenType, bool) {
+	for _, op := range l.dialecto.operadores {
+		if op[0] != l.ch {
+			continue
+		}
+		if len(op) > 1 {
+			siguiente, _ := l.reader.Peek(len(op) - 1)
+			if string(siguiente) != op[1:] {
+				continue
+			}
+			for i := 1; i < len(op); i++ {
+				l.readChar()
+			}
+		}
+		return op, l.dialecto.tiposOp[op], true
+	}
+	return "", "", false
+}
+
 // readChar lee el siguiente caracter y avanza la posición
 func (l *Lexer) readChar() {
-	if l.readPosition >= len(l.input) {
+	b, err := l.reader.ReadByte()
+	if err != nil {
+		if err != io.EOF && l.err == nil {
+			l.err = err
+		}
 		l.ch = 0 // ASCII 'NUL'
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.ch = b
 	}
 	
 	l.position = l.readPosition
 	l.readPosition++
 	
-	// CORREGIDO: Actualizar línea y columna correctamente
+	// El salto de línea queda en la columna 0 para que el primer caracter
+	// de cada línea esté en la columna 1, como en los editores
 	if l.ch == '\n' {
 		l.line++
-		l.column = 1  // Reiniciar columna a 1, no a 0
+		l.column = 0
 	} else {
 		l.column++
 	}
 }
 
+// readRuna devuelve el caracter UTF-8 que comienza en el caracter actual y
+// deja su último byte como caracter actual. Un byte que no inicia una
+// secuencia UTF-8 válida se devuelve solo.
+func (l *Lexer) readRuna() string {
+	if l.ch < utf8.RuneSelf {
+		return string([]byte{l.ch})
+	}
+	siguientes, _ := l.reader.Peek(utf8.UTFMax - 1)
+	bytes := append([]byte{l.ch}, siguientes...)
+	_, tamano := utf8.DecodeRune(bytes)
+	for i := 1; i < tamano; i++ {
+		l.readChar()
+	}
+	return string(bytes[:tamano])
+}
+
 // peekChar mira el siguiente caracter sin avanzar
 func (l *Lexer) peekChar() byte {
-	if l.readPosition >= len(l.input) {
+	b, err := l.reader.Peek(1)
+	if err != nil || len(b) == 0 {
 		return 0
 	}
-	return l.input[l.readPosition]
+	return b[0]
 }
 
 // readIdentifier lee un identificador o palabra reservada
 func (l *Lexer) readIdentifier() string {
-	position := l.position
-	for isLetter(l.ch) || isDigit(l.ch) {
+	var sb strings.Builder
+	sb.WriteByte(l.ch)
+	l.readChar()
+	for l.dialecto.restoIdent[l.ch] {
+		sb.WriteByte(l.ch)
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	return sb.String()
 }
 
 // readNumber lee un número
 func (l *Lexer) readNumber() string {
-	position := l.position
-	for isDigit(l.ch) {
+	var sb strings.Builder
+	sb.WriteByte(l.ch)
+	l.readChar()
+	for l.dialecto.restoNum[l.ch] {
+		sb.WriteByte(l.ch)
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	return sb.String()
 }
 
-// skipWhitespace ignora espacios en blanco
-func (l *Lexer) skipWhitespace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
-		l.readChar()
+// skipTrivia ignora espacios en blanco y comentarios, conservando estos
+// últimos. Si un comentario de bloque no se cerró lo devuelve con false.
+func (l *Lexer) skipTrivia() (Comentario, bool) {
+	for {
+		if esEspacio(l.ch) {
+			l.readChar()
+			continue
+		}
+		if !l.esInicioComentario() {
+			return Comentario{}, true
+		}
+		comentario, cerrado := l.readComment()
+		if !cerrado {
+			return comentario, false
+		}
+		if l.registrar {
+			l.comentarios = append(l.comentarios, comentario)
+		}
 	}
 }
 
-// isLetter verifica si un caracter es una letra o guión bajo
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
-}
-
-// isDigit verifica si un caracter es un dígito
-func isDigit(ch byte) bool {
-	return '0' <= ch && ch <= '9'
-}
-
-// ContarTokens cuenta el número de tokens por categoría
+// ContarTokens cuenta el número de tokens por categoría canónica entre los
+// tokens conservados (ver New y Analizar)
 func (l *Lexer) ContarTokens() map[string]int {
 	conteo := map[string]int{
-		"PR":      0,
-		"ID":      0,
-		"Numeros": 0,
-		"Simbolos": 0,
-		"Error":   0,
+		CATEGORIA_PR:       0,
+		CATEGORIA_ID:       0,
+		CATEGORIA_NUMEROS:  0,
+		CATEGORIA_SIMBOLOS: 0,
+		CATEGORIA_ERROR:    0,
 	}
 
 	for _, token := range l.tokens {
-		categoria := token.Categoria()
+		categoria := token.Clase()
 		if _, ok := conteo[categoria]; ok {
 			conteo[categoria]++
 		}
 	}
 
 	return conteo
-}
\ No newline at end of file
+}
+// ErrorLexico representa un token que no pertenece al lenguaje
+type ErrorLexico struct {
+	Mensaje string
+	Linea   int
+	Columna int
+}
+
+// Errores devuelve los errores léxicos entre los tokens conservados (ver New
+// y Analizar)
+func (l *Lexer) Errores() []ErrorLexico {
+	return ErroresDe(l.tokens)
+}
+
+// ErroresDe devuelve los errores léxicos de una secuencia de tokens: los
+// caracteres no reconocidos y el comentario de bloque sin cerrar
+func ErroresDe(tokens []Token) []ErrorLexico {
+	var errores []ErrorLexico
+	for _, token := range tokens {
+		var mensaje string
+		switch token.Type {
+		case TOKEN_ERROR:
+			mensaje = "Caracter no reconocido '" + token.Lexeme + "'"
+		case TOKEN_ERROR_COMENTARIO:
+			mensaje = "Comentario de bloque sin cerrar"
+		default:
+			continue
+		}
+		errores = append(errores, ErrorLexico{Mensaje: mensaje, Linea: token.Line, Columna: token.Column})
+	}
+	return errores
+}
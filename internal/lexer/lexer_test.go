@@ -0,0 +1,138 @@
+package lexer
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// lectorContado cuenta los bytes que se le pidieron y falla con err al
+// agotarse, si err no es nil
+type lectorContado struct {
+	r     io.Reader
+	leido int
+	err   error
+}
+
+func (l *lectorContado) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.leido += n
+	if err == io.EOF && l.err != nil {
+		err = l.err
+	}
+	return n, err
+}
+
+func TestNewReaderComoNew(t *testing.T) {
+	// Los lexemas de varios bytes quedan partidos entre dos lecturas del
+	// buffer en distintas posiciones
+	var sb strings.Builder
+	for i := 0; sb.Len() < 3*TamanoBuffer; i++ {
+		sb.WriteString("int variable_larga = 12345 == x; /* comentario */ // fin\n")
+		sb.WriteString(strings.Repeat(" ", i%7))
+	}
+	casos := []string{
+		"",
+		"int x = 10;",
+		strings.Repeat(" ", TamanoBuffer-1) + "==",
+		strings.Repeat(" ", TamanoBuffer-2) + "/* bloque */ x",
+		sb.String(),
+	}
+	for _, entrada := range casos {
+		esperados := New(entrada).Analizar()
+		var obtenidos []Token
+		for tok := range NewReader(strings.NewReader(entrada)).Tokens() {
+			obtenidos = append(obtenidos, tok)
+		}
+		if !reflect.DeepEqual(obtenidos, esperados) {
+			t.Errorf("entrada de %d bytes: NewReader produjo %d tokens distintos de los %d de New",
+				len(entrada), len(obtenidos), len(esperados))
+		}
+	}
+}
+
+func TestNewReaderLeeBajoDemanda(t *testing.T) {
+	entrada := strings.Repeat("x = x + 1;\n", 100*TamanoBuffer/11)
+	lector := &lectorContado{r: strings.NewReader(entrada)}
+	l := NewReader(lector)
+	for i := 0; i < 10; i++ {
+		l.NextToken()
+	}
+	if lector.leido > TamanoBuffer {
+		t.Errorf("se leyeron %d bytes para 10 tokens, el buffer es de %d", lector.leido, TamanoBuffer)
+	}
+}
+
+func TestNewReaderError(t *testing.T) {
+	falla := errors.New("disco roto")
+	l := NewReader(&lectorContado{r: strings.NewReader("int x"), err: falla})
+	tokens := l.Analizar()
+	if n := len(tokens); n == 0 || tokens[n-1].Type != TOKEN_EOF {
+		t.Fatalf("la entrada no terminó en EOF: %v", tokens)
+	}
+	if !errors.Is(l.Err(), falla) {
+		t.Errorf("Err() = %v, se esperaba %v", l.Err(), falla)
+	}
+	if err := New("int x").Err(); err != nil {
+		t.Errorf("Err() = %v sin errores de lectura", err)
+	}
+}
+
+func TestTokensSeDetiene(t *testing.T) {
+	l := New("a b c d")
+	var leidos []string
+	for tok := range l.Tokens() {
+		leidos = append(leidos, tok.Lexeme)
+		if len(leidos) == 2 {
+			break
+		}
+	}
+	if resto := l.NextToken(); resto.Lexeme != "c" {
+		t.Errorf("después de cortar el iterador se leyó %q, se esperaba \"c\"", resto.Lexeme)
+	}
+}
+
+func TestErrores(t *testing.T) {
+	casos := []struct {
+		entrada string
+		errores []ErrorLexico
+	}{
+		{"int x = 1;", nil},
+		{"a @ b", []ErrorLexico{{"Caracter no reconocido '@'", 1, 3}}},
+		{"int x = 1; /* abc", []ErrorLexico{{"Comentario de bloque sin cerrar", 1, 12}}},
+		// Un caracter UTF-8 de varios bytes es un solo error, y no se
+		// confunde con un comentario sin cerrar
+		{"int año = 1;", []ErrorLexico{{"Caracter no reconocido 'ñ'", 1, 6}}},
+		{"x = \xff;", []ErrorLexico{{"Caracter no reconocido '\xff'", 1, 5}}},
+	}
+	for _, c := range casos {
+		t.Run(c.entrada, func(t *testing.T) {
+			l := New(c.entrada)
+			l.Analizar()
+			if got := l.Errores(); !reflect.DeepEqual(got, c.errores) {
+				t.Errorf("Errores() = %v, se esperaba %v", got, c.errores)
+			}
+		})
+	}
+}
+
+func TestErrorRunaConsumeSusBytes(t *testing.T) {
+	tokens := New("año;").Analizar()
+	var tipos []TokenType
+	var lexemas []string
+	for _, tok := range tokens {
+		tipos = append(tipos, tok.Type)
+		lexemas = append(lexemas, tok.Lexeme)
+	}
+	if want := []TokenType{TOKEN_IDENT, TOKEN_ERROR, TOKEN_IDENT, TOKEN_SEMI, TOKEN_EOF}; !reflect.DeepEqual(tipos, want) {
+		t.Fatalf("tipos %v, se esperaban %v", tipos, want)
+	}
+	if want := []string{"a", "ñ", "o", ";", ""}; !reflect.DeepEqual(lexemas, want) {
+		t.Errorf("lexemas %q, se esperaban %q", lexemas, want)
+	}
+	if tokens[2].Column != 4 || tokens[2].Offset != 3 {
+		t.Errorf("'o' en la columna %d, desplazamiento %d; se esperaba 4, 3", tokens[2].Column, tokens[2].Offset)
+	}
+}
@@ -8,54 +8,146 @@ type TokenType string
 // Definición de tipos de tokens
 const (
 	// Palabras reservadas
-	TOKEN_INT    TokenType = "PR"     // int
-	TOKEN_DO     TokenType = "PR"     // do
-	TOKEN_WHILE  TokenType = "PR"     // while
-	
+	TOKEN_INT    TokenType = "INT"    // int
+	TOKEN_DO     TokenType = "DO"     // do
+	TOKEN_WHILE  TokenType = "WHILE"  // while
+	TOKEN_RETURN TokenType = "RETURN" // return
+	TOKEN_PRINT  TokenType = "PRINT"  // print
+	TOKEN_READ   TokenType = "READ"   // read
+	TOKEN_CONST  TokenType = "CONST"  // const
+
 	// Identificadores
-	TOKEN_IDENT  TokenType = "ID"     // identificadores (a, b, c, x, etc.)
-	
+	TOKEN_IDENT TokenType = "IDENT" // identificadores (a, b, c, x, etc.)
+
 	// Literales
-	TOKEN_NUMBER TokenType = "Numeros" // números (0, 10, 2, 3, etc.)
-	
+	TOKEN_NUMBER TokenType = "NUMBER" // números (0, 10, 2, 3, etc.)
+
 	// Operadores y símbolos
-	TOKEN_ASSIGN TokenType = "Simbolos" // =
-	TOKEN_PLUS   TokenType = "Simbolos" // +
-	TOKEN_MULT   TokenType = "Simbolos" // *
-	TOKEN_EQUAL  TokenType = "Simbolos" // ==
-	TOKEN_SEMI   TokenType = "Simbolos" // ;
-	TOKEN_LBRACE TokenType = "Simbolos" // {
-	TOKEN_RBRACE TokenType = "Simbolos" // }
-	TOKEN_LPAREN TokenType = "Simbolos" // (
-	TOKEN_RPAREN TokenType = "Simbolos" // )
-	
+	TOKEN_ASSIGN   TokenType = "ASSIGN"   // =
+	TOKEN_PLUS     TokenType = "PLUS"     // +
+	TOKEN_MULT     TokenType = "MULT"     // *
+	TOKEN_DIV      TokenType = "DIV"      // /
+	TOKEN_MOD      TokenType = "MOD"      // %
+	TOKEN_EQUAL    TokenType = "EQUAL"    // ==
+	TOKEN_SEMI     TokenType = "SEMI"     // ;
+	TOKEN_LBRACE   TokenType = "LBRACE"   // {
+	TOKEN_RBRACE   TokenType = "RBRACE"   // }
+	TOKEN_LPAREN   TokenType = "LPAREN"   // (
+	TOKEN_RPAREN   TokenType = "RPAREN"   // )
+	TOKEN_COMMA    TokenType = "COMMA"    // ,
+	TOKEN_LBRACKET TokenType = "LBRACKET" // [
+	TOKEN_RBRACKET TokenType = "RBRACKET" // ]
+
 	// Especiales
-	TOKEN_EOF    TokenType = "EOF"     // Fin de archivo
-	TOKEN_ERROR  TokenType = "Error"   // Error
+	TOKEN_EOF              TokenType = "EOF"              // Fin de archivo
+	TOKEN_ERROR            TokenType = "ERROR"            // Caracter no reconocido
+	TOKEN_ERROR_COMENTARIO TokenType = "ERROR_COMENTARIO" // Comentario de bloque sin cerrar
 )
 
+// Categorías generales de token. Son los nombres canónicos; un dialecto
+// puede mostrarlas con otros nombres (ver Dialecto).
+const (
+	CATEGORIA_PR       = "PR"
+	CATEGORIA_ID       = "ID"
+	CATEGORIA_NUMEROS  = "Numeros"
+	CATEGORIA_SIMBOLOS = "Simbolos"
+	CATEGORIA_EOF      = "EOF"
+	CATEGORIA_ERROR    = "Error"
+)
+
+// categoriasTipo asigna a cada tipo de token su categoría canónica
+var categoriasTipo = map[TokenType]string{
+	TOKEN_INT:              CATEGORIA_PR,
+	TOKEN_DO:               CATEGORIA_PR,
+	TOKEN_WHILE:            CATEGORIA_PR,
+	TOKEN_RETURN:           CATEGORIA_PR,
+	TOKEN_PRINT:            CATEGORIA_PR,
+	TOKEN_READ:             CATEGORIA_PR,
+	TOKEN_CONST:            CATEGORIA_PR,
+	TOKEN_IDENT:            CATEGORIA_ID,
+	TOKEN_NUMBER:           CATEGORIA_NUMEROS,
+	TOKEN_ASSIGN:           CATEGORIA_SIMBOLOS,
+	TOKEN_PLUS:             CATEGORIA_SIMBOLOS,
+	TOKEN_MULT:             CATEGORIA_SIMBOLOS,
+	TOKEN_DIV:              CATEGORIA_SIMBOLOS,
+	TOKEN_MOD:              CATEGORIA_SIMBOLOS,
+	TOKEN_EQUAL:            CATEGORIA_SIMBOLOS,
+	TOKEN_SEMI:             CATEGORIA_SIMBOLOS,
+	TOKEN_LBRACE:           CATEGORIA_SIMBOLOS,
+	TOKEN_RBRACE:           CATEGORIA_SIMBOLOS,
+	TOKEN_LPAREN:           CATEGORIA_SIMBOLOS,
+	TOKEN_RPAREN:           CATEGORIA_SIMBOLOS,
+	TOKEN_COMMA:            CATEGORIA_SIMBOLOS,
+	TOKEN_LBRACKET:         CATEGORIA_SIMBOLOS,
+	TOKEN_RBRACKET:         CATEGORIA_SIMBOLOS,
+	TOKEN_EOF:              CATEGORIA_EOF,
+	TOKEN_ERROR:            CATEGORIA_ERROR,
+	TOKEN_ERROR_COMENTARIO: CATEGORIA_ERROR,
+}
+
 // Token representa un token individual identificado por el analizador léxico
 type Token struct {
-	Type    TokenType // Tipo del token (PR, ID, Numeros, Simbolos, Error)
-	Lexeme  string    // El texto literal del token
-	Line    int       // Línea donde se encontró el token
-	Column  int       // Columna donde se encontró el token
+	Type   TokenType // Tipo del token (INT, IDENT, NUMBER, ASSIGN, ...)
+	Lexeme string    // El texto literal del token
+	Line   int       // Línea donde se encontró el token
+	Column int       // Columna donde se encontró el token
+	Offset int       // Posición en bytes del primer caracter en la entrada
+
+	categoria string // nombre de la categoría según el dialecto
 }
 
 // MapaReservadas mapea palabras reservadas a sus respectivos tipos de token
+// en el dialecto base
 var MapaReservadas = map[string]TokenType{
-	"int":   TOKEN_INT,
-	"do":    TOKEN_DO,
-	"while": TOKEN_WHILE,
+	"int":    TOKEN_INT,
+	"do":     TOKEN_DO,
+	"while":  TOKEN_WHILE,
+	"return": TOKEN_RETURN,
+	"print":  TOKEN_PRINT,
+	"read":   TOKEN_READ,
+	"const":  TOKEN_CONST,
 }
 
-// Categoría retorna la categoría general del token (PR, ID, Numeros, Simbolos, Error)
-func (t *Token) Categoria() string {
+// MapaOperadores mapea los operadores y símbolos del dialecto base a sus
+// tipos de token
+var MapaOperadores = map[string]TokenType{
+	"=":  TOKEN_ASSIGN,
+	"==": TOKEN_EQUAL,
+	"+":  TOKEN_PLUS,
+	"*":  TOKEN_MULT,
+	"/":  TOKEN_DIV,
+	"%":  TOKEN_MOD,
+	";":  TOKEN_SEMI,
+	"{":  TOKEN_LBRACE,
+	"}":  TOKEN_RBRACE,
+	"(":  TOKEN_LPAREN,
+	")":  TOKEN_RPAREN,
+	",":  TOKEN_COMMA,
+	"[":  TOKEN_LBRACKET,
+	"]":  TOKEN_RBRACKET,
+}
+
+// Clase retorna la categoría canónica del token (PR, ID, Numeros, Simbolos,
+// EOF, Error), independiente del dialecto. Los tipos definidos fuera de este
+// paquete son su propia categoría.
+func (t *Token) Clase() string {
+	if categoria, ok := categoriasTipo[t.Type]; ok {
+		return categoria
+	}
 	return string(t.Type)
 }
 
+// Categoría retorna el nombre de la categoría general del token tal como la
+// nombra el dialecto con el que se analizó
+func (t *Token) Categoria() string {
+	if t.categoria != "" {
+		return t.categoria
+	}
+	return t.Clase()
+}
+
 // String implementa la interfaz Stringer para facilitar la depuración
 func (t Token) String() string {
-	return fmt.Sprintf("Token{Type: %s, Lexeme: '%s', Line: %d, Column: %d}", 
+	return fmt.Sprintf("Token{Type: %s, Lexeme: '%s', Line: %d, Column: %d}",
 		t.Type, t.Lexeme, t.Line, t.Column)
-}
\ No newline at end of file
+}
@@ -0,0 +1,197 @@
+// Package metricas calcula métricas de código de un programa: cantidad de
+// sentencias, anidamiento de ciclos, complejidad ciclomática, las medidas de
+// Halstead y datos de las variables e identificadores.
+package metricas
+
+import (
+	"math"
+	"unicode/utf8"
+
+	"analyzer-api/internal/lexer"
+	"analyzer-api/internal/parser"
+)
+
+// Halstead reúne las cuentas de operadores y operandos de Halstead y las
+// medidas que se derivan de ellas. Los operandos son los identificadores y
+// los números; los operadores, las palabras reservadas y los símbolos.
+type Halstead struct {
+	OperadoresDistintos int     `json:"operadoresDistintos"` // n1
+	OperandosDistintos  int     `json:"operandosDistintos"`  // n2
+	Operadores          int     `json:"operadores"`          // N1
+	Operandos           int     `json:"operandos"`           // N2
+	Vocabulario         int     `json:"vocabulario"`         // n = n1 + n2
+	Longitud            int     `json:"longitud"`            // N = N1 + N2
+	Volumen             float64 `json:"volumen"`             // V = N log2 n
+	Dificultad          float64 `json:"dificultad"`          // D = n1/2 · N2/n2
+	Esfuerzo            float64 `json:"esfuerzo"`            // E = D · V
+}
+
+// MetricasFuncion son las métricas del cuerpo de una función
+type MetricasFuncion struct {
+	Nombre                 string `json:"nombre"`
+	Sentencias             int    `json:"sentencias"`
+	ProfundidadCiclos      int    `json:"profundidadCiclos"`
+	ComplejidadCiclomatica int    `json:"complejidadCiclomatica"`
+}
+
+// Metricas son las métricas de un programa
+type Metricas struct {
+	// Sentencias cuenta todas las declaraciones, también las que están
+	// dentro de ciclos y funciones
+	Sentencias int `json:"sentencias"`
+	// ProfundidadCiclos es la mayor cantidad de do-while anidados
+	ProfundidadCiclos int `json:"profundidadCiclos"`
+	// ComplejidadCiclomatica es la de McCabe: 1 más la cantidad de
+	// decisiones por el programa principal y por cada función
+	ComplejidadCiclomatica int `json:"complejidadCiclomatica"`
+
+	Halstead Halstead `json:"halstead"`
+
+	// Variables cuenta las variables y arreglos declarados, globales o
+	// locales, sin contar constantes ni parámetros
+	Variables int `json:"variables"`
+	// LongitudIdentificadores es el promedio de caracteres de los nombres
+	// distintos que aparecen en el programa
+	LongitudIdentificadores float64 `json:"longitudPromedioIdentificadores"`
+
+	Funciones []MetricasFuncion `json:"funciones"`
+}
+
+// Calcular obtiene las métricas de un programa a partir de sus tokens y de
+// su árbol sintáctico
+func Calcular(tokens []lexer.Token, programa *parser.Programa) Metricas {
+	m := Metricas{
+		Halstead:  calcularHalstead(tokens),
+		Funciones: []MetricasFuncion{},
+	}
+	m.LongitudIdentificadores = longitudIdentificadores(tokens)
+	if programa == nil {
+		m.ComplejidadCiclomatica = 1
+		return m
+	}
+
+	// El programa principal es todo lo que está fuera de las funciones
+	var principal []parser.Declaracion
+	for _, decl := range programa.Declaraciones {
+		funcion, ok := decl.(*parser.DeclaracionFuncion)
+		if !ok {
+			principal = append(principal, decl)
+			continue
+		}
+		c := contar(funcion.Cuerpo)
+		m.Funciones = append(m.Funciones, MetricasFuncion{
+			Nombre:                 funcion.Nombre,
+			Sentencias:             c.sentencias,
+			ProfundidadCiclos:      c.profundidadMaxima,
+			ComplejidadCiclomatica: c.decisiones + 1,
+		})
+		m.Sentencias += c.sentencias + 1 // la definición también es una sentencia
+		m.ProfundidadCiclos = max(m.ProfundidadCiclos, c.profundidadMaxima)
+		m.ComplejidadCiclomatica += c.decisiones + 1
+		m.Variables += c.variables
+	}
+	c := contar(principal)
+	m.Sentencias += c.sentencias
+	m.ProfundidadCiclos = max(m.ProfundidadCiclos, c.profundidadMaxima)
+	m.ComplejidadCiclomatica += c.decisiones + 1
+	m.Variables += c.variables
+	return m
+}
+
+// contador recorre declaraciones contando sentencias, decisiones, variables
+// y el anidamiento de los ciclos
+type contador struct {
+	sentencias        int
+	decisiones        int
+	variables         int
+	profundidad       int
+	profundidadMaxima int
+}
+
+func contar(declaraciones []parser.Declaracion) *contador {
+	c := &contador{}
+	for _, decl := range declaraciones {
+		parser.Walk(c, decl)
+	}
+	return c
+}
+
+func (c *contador) Pre(n parser.Nodo) bool {
+	if _, ok := n.(parser.Declaracion); ok {
+		c.sentencias++
+	}
+	switch nodo := n.(type) {
+	case *parser.DeclaracionVariable:
+		if !nodo.Constante {
+			c.variables++
+		}
+	case *parser.DeclaracionArreglo:
+		c.variables++
+	case *parser.DeclaracionDoWhile:
+		// Cada ciclo decide si repetir el cuerpo
+		c.decisiones++
+		c.profundidad++
+		c.profundidadMaxima = max(c.profundidadMaxima, c.profundidad)
+	case parser.Expresion:
+		return false
+	}
+	return true
+}
+
+func (c *contador) Post(n parser.Nodo) {
+	if _, ok := n.(*parser.DeclaracionDoWhile); ok {
+		c.profundidad--
+	}
+}
+
+// calcularHalstead cuenta los operadores y operandos de los tokens
+func calcularHalstead(tokens []lexer.Token) Halstead {
+	var h Halstead
+	operadores := map[lexer.TokenType]bool{}
+	operandos := map[string]bool{}
+	for i := range tokens {
+		switch tokens[i].Clase() {
+		case lexer.CATEGORIA_ID, lexer.CATEGORIA_NUMEROS:
+			h.Operandos++
+			operandos[tokens[i].Lexeme] = true
+		case lexer.CATEGORIA_PR, lexer.CATEGORIA_SIMBOLOS:
+			h.Operadores++
+			operadores[tokens[i].Type] = true
+		}
+	}
+	h.OperadoresDistintos = len(operadores)
+	h.OperandosDistintos = len(operandos)
+	h.Vocabulario = h.OperadoresDistintos + h.OperandosDistintos
+	h.Longitud = h.Operadores + h.Operandos
+	if h.Vocabulario > 0 {
+		h.Volumen = redondear(float64(h.Longitud) * math.Log2(float64(h.Vocabulario)))
+	}
+	if h.OperandosDistintos > 0 {
+		h.Dificultad = redondear(float64(h.OperadoresDistintos) / 2 * float64(h.Operandos) / float64(h.OperandosDistintos))
+	}
+	h.Esfuerzo = redondear(h.Dificultad * h.Volumen)
+	return h
+}
+
+// longitudIdentificadores promedia la longitud de los identificadores
+// distintos
+func longitudIdentificadores(tokens []lexer.Token) float64 {
+	nombres := map[string]bool{}
+	total := 0
+	for i := range tokens {
+		if tokens[i].Clase() != lexer.CATEGORIA_ID || nombres[tokens[i].Lexeme] {
+			continue
+		}
+		nombres[tokens[i].Lexeme] = true
+		total += utf8.RuneCountInString(tokens[i].Lexeme)
+	}
+	if len(nombres) == 0 {
+		return 0
+	}
+	return redondear(float64(total) / float64(len(nombres)))
+}
+
+// redondear deja dos decimales
+func redondear(x float64) float64 {
+	return math.Round(x*100) / 100
+}
@@ -0,0 +1,129 @@
+package metricas
+
+import (
+	"reflect"
+	"testing"
+
+	"analyzer-api/internal/lexer"
+	"analyzer-api/internal/parser"
+)
+
+func calcular(t *testing.T, codigo string) Metricas {
+	t.Helper()
+	p := parser.New(lexer.New(codigo))
+	programa := p.Parse()
+	if errores := p.Errores(); len(errores) > 0 {
+		t.Fatalf("errores de sintaxis: %v", errores)
+	}
+	return Calcular(lexer.New(codigo).Analizar(), programa)
+}
+
+func TestCalcular(t *testing.T) {
+	// Los ciclos del programa principal van uno después del otro: la
+	// profundidad vuelve a 0 al salir del primero
+	m := calcular(t, `int x = 0;
+int suma(int a, int b) {
+	int t = a;
+	do {
+		t = t + b;
+	} while (t == 1);
+	return t;
+}
+do {
+	x = x + 1;
+} while (x == 2);
+do {
+	print(x);
+} while (x == 3);
+`)
+
+	// suma: int t, do-while, la asignación del cuerpo y return son 4
+	// sentencias; un ciclo da complejidad 2
+	funciones := []MetricasFuncion{{Nombre: "suma", Sentencias: 4, ProfundidadCiclos: 1, ComplejidadCiclomatica: 2}}
+	if !reflect.DeepEqual(m.Funciones, funciones) {
+		t.Errorf("funciones %+v, se esperaba %+v", m.Funciones, funciones)
+	}
+	// Principal: int x, dos do-while y una sentencia en cada cuerpo son 5;
+	// más las 4 de suma y su definición
+	if m.Sentencias != 10 {
+		t.Errorf("%d sentencias, se esperaban 10", m.Sentencias)
+	}
+	// McCabe suma 1 + 2 ciclos del principal y 1 + 1 ciclo de suma
+	if m.ComplejidadCiclomatica != 5 {
+		t.Errorf("complejidad %d, se esperaba 5", m.ComplejidadCiclomatica)
+	}
+	if m.ProfundidadCiclos != 1 {
+		t.Errorf("profundidad %d, se esperaba 1", m.ProfundidadCiclos)
+	}
+	// x y t; los parámetros no cuentan
+	if m.Variables != 2 {
+		t.Errorf("%d variables, se esperaban 2", m.Variables)
+	}
+	// x, suma, a, b, t: 8 caracteres en 5 nombres
+	if m.LongitudIdentificadores != 1.6 {
+		t.Errorf("longitud promedio %v, se esperaba 1.6", m.LongitudIdentificadores)
+	}
+}
+
+func TestCalcularSinArbol(t *testing.T) {
+	m := Calcular(lexer.New("int x = ;").Analizar(), nil)
+	if m.ComplejidadCiclomatica != 1 || m.Sentencias != 0 || len(m.Funciones) != 0 {
+		t.Errorf("métricas %+v sin árbol", m)
+	}
+}
+
+func TestHalstead(t *testing.T) {
+	m := calcular(t, "int x = 1; x = x + 2;")
+	// Operadores: int = ; = + ; de 4 tipos. Operandos: x 1 x x 2, 3 distintos.
+	// V = 11 · log2 7, D = 4/2 · 5/3, E = D · V con los valores redondeados.
+	esperado := Halstead{
+		OperadoresDistintos: 4,
+		OperandosDistintos:  3,
+		Operadores:          6,
+		Operandos:           5,
+		Vocabulario:         7,
+		Longitud:            11,
+		Volumen:             30.88,
+		Dificultad:          3.33,
+		Esfuerzo:            102.83,
+	}
+	if m.Halstead != esperado {
+		t.Errorf("Halstead %+v, se esperaba %+v", m.Halstead, esperado)
+	}
+
+	// Sin operandos no hay dificultad
+	if h := calcularHalstead(lexer.New(";").Analizar()); h.Dificultad != 0 || h.Volumen != 0 {
+		t.Errorf("Halstead %+v de un programa sin operandos", h)
+	}
+}
+
+func TestResumir(t *testing.T) {
+	lote := []Metricas{
+		{Sentencias: 2, ComplejidadCiclomatica: 1, Halstead: Halstead{Volumen: 10.5}},
+		{Sentencias: 4, ComplejidadCiclomatica: 2, Halstead: Halstead{Volumen: 20}},
+		{Sentencias: 9, ComplejidadCiclomatica: 2, Halstead: Halstead{Volumen: 1.25}},
+	}
+	r := Resumir(lote)
+	if r.Programas != 3 {
+		t.Errorf("%d programas, se esperaban 3", r.Programas)
+	}
+	casos := []struct {
+		nombre   string
+		obtenida Estadistica
+		esperada Estadistica
+	}{
+		{"sentencias", r.Sentencias, Estadistica{Minimo: 2, Maximo: 9, Promedio: 5}},
+		{"complejidad", r.ComplejidadCiclomatica, Estadistica{Minimo: 1, Maximo: 2, Promedio: 1.67}},
+		{"volumen", r.VolumenHalstead, Estadistica{Minimo: 1.25, Maximo: 20, Promedio: 10.58}},
+		{"profundidad", r.ProfundidadCiclos, Estadistica{}},
+	}
+	for _, c := range casos {
+		if c.obtenida != c.esperada {
+			t.Errorf("%s: %+v, se esperaba %+v", c.nombre, c.obtenida, c.esperada)
+		}
+	}
+
+	if vacio := Resumir(nil); vacio != (Resumen{}) {
+		t.Errorf("resumen de un lote vacío %+v", vacio)
+	}
+}
@@ -0,0 +1,50 @@
+package metricas
+
+// Estadistica resume una métrica en un conjunto de programas
+type Estadistica struct {
+	Minimo   float64 `json:"minimo"`
+	Maximo   float64 `json:"maximo"`
+	Promedio float64 `json:"promedio"`
+}
+
+// Resumen reúne las métricas de un lote de programas
+type Resumen struct {
+	Programas               int         `json:"programas"`
+	Sentencias              Estadistica `json:"sentencias"`
+	ProfundidadCiclos       Estadistica `json:"profundidadCiclos"`
+	ComplejidadCiclomatica  Estadistica `json:"complejidadCiclomatica"`
+	VolumenHalstead         Estadistica `json:"volumenHalstead"`
+	EsfuerzoHalstead        Estadistica `json:"esfuerzoHalstead"`
+	Variables               Estadistica `json:"variables"`
+	LongitudIdentificadores Estadistica `json:"longitudPromedioIdentificadores"`
+}
+
+// Resumir calcula el mínimo, el máximo y el promedio de cada métrica
+func Resumir(lote []Metricas) Resumen {
+	r := Resumen{Programas: len(lote)}
+	estadistica := func(valor func(Metricas) float64) Estadistica {
+		var e Estadistica
+		for i, m := range lote {
+			v := valor(m)
+			if i == 0 || v < e.Minimo {
+				e.Minimo = v
+			}
+			if i == 0 || v > e.Maximo {
+				e.Maximo = v
+			}
+			e.Promedio += v
+		}
+		if len(lote) > 0 {
+			e.Promedio = redondear(e.Promedio / float64(len(lote)))
+		}
+		return e
+	}
+	r.Sentencias = estadistica(func(m Metricas) float64 { return float64(m.Sentencias) })
+	r.ProfundidadCiclos = estadistica(func(m Metricas) float64 { return float64(m.ProfundidadCiclos) })
+	r.ComplejidadCiclomatica = estadistica(func(m Metricas) float64 { return float64(m.ComplejidadCiclomatica) })
+	r.VolumenHalstead = estadistica(func(m Metricas) float64 { return m.Halstead.Volumen })
+	r.EsfuerzoHalstead = estadistica(func(m Metricas) float64 { return m.Halstead.Esfuerzo })
+	r.Variables = estadistica(func(m Metricas) float64 { return float64(m.Variables) })
+	r.LongitudIdentificadores = estadistica(func(m Metricas) float64 { return m.LongitudIdentificadores })
+	return r
+}
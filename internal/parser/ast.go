@@ -1,8 +1,11 @@
 package parser
 
+import "analyzer-api/internal/lexer"
+
 // Nodo es la interfaz base para todos los nodos del AST
 type Nodo interface {
 	TokenLiteral() string
+	Ubicacion() Rango
 }
 
 // Expresion representa una expresión en el AST
@@ -19,6 +22,7 @@ type Declaracion interface {
 
 // Programa representa el programa completo
 type Programa struct {
+	Rango
 	Declaraciones []Declaracion
 }
 
@@ -30,70 +34,219 @@ func (p *Programa) TokenLiteral() string {
 }
 
 // DeclaracionVariable representa una declaración de variable (int a = 0;)
+// o de constante (const int N = 10;)
 type DeclaracionVariable struct {
-	Tipo     string    // Tipo de la variable (int, float, etc.)
-	Nombre   string    // Nombre de la variable
-	Valor    Expresion // Valor asignado
+	Rango
+	Tipo      string    // Tipo de la variable (int, float, etc.)
+	Nombre    string    // Nombre de la variable
+	Valor     Expresion // Valor asignado
+	Constante bool      // Declarada con const
 }
 
-func (dv *DeclaracionVariable) esDeclaracion() {}
+func (dv *DeclaracionVariable) esDeclaracion()       {}
 func (dv *DeclaracionVariable) TokenLiteral() string { return dv.Tipo }
 
 // ExpresionIdentificador representa un identificador
 type ExpresionIdentificador struct {
+	Rango
 	Valor string
 }
 
-func (ei *ExpresionIdentificador) esExpresion() {}
+func (ei *ExpresionIdentificador) esExpresion()         {}
 func (ei *ExpresionIdentificador) TokenLiteral() string { return ei.Valor }
 
 // ExpresionNumero representa un literal numérico
 type ExpresionNumero struct {
+	Rango
 	Valor string
 }
 
-func (en *ExpresionNumero) esExpresion() {}
+func (en *ExpresionNumero) esExpresion()         {}
 func (en *ExpresionNumero) TokenLiteral() string { return en.Valor }
 
 // ExpresionBinaria representa una operación binaria (a + b, a * b)
 type ExpresionBinaria struct {
+	Rango
 	Izquierda Expresion
 	Operador  string
 	Derecha   Expresion
 }
 
-func (eb *ExpresionBinaria) esExpresion() {}
+func (eb *ExpresionBinaria) esExpresion()         {}
 func (eb *ExpresionBinaria) TokenLiteral() string { return eb.Operador }
 
-// ExpresionAsignacion representa una asignación (a = 5)
+// ExpresionAsignacion representa una asignación (a = 5, v[i] = 5)
 type ExpresionAsignacion struct {
+	Rango
 	Nombre string
+	Indice Expresion // nil salvo al asignar un elemento de un arreglo
 	Valor  Expresion
 }
 
-func (ea *ExpresionAsignacion) esExpresion() {}
+func (ea *ExpresionAsignacion) esExpresion()         {}
 func (ea *ExpresionAsignacion) TokenLiteral() string { return "=" }
 
 // DeclaracionAsignacion envuelve una ExpresionAsignacion para implementar Declaracion
 type DeclaracionAsignacion struct {
+	Rango
 	Asignacion *ExpresionAsignacion
 }
 
-func (da *DeclaracionAsignacion) esDeclaracion() {}
+func (da *DeclaracionAsignacion) esDeclaracion()       {}
 func (da *DeclaracionAsignacion) TokenLiteral() string { return "=" }
 
 // DeclaracionDoWhile representa una estructura do-while
 type DeclaracionDoWhile struct {
-	Cuerpo      []Declaracion
-	Condicion   Expresion
+	Rango
+	Cuerpo    []Declaracion
+	Condicion Expresion
 }
 
-func (dw *DeclaracionDoWhile) esDeclaracion() {}
+func (dw *DeclaracionDoWhile) esDeclaracion()       {}
 func (dw *DeclaracionDoWhile) TokenLiteral() string { return "do" }
 
+// DeclaracionImpresion escribe el valor de una expresión en la salida (print(a + 1);)
+type DeclaracionImpresion struct {
+	Rango
+	Valor Expresion
+}
+
+func (di *DeclaracionImpresion) esDeclaracion()       {}
+func (di *DeclaracionImpresion) TokenLiteral() string { return "print" }
+
+// DeclaracionLectura lee un entero de la entrada en una variable o en un
+// elemento de un arreglo (read(a); o read(v[i]);)
+type DeclaracionLectura struct {
+	Rango
+	Nombre string
+	Indice Expresion // nil salvo al leer un elemento de un arreglo
+}
+
+func (dl *DeclaracionLectura) esDeclaracion()       {}
+func (dl *DeclaracionLectura) TokenLiteral() string { return "read" }
+
+// DeclaracionArreglo representa la declaración de un arreglo de tamaño fijo
+// (int v[3]; o int v[3] = {1, 2, 3};)
+type DeclaracionArreglo struct {
+	Rango
+	Tipo      string // Tipo de los elementos
+	Nombre    string
+	Tamano    *ExpresionNumero
+	Elementos []Expresion // Valores iniciales; nil si no se inicializa
+}
+
+func (da *DeclaracionArreglo) esDeclaracion()       {}
+func (da *DeclaracionArreglo) TokenLiteral() string { return da.Tipo }
+
+// ExpresionIndice representa el acceso a un elemento de un arreglo (v[i])
+type ExpresionIndice struct {
+	Rango
+	Nombre string
+	Indice Expresion
+}
+
+func (ei *ExpresionIndice) esExpresion()         {}
+func (ei *ExpresionIndice) TokenLiteral() string { return ei.Nombre }
+
+// DeclaracionFuncion representa la definición de una función
+// (int suma(int a, int b) { ... return a + b; })
+type DeclaracionFuncion struct {
+	Rango
+	Tipo       string // Tipo del valor devuelto
+	Nombre     string
+	Parametros []*Parametro
+	Cuerpo     []Declaracion
+}
+
+func (df *DeclaracionFuncion) esDeclaracion()       {}
+func (df *DeclaracionFuncion) TokenLiteral() string { return df.Tipo }
+
+// Parametro representa un parámetro en la definición de una función (int a)
+type Parametro struct {
+	Rango
+	Tipo   string
+	Nombre string
+}
+
+func (pa *Parametro) TokenLiteral() string { return pa.Tipo }
+
+// DeclaracionRetorno representa la devolución del valor de una función (return a;)
+type DeclaracionRetorno struct {
+	Rango
+	Valor Expresion
+}
+
+func (dr *DeclaracionRetorno) esDeclaracion()       {}
+func (dr *DeclaracionRetorno) TokenLiteral() string { return "return" }
+
+// ExpresionLlamada representa la llamada a una función (suma(a, 2))
+type ExpresionLlamada struct {
+	Rango
+	Nombre     string
+	Argumentos []Expresion
+}
+
+func (el *ExpresionLlamada) esExpresion()         {}
+func (el *ExpresionLlamada) TokenLiteral() string { return el.Nombre }
+
+// DeclaracionLlamada envuelve una ExpresionLlamada usada como declaración (f(a);)
+type DeclaracionLlamada struct {
+	Rango
+	Llamada *ExpresionLlamada
+}
+
+func (dl *DeclaracionLlamada) esDeclaracion()       {}
+func (dl *DeclaracionLlamada) TokenLiteral() string { return dl.Llamada.TokenLiteral() }
+
+// Posicion ubica un caracter en el código fuente. Línea y columna comienzan
+// en 1 y el offset en 0.
+type Posicion struct {
+	Offset  int `json:"offset"`
+	Linea   int `json:"linea"`
+	Columna int `json:"columna"`
+}
+
+// Rango es el fragmento del código fuente que ocupa un nodo: desde el primer
+// caracter de su primer token hasta justo después del último
+type Rango struct {
+	Inicio Posicion `json:"inicio"`
+	Fin    Posicion `json:"fin"`
+}
+
+// Ubicacion devuelve el rango. Todos los nodos lo incluyen, así que todos
+// implementan este método.
+func (r Rango) Ubicacion() Rango { return r }
+
+func (r *Rango) reubicar(nuevo Rango) { *r = nuevo }
+
+// inicioToken devuelve la posición del primer caracter del token
+func inicioToken(tok lexer.Token) Posicion {
+	return Posicion{Offset: tok.Offset, Linea: tok.Line, Columna: tok.Column}
+}
+
+// finToken devuelve la posición justo después del último caracter del token.
+// Ningún lexema contiene saltos de línea.
+func finToken(tok lexer.Token) Posicion {
+	n := len(tok.Lexeme)
+	return Posicion{Offset: tok.Offset + n, Linea: tok.Line, Columna: tok.Column + n}
+}
+
 // Error de sintaxis
 type ErrorSintactico struct {
 	Mensaje string
 	Linea   int
 	Columna int
-}
\ No newline at end of file
+}
+
+// Trasladar devuelve una copia del nodo y sus descendientes con cada
+// posición transformada por f. Permite reutilizar subárboles cuando el texto
+// anterior a ellos cambió.
+func Trasladar(n Nodo, f func(Posicion) Posicion) Nodo {
+	return Rewrite(n, func(copia Nodo) Nodo {
+		if r, ok := copia.(interface{ reubicar(Rango) }); ok {
+			rango := copia.Ubicacion()
+			r.reubicar(Rango{Inicio: f(rango.Inicio), Fin: f(rango.Fin)})
+		}
+		return copia
+	})
+}
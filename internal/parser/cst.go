@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"strings"
+
+	"analyzer-api/internal/lexer"
+)
+
+// Epsilon representa la cadena vacía en las producciones
+const Epsilon = "ε"
+
+// NodoConcreto es un nodo del árbol de análisis (árbol sintáctico concreto):
+// un no terminal con los símbolos en que se expandió, o un terminal con su
+// lexema. A diferencia del AST conserva todos los tokens, incluidos ';',
+// llaves y paréntesis.
+type NodoConcreto struct {
+	Simbolo  string          `json:"simbolo"`
+	Terminal bool            `json:"terminal"`
+	Lexema   string          `json:"lexema,omitempty"`
+	Rango    Rango           `json:"span"`
+	Hijos    []*NodoConcreto `json:"hijos,omitempty"`
+}
+
+// Produccion es una regla de la gramática. Derecha vacía representa ε.
+type Produccion struct {
+	Izquierda string   `json:"izquierda"`
+	Derecha   []string `json:"derecha"`
+}
+
+// String muestra la producción como "a → b c"
+func (pr Produccion) String() string {
+	if len(pr.Derecha) == 0 {
+		return pr.Izquierda + " → " + Epsilon
+	}
+	return pr.Izquierda + " → " + strings.Join(pr.Derecha, " ")
+}
+
+// PasoDerivacion es la aplicación de una producción al no terminal de más a
+// la izquierda. Posicion es el índice de ese no terminal en la forma
+// sentencial anterior; la forma que resulta se obtiene reemplazándolo por el
+// lado derecho de la producción. No se incluye la forma completa porque su
+// tamaño crece con el programa y la derivación tendría tamaño cuadrático.
+type PasoDerivacion struct {
+	Produccion Produccion `json:"produccion"`
+	Posicion   int        `json:"posicion"`
+}
+
+// ConArbolConcreto hace que el parser construya el árbol concreto junto con
+// el AST (ver ArbolConcreto)
+func ConArbolConcreto() Opcion {
+	return func(p *Parser) {
+		p.cst = &constructorCST{}
+	}
+}
+
+// ArbolConcreto devuelve el árbol concreto construido por Parse, o nil si el
+// parser no se creó con ConArbolConcreto o si el árbol usó una producción
+// que no está en la gramática. Si hubo errores de sintaxis el árbol contiene
+// solo lo que se llegó a reconocer.
+func (p *Parser) ArbolConcreto() *NodoConcreto {
+	if p.cst == nil {
+		return nil
+	}
+	return p.cst.raiz
+}
+
+// Derivacion reconstruye la derivación por la izquierda que corresponde al
+// árbol: un paso por cada no terminal, en preorden. A la izquierda del no
+// terminal que se expande solo hay terminales, así que su posición es la
+// cantidad de terminales que lo preceden en el preorden.
+func (n *NodoConcreto) Derivacion() []PasoDerivacion {
+	if n == nil {
+		return nil
+	}
+	pasos := []PasoDerivacion{}
+	terminales := 0
+	pendientes := []*NodoConcreto{n}
+	for len(pendientes) > 0 {
+		nodo := pendientes[len(pendientes)-1]
+		pendientes = pendientes[:len(pendientes)-1]
+		if nodo.Terminal {
+			terminales++
+			continue
+		}
+		pasos = append(pasos, PasoDerivacion{Produccion: nodo.Produccion(), Posicion: terminales})
+		for i := len(nodo.Hijos) - 1; i >= 0; i-- {
+			pendientes = append(pendientes, nodo.Hijos[i])
+		}
+	}
+	return pasos
+}
+
+// AplicarPaso devuelve la forma sentencial que resulta de aplicar el paso a
+// la forma dada
+func AplicarPaso(forma []string, paso PasoDerivacion) []string {
+	siguiente := make([]string, 0, len(forma)+len(paso.Produccion.Derecha))
+	siguiente = append(siguiente, forma[:paso.Posicion]...)
+	siguiente = append(siguiente, paso.Produccion.Derecha...)
+	return append(siguiente, forma[paso.Posicion+1:]...)
+}
+
+// Produccion devuelve la producción aplicada en un no terminal
+func (n *NodoConcreto) Produccion() Produccion {
+	pr := Produccion{Izquierda: n.Simbolo, Derecha: []string{}}
+	for _, h := range n.Hijos {
+		pr.Derecha = append(pr.Derecha, h.Simbolo)
+	}
+	return pr
+}
+
+// constructorCST arma el árbol concreto a medida que el parser entra y sale
+// de las reglas
+type constructorCST struct {
+	raiz     *NodoConcreto
+	abiertos []*NodoConcreto
+}
+
+// abrir comienza un no terminal como hijo del no terminal abierto
+func (p *Parser) abrir(simbolo string) {
+	if p.cst == nil {
+		return
+	}
+	nodo := &NodoConcreto{Simbolo: simbolo}
+	nodo.Rango.Inicio = inicioToken(p.curToken)
+	nodo.Rango.Fin = nodo.Rango.Inicio
+	if n := len(p.cst.abiertos); n > 0 {
+		padre := p.cst.abiertos[n-1]
+		padre.Hijos = append(padre.Hijos, nodo)
+	} else {
+		p.cst.raiz = nodo
+	}
+	p.cst.abiertos = append(p.cst.abiertos, nodo)
+}
+
+// cerrar termina los últimos n no terminales abiertos, ajustando su rango al
+// de sus hijos
+func (p *Parser) cerrar(n int) {
+	if p.cst == nil {
+		return
+	}
+	for ; n > 0 && len(p.cst.abiertos) > 0; n-- {
+		ultimo := len(p.cst.abiertos) - 1
+		nodo := p.cst.abiertos[ultimo]
+		p.cst.abiertos = p.cst.abiertos[:ultimo]
+		if len(nodo.Hijos) > 0 {
+			nodo.Rango.Inicio = nodo.Hijos[0].Rango.Inicio
+			nodo.Rango.Fin = nodo.Hijos[len(nodo.Hijos)-1].Rango.Fin
+		}
+	}
+}
+
+// hoja agrega el token actual como terminal del no terminal abierto
+func (p *Parser) hoja() {
+	if p.cst == nil || len(p.cst.abiertos) == 0 || p.curToken.Type == lexer.TOKEN_EOF {
+		return
+	}
+	padre := p.cst.abiertos[len(p.cst.abiertos)-1]
+	padre.Hijos = append(padre.Hijos, &NodoConcreto{
+		Simbolo:  string(p.curToken.Type),
+		Terminal: true,
+		Lexema:   p.curToken.Lexeme,
+		Rango:    Rango{Inicio: inicioToken(p.curToken), Fin: finToken(p.curToken)},
+	})
+}
@@ -0,0 +1,361 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"analyzer-api/internal/lexer"
+)
+
+// Nombres de las reglas de la gramática. Son los que usan la traza y el
+// árbol concreto.
+const (
+	REGLA_PROGRAMA                = "programa"
+	REGLA_DECLARACIONES           = "declaraciones"
+	REGLA_DECLARACION             = "declaracion"
+	REGLA_DECLARACION_ENTERA      = "declaracionEntera"
+	REGLA_DECLARACION_CONSTANTE   = "declaracionConstante"
+	REGLA_RESTO_DECLARACION       = "restoDeclaracion"
+	REGLA_DECLARACION_VARIABLE    = "declaracionVariable"
+	REGLA_DECLARACION_ARREGLO     = "declaracionArreglo"
+	REGLA_INICIALIZACION_ARREGLO  = "inicializacionArreglo"
+	REGLA_MAS_ELEMENTOS           = "masElementos"
+	REGLA_DECLARACION_FUNCION     = "declaracionFuncion"
+	REGLA_PARAMETROS              = "parametros"
+	REGLA_MAS_PARAMETROS          = "masParametros"
+	REGLA_PARAMETRO               = "parametro"
+	REGLA_CUERPO_FUNCION          = "cuerpoFuncion"
+	REGLA_SENTENCIA_IDENTIFICADOR = "sentenciaIdentificador"
+	REGLA_RESTO_SENTENCIA         = "restoSentencia"
+	REGLA_ASIGNACION              = "asignacion"
+	REGLA_INDICE                  = "indice"
+	REGLA_SENTENCIA_LLAMADA       = "sentenciaLlamada"
+	REGLA_RETORNO                 = "retorno"
+	REGLA_IMPRESION               = "impresion"
+	REGLA_LECTURA                 = "lectura"
+	REGLA_SENTENCIA               = "sentencia"
+	REGLA_DO_WHILE                = "doWhile"
+	REGLA_CUERPO                  = "cuerpo"
+	REGLA_EXPRESION_COMPLETA      = "expresionCompleta"
+	REGLA_OPERACION               = "operacion"
+	REGLA_EXPRESION_COMPARACION   = "expresionComparacion"
+	REGLA_EXPRESION_SIMPLE        = "expresionSimple"
+	REGLA_RESTO_IDENTIFICADOR     = "restoIdentificador"
+	REGLA_ARGUMENTOS              = "argumentos"
+	REGLA_MAS_ARGUMENTOS          = "masArgumentos"
+)
+
+// TipoElemento clasifica los elementos de una expresión EBNF
+type TipoElemento string
+
+const (
+	ELEMENTO_TERMINAL    TipoElemento = "terminal"    // un tipo de token
+	ELEMENTO_NO_TERMINAL TipoElemento = "noTerminal"  // una regla
+	ELEMENTO_SECUENCIA   TipoElemento = "secuencia"   // los hijos en orden; sin hijos es ε
+	ELEMENTO_ALTERNATIVA TipoElemento = "alternativa" // uno de los hijos
+	ELEMENTO_REPETICION  TipoElemento = "repeticion"  // el único hijo cero o más veces
+)
+
+// Elemento es una expresión EBNF del lado derecho de una regla. Simbolo es
+// el tipo de token de un terminal o el nombre de la regla de un no terminal.
+type Elemento struct {
+	Tipo    TipoElemento `json:"tipo"`
+	Simbolo string       `json:"simbolo,omitempty"`
+	Hijos   []Elemento   `json:"hijos,omitempty"`
+}
+
+// Regla define un no terminal de la gramática
+type Regla struct {
+	Nombre string   `json:"nombre"`
+	Cuerpo Elemento `json:"cuerpo"`
+}
+
+func terminal(tipo lexer.TokenType) Elemento {
+	return Elemento{Tipo: ELEMENTO_TERMINAL, Simbolo: string(tipo)}
+}
+
+func noTerminal(regla string) Elemento {
+	return Elemento{Tipo: ELEMENTO_NO_TERMINAL, Simbolo: regla}
+}
+
+func secuencia(elementos ...Elemento) Elemento {
+	return Elemento{Tipo: ELEMENTO_SECUENCIA, Hijos: elementos}
+}
+
+func alternativa(elementos ...Elemento) Elemento {
+	return Elemento{Tipo: ELEMENTO_ALTERNATIVA, Hijos: elementos}
+}
+
+func repeticion(elemento Elemento) Elemento {
+	return Elemento{Tipo: ELEMENTO_REPETICION, Hijos: []Elemento{elemento}}
+}
+
+// reglas es la gramática que reconoce el parser. Cada función parse* sigue
+// la regla de su nombre; al construir el árbol concreto de un programa sin
+// errores, Parse verifica que todas las producciones usadas estén aquí.
+//
+// Para que la forma BNF coincida con el árbol concreto, una repetición solo
+// puede ser el cuerpo completo de una regla (R → x R | ε) y las alternativas
+// solo pueden contener símbolos o secuencias de símbolos.
+var reglas = []Regla{
+	{REGLA_PROGRAMA, noTerminal(REGLA_DECLARACIONES)},
+	{REGLA_DECLARACIONES, repeticion(noTerminal(REGLA_DECLARACION))},
+	{REGLA_DECLARACION, alternativa(
+		noTerminal(REGLA_DECLARACION_ENTERA),
+		noTerminal(REGLA_DECLARACION_CONSTANTE),
+		noTerminal(REGLA_SENTENCIA_IDENTIFICADOR),
+		noTerminal(REGLA_DO_WHILE),
+		noTerminal(REGLA_RETORNO),
+		noTerminal(REGLA_IMPRESION),
+		noTerminal(REGLA_LECTURA),
+	)},
+	// Las variables, los arreglos y las funciones comienzan igual: int IDENT
+	{REGLA_DECLARACION_ENTERA, secuencia(
+		terminal(lexer.TOKEN_INT), terminal(lexer.TOKEN_IDENT), noTerminal(REGLA_RESTO_DECLARACION),
+	)},
+	{REGLA_DECLARACION_CONSTANTE, secuencia(
+		terminal(lexer.TOKEN_CONST), terminal(lexer.TOKEN_INT), terminal(lexer.TOKEN_IDENT), terminal(lexer.TOKEN_ASSIGN),
+		noTerminal(REGLA_EXPRESION_COMPLETA), terminal(lexer.TOKEN_SEMI),
+	)},
+	{REGLA_RESTO_DECLARACION, alternativa(
+		noTerminal(REGLA_DECLARACION_VARIABLE),
+		noTerminal(REGLA_DECLARACION_ARREGLO),
+		noTerminal(REGLA_DECLARACION_FUNCION),
+	)},
+	{REGLA_DECLARACION_VARIABLE, secuencia(
+		terminal(lexer.TOKEN_ASSIGN), noTerminal(REGLA_EXPRESION_COMPLETA), terminal(lexer.TOKEN_SEMI),
+	)},
+	{REGLA_DECLARACION_ARREGLO, secuencia(
+		terminal(lexer.TOKEN_LBRACKET), terminal(lexer.TOKEN_NUMBER), terminal(lexer.TOKEN_RBRACKET),
+		noTerminal(REGLA_INICIALIZACION_ARREGLO), terminal(lexer.TOKEN_SEMI),
+	)},
+	{REGLA_INICIALIZACION_ARREGLO, alternativa(
+		secuencia(
+			terminal(lexer.TOKEN_ASSIGN), terminal(lexer.TOKEN_LBRACE),
+			noTerminal(REGLA_EXPRESION_SIMPLE), noTerminal(REGLA_MAS_ELEMENTOS), terminal(lexer.TOKEN_RBRACE),
+		),
+		secuencia(),
+	)},
+	{REGLA_MAS_ELEMENTOS, repeticion(secuencia(terminal(lexer.TOKEN_COMMA), noTerminal(REGLA_EXPRESION_SIMPLE)))},
+	{REGLA_DECLARACION_FUNCION, secuencia(
+		terminal(lexer.TOKEN_LPAREN), noTerminal(REGLA_PARAMETROS), terminal(lexer.TOKEN_RPAREN),
+		terminal(lexer.TOKEN_LBRACE), noTerminal(REGLA_CUERPO_FUNCION), terminal(lexer.TOKEN_RBRACE),
+	)},
+	{REGLA_PARAMETROS, alternativa(
+		secuencia(noTerminal(REGLA_PARAMETRO), noTerminal(REGLA_MAS_PARAMETROS)),
+		secuencia(),
+	)},
+	{REGLA_MAS_PARAMETROS, repeticion(secuencia(terminal(lexer.TOKEN_COMMA), noTerminal(REGLA_PARAMETRO)))},
+	{REGLA_PARAMETRO, secuencia(terminal(lexer.TOKEN_INT), terminal(lexer.TOKEN_IDENT))},
+	{REGLA_CUERPO_FUNCION, repeticion(noTerminal(REGLA_DECLARACION))},
+	// Las asignaciones y las llamadas comienzan igual: IDENT
+	{REGLA_SENTENCIA_IDENTIFICADOR, secuencia(terminal(lexer.TOKEN_IDENT), noTerminal(REGLA_RESTO_SENTENCIA))},
+	{REGLA_RESTO_SENTENCIA, alternativa(
+		noTerminal(REGLA_ASIGNACION),
+		noTerminal(REGLA_SENTENCIA_LLAMADA),
+	)},
+	{REGLA_ASIGNACION, secuencia(
+		noTerminal(REGLA_INDICE), terminal(lexer.TOKEN_ASSIGN), noTerminal(REGLA_EXPRESION_COMPLETA), terminal(lexer.TOKEN_SEMI),
+	)},
+	// Sin índice se asigna a una variable y con índice a un elemento de un arreglo
+	{REGLA_INDICE, alternativa(
+		secuencia(terminal(lexer.TOKEN_LBRACKET), noTerminal(REGLA_EXPRESION_COMPLETA), terminal(lexer.TOKEN_RBRACKET)),
+		secuencia(),
+	)},
+	{REGLA_SENTENCIA_LLAMADA, secuencia(
+		terminal(lexer.TOKEN_LPAREN), noTerminal(REGLA_ARGUMENTOS), terminal(lexer.TOKEN_RPAREN), terminal(lexer.TOKEN_SEMI),
+	)},
+	{REGLA_RETORNO, secuencia(
+		terminal(lexer.TOKEN_RETURN), noTerminal(REGLA_EXPRESION_COMPLETA), terminal(lexer.TOKEN_SEMI),
+	)},
+	{REGLA_IMPRESION, secuencia(
+		terminal(lexer.TOKEN_PRINT), terminal(lexer.TOKEN_LPAREN), noTerminal(REGLA_EXPRESION_COMPLETA),
+		terminal(lexer.TOKEN_RPAREN), terminal(lexer.TOKEN_SEMI),
+	)},
+	{REGLA_LECTURA, secuencia(
+		terminal(lexer.TOKEN_READ), terminal(lexer.TOKEN_LPAREN), terminal(lexer.TOKEN_IDENT), noTerminal(REGLA_INDICE),
+		terminal(lexer.TOKEN_RPAREN), terminal(lexer.TOKEN_SEMI),
+	)},
+	{REGLA_DO_WHILE, secuencia(
+		terminal(lexer.TOKEN_DO), terminal(lexer.TOKEN_LBRACE), noTerminal(REGLA_CUERPO), terminal(lexer.TOKEN_RBRACE),
+		terminal(lexer.TOKEN_WHILE), terminal(lexer.TOKEN_LPAREN), noTerminal(REGLA_EXPRESION_COMPARACION),
+		terminal(lexer.TOKEN_RPAREN), terminal(lexer.TOKEN_SEMI),
+	)},
+	{REGLA_CUERPO, repeticion(noTerminal(REGLA_SENTENCIA))},
+	// Lo que puede aparecer en el cuerpo de un do-while
+	{REGLA_SENTENCIA, alternativa(
+		noTerminal(REGLA_SENTENCIA_IDENTIFICADOR),
+		noTerminal(REGLA_IMPRESION),
+		noTerminal(REGLA_LECTURA),
+	)},
+	{REGLA_EXPRESION_COMPLETA, secuencia(noTerminal(REGLA_EXPRESION_SIMPLE), noTerminal(REGLA_OPERACION))},
+	{REGLA_OPERACION, alternativa(
+		secuencia(terminal(lexer.TOKEN_PLUS), noTerminal(REGLA_EXPRESION_SIMPLE)),
+		secuencia(terminal(lexer.TOKEN_MULT), noTerminal(REGLA_EXPRESION_SIMPLE)),
+		secuencia(terminal(lexer.TOKEN_DIV), noTerminal(REGLA_EXPRESION_SIMPLE)),
+		secuencia(terminal(lexer.TOKEN_MOD), noTerminal(REGLA_EXPRESION_SIMPLE)),
+		secuencia(),
+	)},
+	{REGLA_EXPRESION_COMPARACION, secuencia(
+		noTerminal(REGLA_EXPRESION_SIMPLE), terminal(lexer.TOKEN_EQUAL), noTerminal(REGLA_EXPRESION_SIMPLE),
+	)},
+	{REGLA_EXPRESION_SIMPLE, alternativa(
+		secuencia(terminal(lexer.TOKEN_IDENT), noTerminal(REGLA_RESTO_IDENTIFICADOR)),
+		terminal(lexer.TOKEN_NUMBER),
+	)},
+	// Un identificador seguido de '(' es una llamada y seguido de '[' un
+	// elemento de un arreglo
+	{REGLA_RESTO_IDENTIFICADOR, alternativa(
+		secuencia(terminal(lexer.TOKEN_LPAREN), noTerminal(REGLA_ARGUMENTOS), terminal(lexer.TOKEN_RPAREN)),
+		secuencia(terminal(lexer.TOKEN_LBRACKET), noTerminal(REGLA_EXPRESION_COMPLETA), terminal(lexer.TOKEN_RBRACKET)),
+		secuencia(),
+	)},
+	{REGLA_ARGUMENTOS, alternativa(
+		secuencia(noTerminal(REGLA_EXPRESION_COMPLETA), noTerminal(REGLA_MAS_ARGUMENTOS)),
+		secuencia(),
+	)},
+	{REGLA_MAS_ARGUMENTOS, repeticion(secuencia(terminal(lexer.TOKEN_COMMA), noTerminal(REGLA_EXPRESION_COMPLETA)))},
+}
+
+// produccionesGramatica es la forma BNF de reglas, calculada una sola vez
+var produccionesGramatica = calcularProducciones()
+
+// produccionesValidas contiene el texto de cada producción de la gramática,
+// para que VerificarArbol no tenga que recorrerlas en cada nodo
+var produccionesValidas = func() map[string]bool {
+	validas := map[string]bool{}
+	for _, p := range produccionesGramatica {
+		validas[p.String()] = true
+	}
+	return validas
+}()
+
+// Gramatica devuelve las reglas que reconoce el parser, empezando por el
+// símbolo inicial. El resultado no debe modificarse.
+func Gramatica() []Regla {
+	return reglas
+}
+
+// Producciones devuelve la gramática en BNF, con los terminales escritos como
+// tipos de token. Son las producciones que aparecen en el árbol concreto.
+func Producciones() []Produccion {
+	return append([]Produccion{}, produccionesGramatica...)
+}
+
+// calcularProducciones traduce las reglas EBNF a BNF
+func calcularProducciones() []Produccion {
+	var producciones []Produccion
+	for _, r := range reglas {
+		switch r.Cuerpo.Tipo {
+		case ELEMENTO_REPETICION:
+			derecha := append(simbolosBNF(r.Nombre, r.Cuerpo.Hijos[0]), r.Nombre)
+			producciones = append(producciones,
+				Produccion{Izquierda: r.Nombre, Derecha: derecha},
+				Produccion{Izquierda: r.Nombre, Derecha: []string{}})
+		case ELEMENTO_ALTERNATIVA:
+			for _, alt := range r.Cuerpo.Hijos {
+				producciones = append(producciones, Produccion{Izquierda: r.Nombre, Derecha: simbolosBNF(r.Nombre, alt)})
+			}
+		default:
+			producciones = append(producciones, Produccion{Izquierda: r.Nombre, Derecha: simbolosBNF(r.Nombre, r.Cuerpo)})
+		}
+	}
+	return producciones
+}
+
+// simbolosBNF devuelve los símbolos de un símbolo o una secuencia de símbolos
+func simbolosBNF(regla string, e Elemento) []string {
+	switch e.Tipo {
+	case ELEMENTO_TERMINAL, ELEMENTO_NO_TERMINAL:
+		return []string{e.Simbolo}
+	case ELEMENTO_SECUENCIA:
+		simbolos := []string{}
+		for _, h := range e.Hijos {
+			if h.Tipo != ELEMENTO_TERMINAL && h.Tipo != ELEMENTO_NO_TERMINAL {
+				panic(fmt.Sprintf("parser: la regla %s anida un elemento %s", regla, h.Tipo))
+			}
+			simbolos = append(simbolos, h.Simbolo)
+		}
+		return simbolos
+	}
+	panic(fmt.Sprintf("parser: la regla %s anida un elemento %s", regla, e.Tipo))
+}
+
+// VerificarArbol comprueba que cada no terminal del árbol concreto se haya
+// expandido con una producción de la gramática
+func VerificarArbol(n *NodoConcreto) error {
+	if n == nil || n.Terminal {
+		return nil
+	}
+	produccion := n.Produccion()
+	if !produccionesValidas[produccion.String()] {
+		return fmt.Errorf("la producción %s no está en la gramática", produccion)
+	}
+	for _, h := range n.Hijos {
+		if err := VerificarArbol(h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EBNF devuelve la gramática en EBNF, una regla por línea. Los terminales
+// con lexema fijo se escriben entre comillas con el lexema del dialecto; el
+// resto (IDENT, NUMBER) con el nombre del tipo de token. El texto puede
+// leerse con gramatica.Cargar.
+func EBNF(dialecto *lexer.Dialecto) string {
+	if dialecto == nil {
+		dialecto = lexer.DialectoBase()
+	}
+	ancho := 0
+	for _, r := range reglas {
+		ancho = max(ancho, len(r.Nombre))
+	}
+	var sb strings.Builder
+	for _, r := range reglas {
+		fmt.Fprintf(&sb, "%-*s ::= %s ;\n", ancho, r.Nombre, r.Cuerpo.EBNF(dialecto))
+	}
+	return sb.String()
+}
+
+// EBNF devuelve el elemento en EBNF con los lexemas del dialecto
+func (e Elemento) EBNF(dialecto *lexer.Dialecto) string {
+	switch e.Tipo {
+	case ELEMENTO_TERMINAL:
+		return TextoTerminal(lexer.TokenType(e.Simbolo), dialecto)
+	case ELEMENTO_NO_TERMINAL:
+		return e.Simbolo
+	case ELEMENTO_REPETICION:
+		return "{ " + e.Hijos[0].EBNF(dialecto) + " }"
+	case ELEMENTO_ALTERNATIVA:
+		partes := make([]string, len(e.Hijos))
+		for i, h := range e.Hijos {
+			partes[i] = h.EBNF(dialecto)
+		}
+		return strings.Join(partes, " | ")
+	}
+	if len(e.Hijos) == 0 {
+		return Epsilon
+	}
+	partes := make([]string, len(e.Hijos))
+	for i, h := range e.Hijos {
+		partes[i] = h.EBNF(dialecto)
+		if h.Tipo == ELEMENTO_ALTERNATIVA {
+			partes[i] = "( " + partes[i] + " )"
+		}
+	}
+	return strings.Join(partes, " ")
+}
+
+// TextoTerminal muestra un terminal como su lexema entre comillas si es una
+// palabra reservada u operador del dialecto, o como el nombre de su tipo
+func TextoTerminal(tipo lexer.TokenType, dialecto *lexer.Dialecto) string {
+	lexema, ok := dialecto.Lexema(tipo)
+	if !ok {
+		return string(tipo)
+	}
+	if strings.Contains(lexema, `"`) {
+		return "'" + lexema + "'"
+	}
+	return `"` + lexema + `"`
+}
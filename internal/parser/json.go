@@ -0,0 +1,571 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Tipos de nodo en la representación JSON del AST (campo "kind")
+const (
+	NODO_PROGRAMA                = "Programa"
+	NODO_DECLARACION_VARIABLE    = "DeclaracionVariable"
+	NODO_DECLARACION_ASIGNACION  = "DeclaracionAsignacion"
+	NODO_DECLARACION_DO_WHILE    = "DeclaracionDoWhile"
+	NODO_EXPRESION_IDENTIFICADOR = "ExpresionIdentificador"
+	NODO_EXPRESION_NUMERO        = "ExpresionNumero"
+	NODO_EXPRESION_BINARIA       = "ExpresionBinaria"
+	NODO_EXPRESION_ASIGNACION    = "ExpresionAsignacion"
+	NODO_DECLARACION_FUNCION     = "DeclaracionFuncion"
+	NODO_PARAMETRO               = "Parametro"
+	NODO_DECLARACION_RETORNO     = "DeclaracionRetorno"
+	NODO_DECLARACION_LLAMADA     = "DeclaracionLlamada"
+	NODO_EXPRESION_LLAMADA       = "ExpresionLlamada"
+	NODO_DECLARACION_ARREGLO     = "DeclaracionArreglo"
+	NODO_EXPRESION_INDICE        = "ExpresionIndice"
+	NODO_DECLARACION_IMPRESION   = "DeclaracionImpresion"
+	NODO_DECLARACION_LECTURA     = "DeclaracionLectura"
+)
+
+// Roles de los hijos en la representación JSON, para distinguir por ejemplo
+// el cuerpo de la condición de un do-while
+const (
+	ROL_DECLARACION = "declaracion"
+	ROL_VALOR       = "valor"
+	ROL_IZQUIERDA   = "izquierda"
+	ROL_DERECHA     = "derecha"
+	ROL_ASIGNACION  = "asignacion"
+	ROL_CUERPO      = "cuerpo"
+	ROL_CONDICION   = "condicion"
+	ROL_PARAMETRO   = "parametro"
+	ROL_ARGUMENTO   = "argumento"
+	ROL_LLAMADA     = "llamada"
+	ROL_TAMANO      = "tamano"
+	ROL_ELEMENTO    = "elemento"
+	ROL_INDICE      = "indice"
+)
+
+// NodoJSON es la forma serializable de un nodo del AST. Todos los nodos
+// comparten la misma estructura: el tipo en Kind, sus atributos propios y los
+// hijos en orden, cada uno con el rol que ocupa en el padre.
+type NodoJSON struct {
+	Kind      string      `json:"kind"`
+	Rol       string      `json:"rol,omitempty"`
+	Rango     Rango       `json:"span"`
+	Tipo      string      `json:"tipo,omitempty"`
+	Nombre    string      `json:"nombre,omitempty"`
+	Valor     string      `json:"valor,omitempty"`
+	Operador  string      `json:"operador,omitempty"`
+	Constante bool        `json:"constante,omitempty"`
+	Children  []*NodoJSON `json:"children"`
+}
+
+// CodificarNodo convierte un nodo y todos sus descendientes a su forma
+// serializable. Devuelve nil si n es nil.
+func CodificarNodo(n Nodo) *NodoJSON {
+	if esNil(n) {
+		return nil
+	}
+	j := &NodoJSON{Rango: n.Ubicacion(), Children: []*NodoJSON{}}
+	switch nodo := n.(type) {
+	case *Programa:
+		j.Kind = NODO_PROGRAMA
+		for _, decl := range nodo.Declaraciones {
+			j.agregarHijo(ROL_DECLARACION, decl)
+		}
+	case *DeclaracionVariable:
+		j.Kind = NODO_DECLARACION_VARIABLE
+		j.Tipo = nodo.Tipo
+		j.Nombre = nodo.Nombre
+		j.Constante = nodo.Constante
+		j.agregarHijo(ROL_VALOR, nodo.Valor)
+	case *DeclaracionAsignacion:
+		j.Kind = NODO_DECLARACION_ASIGNACION
+		if nodo.Asignacion != nil {
+			j.agregarHijo(ROL_ASIGNACION, nodo.Asignacion)
+		}
+	case *DeclaracionDoWhile:
+		j.Kind = NODO_DECLARACION_DO_WHILE
+		for _, decl := range nodo.Cuerpo {
+			j.agregarHijo(ROL_CUERPO, decl)
+		}
+		j.agregarHijo(ROL_CONDICION, nodo.Condicion)
+	case *DeclaracionImpresion:
+		j.Kind = NODO_DECLARACION_IMPRESION
+		j.agregarHijo(ROL_VALOR, nodo.Valor)
+	case *DeclaracionLectura:
+		j.Kind = NODO_DECLARACION_LECTURA
+		j.Nombre = nodo.Nombre
+		j.agregarHijo(ROL_INDICE, nodo.Indice)
+	case *DeclaracionArreglo:
+		j.Kind = NODO_DECLARACION_ARREGLO
+		j.Tipo = nodo.Tipo
+		j.Nombre = nodo.Nombre
+		if nodo.Tamano != nil {
+			j.agregarHijo(ROL_TAMANO, nodo.Tamano)
+		}
+		for _, elemento := range nodo.Elementos {
+			j.agregarHijo(ROL_ELEMENTO, elemento)
+		}
+	case *ExpresionIndice:
+		j.Kind = NODO_EXPRESION_INDICE
+		j.Nombre = nodo.Nombre
+		j.agregarHijo(ROL_INDICE, nodo.Indice)
+	case *DeclaracionFuncion:
+		j.Kind = NODO_DECLARACION_FUNCION
+		j.Tipo = nodo.Tipo
+		j.Nombre = nodo.Nombre
+		for _, parametro := range nodo.Parametros {
+			j.agregarHijo(ROL_PARAMETRO, parametro)
+		}
+		for _, decl := range nodo.Cuerpo {
+			j.agregarHijo(ROL_CUERPO, decl)
+		}
+	case *Parametro:
+		j.Kind = NODO_PARAMETRO
+		j.Tipo = nodo.Tipo
+		j.Nombre = nodo.Nombre
+	case *DeclaracionRetorno:
+		j.Kind = NODO_DECLARACION_RETORNO
+		j.agregarHijo(ROL_VALOR, nodo.Valor)
+	case *DeclaracionLlamada:
+		j.Kind = NODO_DECLARACION_LLAMADA
+		if nodo.Llamada != nil {
+			j.agregarHijo(ROL_LLAMADA, nodo.Llamada)
+		}
+	case *ExpresionLlamada:
+		j.Kind = NODO_EXPRESION_LLAMADA
+		j.Nombre = nodo.Nombre
+		for _, argumento := range nodo.Argumentos {
+			j.agregarHijo(ROL_ARGUMENTO, argumento)
+		}
+	case *ExpresionIdentificador:
+		j.Kind = NODO_EXPRESION_IDENTIFICADOR
+		j.Valor = nodo.Valor
+	case *ExpresionNumero:
+		j.Kind = NODO_EXPRESION_NUMERO
+		j.Valor = nodo.Valor
+	case *ExpresionBinaria:
+		j.Kind = NODO_EXPRESION_BINARIA
+		j.Operador = nodo.Operador
+		j.agregarHijo(ROL_IZQUIERDA, nodo.Izquierda)
+		j.agregarHijo(ROL_DERECHA, nodo.Derecha)
+	case *ExpresionAsignacion:
+		j.Kind = NODO_EXPRESION_ASIGNACION
+		j.Nombre = nodo.Nombre
+		j.agregarHijo(ROL_INDICE, nodo.Indice)
+		j.agregarHijo(ROL_VALOR, nodo.Valor)
+	default:
+		j.Kind = fmt.Sprintf("%T", n)
+	}
+	return j
+}
+
+// agregarHijo codifica hijo con el rol dado, salvo que sea nil
+func (j *NodoJSON) agregarHijo(rol string, hijo Nodo) {
+	if h := CodificarNodo(hijo); h != nil {
+		h.Rol = rol
+		j.Children = append(j.Children, h)
+	}
+}
+
+// Nodo reconstruye el nodo del AST descrito por j. Falla si a un nodo le
+// falta un hijo obligatorio, como la condición de un do-while o un operando
+// de una expresión binaria.
+func (j *NodoJSON) Nodo() (Nodo, error) {
+	if j == nil {
+		return nil, fmt.Errorf("nodo nulo")
+	}
+	switch j.Kind {
+	case NODO_PROGRAMA:
+		programa := &Programa{Rango: j.Rango, Declaraciones: []Declaracion{}}
+		for _, h := range j.Children {
+			decl, err := h.declaracion(ROL_DECLARACION)
+			if err != nil {
+				return nil, err
+			}
+			programa.Declaraciones = append(programa.Declaraciones, decl)
+		}
+		return programa, nil
+
+	case NODO_DECLARACION_VARIABLE:
+		if err := j.verificarHijos(ROL_VALOR); err != nil {
+			return nil, err
+		}
+		decl := &DeclaracionVariable{Rango: j.Rango, Tipo: j.Tipo, Nombre: j.Nombre, Constante: j.Constante}
+		for _, h := range j.Children {
+			valor, err := h.expresion(ROL_VALOR)
+			if err != nil {
+				return nil, err
+			}
+			decl.Valor = valor
+		}
+		return decl, nil
+
+	case NODO_DECLARACION_ASIGNACION:
+		if err := j.verificarHijos(ROL_ASIGNACION); err != nil {
+			return nil, err
+		}
+		decl := &DeclaracionAsignacion{Rango: j.Rango}
+		for _, h := range j.Children {
+			expr, err := h.expresion(ROL_ASIGNACION)
+			if err != nil {
+				return nil, err
+			}
+			asignacion, ok := expr.(*ExpresionAsignacion)
+			if !ok {
+				return nil, fmt.Errorf("%s: se esperaba %s, se encontró %s", j.Kind, NODO_EXPRESION_ASIGNACION, h.Kind)
+			}
+			decl.Asignacion = asignacion
+		}
+		return decl, nil
+
+	case NODO_DECLARACION_DO_WHILE:
+		if err := j.verificarHijos(ROL_CONDICION); err != nil {
+			return nil, err
+		}
+		dowhile := &DeclaracionDoWhile{Rango: j.Rango, Cuerpo: []Declaracion{}}
+		for _, h := range j.Children {
+			if h != nil && h.Rol == ROL_CONDICION {
+				condicion, err := h.expresion(ROL_CONDICION)
+				if err != nil {
+					return nil, err
+				}
+				dowhile.Condicion = condicion
+				continue
+			}
+			decl, err := h.declaracion(ROL_CUERPO)
+			if err != nil {
+				return nil, err
+			}
+			dowhile.Cuerpo = append(dowhile.Cuerpo, decl)
+		}
+		return dowhile, nil
+
+	case NODO_DECLARACION_IMPRESION:
+		if err := j.verificarHijos(ROL_VALOR); err != nil {
+			return nil, err
+		}
+		impresion := &DeclaracionImpresion{Rango: j.Rango}
+		for _, h := range j.Children {
+			valor, err := h.expresion(ROL_VALOR)
+			if err != nil {
+				return nil, err
+			}
+			impresion.Valor = valor
+		}
+		return impresion, nil
+
+	case NODO_DECLARACION_LECTURA:
+		if err := j.verificarHijos(); err != nil {
+			return nil, err
+		}
+		lectura := &DeclaracionLectura{Rango: j.Rango, Nombre: j.Nombre}
+		for _, h := range j.Children {
+			indice, err := h.expresion(ROL_INDICE)
+			if err != nil {
+				return nil, err
+			}
+			lectura.Indice = indice
+		}
+		return lectura, nil
+
+	case NODO_DECLARACION_ARREGLO:
+		if err := j.verificarHijos(ROL_TAMANO); err != nil {
+			return nil, err
+		}
+		arreglo := &DeclaracionArreglo{Rango: j.Rango, Tipo: j.Tipo, Nombre: j.Nombre}
+		for _, h := range j.Children {
+			if h != nil && h.Rol == ROL_TAMANO {
+				expr, err := h.expresion(ROL_TAMANO)
+				if err != nil {
+					return nil, err
+				}
+				tamano, ok := expr.(*ExpresionNumero)
+				if !ok {
+					return nil, fmt.Errorf("%s: se esperaba %s, se encontró %s", j.Kind, NODO_EXPRESION_NUMERO, h.Kind)
+				}
+				arreglo.Tamano = tamano
+				continue
+			}
+			elemento, err := h.expresion(ROL_ELEMENTO)
+			if err != nil {
+				return nil, err
+			}
+			arreglo.Elementos = append(arreglo.Elementos, elemento)
+		}
+		return arreglo, nil
+
+	case NODO_EXPRESION_INDICE:
+		if err := j.verificarHijos(ROL_INDICE); err != nil {
+			return nil, err
+		}
+		indice := &ExpresionIndice{Rango: j.Rango, Nombre: j.Nombre}
+		for _, h := range j.Children {
+			expr, err := h.expresion(ROL_INDICE)
+			if err != nil {
+				return nil, err
+			}
+			indice.Indice = expr
+		}
+		return indice, nil
+
+	case NODO_DECLARACION_FUNCION:
+		funcion := &DeclaracionFuncion{Rango: j.Rango, Tipo: j.Tipo, Nombre: j.Nombre, Parametros: []*Parametro{}, Cuerpo: []Declaracion{}}
+		for _, h := range j.Children {
+			if h != nil && h.Rol == ROL_PARAMETRO {
+				if h.Kind != NODO_PARAMETRO {
+					return nil, fmt.Errorf("%s: se esperaba %s, se encontró %s", j.Kind, NODO_PARAMETRO, h.Kind)
+				}
+				funcion.Parametros = append(funcion.Parametros, &Parametro{Rango: h.Rango, Tipo: h.Tipo, Nombre: h.Nombre})
+				continue
+			}
+			decl, err := h.declaracion(ROL_CUERPO)
+			if err != nil {
+				return nil, err
+			}
+			funcion.Cuerpo = append(funcion.Cuerpo, decl)
+		}
+		return funcion, nil
+
+	case NODO_PARAMETRO:
+		return &Parametro{Rango: j.Rango, Tipo: j.Tipo, Nombre: j.Nombre}, nil
+
+	case NODO_DECLARACION_RETORNO:
+		if err := j.verificarHijos(ROL_VALOR); err != nil {
+			return nil, err
+		}
+		retorno := &DeclaracionRetorno{Rango: j.Rango}
+		for _, h := range j.Children {
+			valor, err := h.expresion(ROL_VALOR)
+			if err != nil {
+				return nil, err
+			}
+			retorno.Valor = valor
+		}
+		return retorno, nil
+
+	case NODO_DECLARACION_LLAMADA:
+		if err := j.verificarHijos(ROL_LLAMADA); err != nil {
+			return nil, err
+		}
+		decl := &DeclaracionLlamada{Rango: j.Rango}
+		for _, h := range j.Children {
+			expr, err := h.expresion(ROL_LLAMADA)
+			if err != nil {
+				return nil, err
+			}
+			llamada, ok := expr.(*ExpresionLlamada)
+			if !ok {
+				return nil, fmt.Errorf("%s: se esperaba %s, se encontró %s", j.Kind, NODO_EXPRESION_LLAMADA, h.Kind)
+			}
+			decl.Llamada = llamada
+		}
+		return decl, nil
+
+	case NODO_EXPRESION_LLAMADA:
+		llamada := &ExpresionLlamada{Rango: j.Rango, Nombre: j.Nombre, Argumentos: []Expresion{}}
+		for _, h := range j.Children {
+			argumento, err := h.expresion(ROL_ARGUMENTO)
+			if err != nil {
+				return nil, err
+			}
+			llamada.Argumentos = append(llamada.Argumentos, argumento)
+		}
+		return llamada, nil
+
+	case NODO_EXPRESION_IDENTIFICADOR:
+		return &ExpresionIdentificador{Rango: j.Rango, Valor: j.Valor}, nil
+
+	case NODO_EXPRESION_NUMERO:
+		return &ExpresionNumero{Rango: j.Rango, Valor: j.Valor}, nil
+
+	case NODO_EXPRESION_BINARIA:
+		if err := j.verificarHijos(ROL_IZQUIERDA, ROL_DERECHA); err != nil {
+			return nil, err
+		}
+		binaria := &ExpresionBinaria{Rango: j.Rango, Operador: j.Operador}
+		for _, h := range j.Children {
+			rol := ""
+			if h != nil {
+				rol = h.Rol
+			}
+			switch rol {
+			case ROL_IZQUIERDA:
+				expr, err := h.expresion(ROL_IZQUIERDA)
+				if err != nil {
+					return nil, err
+				}
+				binaria.Izquierda = expr
+			case ROL_DERECHA:
+				expr, err := h.expresion(ROL_DERECHA)
+				if err != nil {
+					return nil, err
+				}
+				binaria.Derecha = expr
+			default:
+				return nil, fmt.Errorf("%s: rol de hijo desconocido %q", j.Kind, rol)
+			}
+		}
+		return binaria, nil
+
+	case NODO_EXPRESION_ASIGNACION:
+		if err := j.verificarHijos(ROL_VALOR); err != nil {
+			return nil, err
+		}
+		asignacion := &ExpresionAsignacion{Rango: j.Rango, Nombre: j.Nombre}
+		for _, h := range j.Children {
+			if h != nil && h.Rol == ROL_INDICE {
+				indice, err := h.expresion(ROL_INDICE)
+				if err != nil {
+					return nil, err
+				}
+				asignacion.Indice = indice
+				continue
+			}
+			valor, err := h.expresion(ROL_VALOR)
+			if err != nil {
+				return nil, err
+			}
+			asignacion.Valor = valor
+		}
+		return asignacion, nil
+	}
+	return nil, fmt.Errorf("tipo de nodo desconocido %q", j.Kind)
+}
+
+// declaracion reconstruye un hijo que debe ser una declaración con el rol dado
+func (j *NodoJSON) declaracion(rol string) (Declaracion, error) {
+	if err := j.verificarRol(rol); err != nil {
+		return nil, err
+	}
+	n, err := j.Nodo()
+	if err != nil {
+		return nil, err
+	}
+	decl, ok := n.(Declaracion)
+	if !ok {
+		return nil, fmt.Errorf("%s no es una declaración", j.Kind)
+	}
+	return decl, nil
+}
+
+// expresion reconstruye un hijo que debe ser una expresión con el rol dado
+func (j *NodoJSON) expresion(rol string) (Expresion, error) {
+	if err := j.verificarRol(rol); err != nil {
+		return nil, err
+	}
+	n, err := j.Nodo()
+	if err != nil {
+		return nil, err
+	}
+	expr, ok := n.(Expresion)
+	if !ok {
+		return nil, fmt.Errorf("%s no es una expresión", j.Kind)
+	}
+	return expr, nil
+}
+
+// verificarHijos comprueba que j tenga un hijo con cada uno de los roles
+// requeridos y que no repita los roles de hijos únicos, es decir, todos
+// salvo los de las listas (declaraciones, cuerpo, parámetros, argumentos y
+// elementos)
+func (j *NodoJSON) verificarHijos(requeridos ...string) error {
+	cuenta := map[string]int{}
+	for _, h := range j.Children {
+		if h != nil {
+			cuenta[h.Rol]++
+		}
+	}
+	for _, rol := range requeridos {
+		if cuenta[rol] == 0 {
+			return fmt.Errorf("%s: falta el hijo con rol %q", j.Kind, rol)
+		}
+	}
+	for _, rol := range []string{ROL_VALOR, ROL_ASIGNACION, ROL_CONDICION, ROL_TAMANO, ROL_INDICE, ROL_LLAMADA, ROL_IZQUIERDA, ROL_DERECHA} {
+		if cuenta[rol] > 1 {
+			return fmt.Errorf("%s: más de un hijo con rol %q", j.Kind, rol)
+		}
+	}
+	return nil
+}
+
+func (j *NodoJSON) verificarRol(rol string) error {
+	if j == nil {
+		return fmt.Errorf("hijo nulo con rol %q", rol)
+	}
+	if j.Rol != rol {
+		return fmt.Errorf("%s: se esperaba el rol %q, se encontró %q", j.Kind, rol, j.Rol)
+	}
+	return nil
+}
+
+// DecodificarNodo reconstruye un AST a partir de su representación JSON
+func DecodificarNodo(datos []byte) (Nodo, error) {
+	var j NodoJSON
+	if err := json.Unmarshal(datos, &j); err != nil {
+		return nil, err
+	}
+	return j.Nodo()
+}
+
+// MarshalJSON serializa el programa con el formato de NodoJSON
+func (p *Programa) MarshalJSON() ([]byte, error) {
+	return json.Marshal(CodificarNodo(p))
+}
+
+// UnmarshalJSON reconstruye un programa serializado con MarshalJSON
+func (p *Programa) UnmarshalJSON(datos []byte) error {
+	n, err := DecodificarNodo(datos)
+	if err != nil {
+		return err
+	}
+	programa, ok := n.(*Programa)
+	if !ok {
+		return fmt.Errorf("se esperaba %s", NODO_PROGRAMA)
+	}
+	*p = *programa
+	return nil
+}
+
+// esNil indica si n es nil, incluido un puntero nil envuelto en la interfaz
+func esNil(n Nodo) bool {
+	switch nodo := n.(type) {
+	case nil:
+		return true
+	case *Programa:
+		return nodo == nil
+	case *DeclaracionVariable:
+		return nodo == nil
+	case *DeclaracionAsignacion:
+		return nodo == nil
+	case *DeclaracionDoWhile:
+		return nodo == nil
+	case *ExpresionIdentificador:
+		return nodo == nil
+	case *ExpresionNumero:
+		return nodo == nil
+	case *ExpresionBinaria:
+		return nodo == nil
+	case *ExpresionAsignacion:
+		return nodo == nil
+	case *DeclaracionFuncion:
+		return nodo == nil
+	case *Parametro:
+		return nodo == nil
+	case *DeclaracionRetorno:
+		return nodo == nil
+	case *DeclaracionLlamada:
+		return nodo == nil
+	case *ExpresionLlamada:
+		return nodo == nil
+	case *DeclaracionArreglo:
+		return nodo == nil
+	case *DeclaracionImpresion:
+		return nodo == nil
+	case *DeclaracionLectura:
+		return nodo == nil
+	case *ExpresionIndice:
+		return nodo == nil
+	}
+	return false
+}
@@ -1,35 +1,60 @@
 package parser
 
 import (
-	"fmt"
 	"analyzer-api/internal/lexer"
+	"analyzer-api/internal/traza"
+	"fmt"
 )
 
+// MaximaAnidacion limita las expresiones anidadas en argumentos e índices,
+// como f(f(f(1))). Sin límite, un programa válido podría agotar la pila del
+// analizador o producir un AST demasiado profundo para codificarlo en JSON.
+const MaximaAnidacion = 200
 
+// Parser consume los tokens del lexer bajo demanda, con un solo token de
+// anticipación (peekToken)
 type Parser struct {
-	l         *lexer.Lexer
-	tokens    []lexer.Token
-	position  int
-	curToken  lexer.Token
-	peekToken lexer.Token
-	errors    []ErrorSintactico
+	l           lexer.Fuente
+	position    int // número de tokens consumidos
+	curToken    lexer.Token
+	peekToken   lexer.Token
+	anterior    lexer.Token // último token consumido, para cerrar los rangos
+	errors      []ErrorSintactico
+	traza       traza.Trazador
+	profundidad int             // reglas abiertas, para la traza
+	anidacion   int             // expresiones completas abiertas
+	grupos      int             // paréntesis y corchetes abiertos en la expresión
+	cst         *constructorCST // nil si no se construye el árbol concreto
+}
+
+// Opcion configura un analizador sintáctico al crearlo
+type Opcion func(*Parser)
+
+// ConTraza registra en t la entrada y salida de cada regla, los errores y
+// las recuperaciones
+func ConTraza(t traza.Trazador) Opcion {
+	return func(p *Parser) {
+		if t != nil {
+			p.traza = t
+		}
+	}
 }
 
-// New crea un nuevo analizador sintáctico
-func New(l *lexer.Lexer) *Parser {
+// New crea un nuevo analizador sintáctico que lee los tokens de l, que
+// normalmente es un *lexer.Lexer
+func New(l lexer.Fuente, opciones ...Opcion) *Parser {
 	p := &Parser{
 		l:      l,
-		tokens: l.Analizar(),
 		errors: []ErrorSintactico{},
+		traza:  traza.Nulo(),
+	}
+	for _, opcion := range opciones {
+		opcion(p)
 	}
 
 	// Leer dos tokens para inicializar curToken y peekToken
-	if len(p.tokens) > 0 {
-		p.curToken = p.tokens[0]
-	}
-	if len(p.tokens) > 1 {
-		p.peekToken = p.tokens[1]
-	}
+	p.curToken = p.leerToken()
+	p.peekToken = p.leerToken()
 
 	return p
 }
@@ -41,21 +66,23 @@ func (p *Parser) Errores() []ErrorSintactico {
 
 // nextToken avanza al siguiente token
 func (p *Parser) nextToken() {
-	prevToken := p.curToken
 	p.position++
-	if p.position < len(p.tokens) {
-		p.curToken = p.tokens[p.position]
-	} else {
-		p.curToken = lexer.Token{Type: lexer.TOKEN_EOF, Lexeme: "EOF"}
-	}
-	if p.position+1 < len(p.tokens) {
-		p.peekToken = p.tokens[p.position+1]
-	} else {
-		p.peekToken = lexer.Token{Type: lexer.TOKEN_EOF, Lexeme: "EOF"}
+	p.anterior = p.curToken
+	p.curToken = p.peekToken
+	p.peekToken = p.leerToken()
+}
+
+// leerToken lee el siguiente token de la fuente. Un comentario de bloque sin
+// cerrar abarca el resto de la entrada y el analizador léxico ya lo informa
+// como error, así que el parser lo trata como el fin de la entrada en lugar
+// de informarlo otra vez como una declaración inesperada.
+func (p *Parser) leerToken() lexer.Token {
+	tok := p.l.NextToken()
+	if tok.Type == lexer.TOKEN_ERROR_COMENTARIO {
+		tok.Type = lexer.TOKEN_EOF
+		tok.Lexeme = ""
 	}
-	
-	fmt.Printf("Avanzando de '%s' a '%s', siguiente: '%s'\n", 
-		prevToken.Lexeme, p.curToken.Lexeme, p.peekToken.Lexeme)
+	return tok
 }
 
 // Parse analiza el programa completo
@@ -63,56 +90,115 @@ func (p *Parser) Parse() *Programa {
 	programa := &Programa{
 		Declaraciones: []Declaracion{},
 	}
+	programa.Inicio = inicioToken(p.curToken)
 
-	fmt.Println("Iniciando análisis sintáctico...")
+	defer p.salir(p.entrar(REGLA_PROGRAMA))
 
-	// Contador de seguridad para evitar bucles infinitos
-	maxIteraciones := len(p.tokens) * 2 // Máximo 2 veces el número de tokens
+	// Contador de seguridad para evitar bucles infinitos: cada iteración
+	// consume al menos un token, así que nunca debería superar el doble
+	// de los tokens consumidos
 	iteraciones := 0
 
-	for p.curToken.Type != lexer.TOKEN_EOF && iteraciones < maxIteraciones {
+	// declaraciones → declaracion declaraciones | ε
+	for !p.Terminado() && iteraciones < 2*(p.position+1) {
 		iteraciones++
-		fmt.Printf("Procesando token: '%s' (%s) en línea %d, columna %d [iter: %d]\n", 
-			p.curToken.Lexeme, p.curToken.Type, p.curToken.Line, p.curToken.Column, iteraciones)
-		
-		// Guardar posición actual para detectar bucles infinitos
-		posicionAnterior := p.position
-		
-		decl := p.parseDeclaracion()
+		p.abrir(REGLA_DECLARACIONES)
+
+		decl := p.ParseDeclaracion()
 		if decl != nil {
 			programa.Declaraciones = append(programa.Declaraciones, decl)
-			fmt.Printf("Declaración agregada exitosamente\n")
-		}
-		
-		// CRÍTICO: Si no avanzamos, forzar avance para evitar bucle infinito
-		if p.position == posicionAnterior && p.curToken.Type != lexer.TOKEN_EOF {
-			fmt.Printf("RECOVERY: Forzando avance del token '%s' para evitar bucle infinito\n", p.curToken.Lexeme)
-			p.nextToken()
 		}
 	}
+	p.abrir(REGLA_DECLARACIONES)
+	p.cerrar(iteraciones + 1)
 
-	if iteraciones >= maxIteraciones {
+	if p.curToken.Type != lexer.TOKEN_EOF {
 		p.agregarError("Análisis interrumpido: demasiadas iteraciones (posible bucle infinito)")
-		fmt.Println("ERROR: Análisis interrumpido por exceso de iteraciones - posible bucle infinito")
 	}
 
-	fmt.Println("Análisis sintáctico completado.")
+	programa.Fin = inicioToken(p.curToken)
+
+	// El árbol de un programa correcto solo puede usar producciones de la
+	// gramática; si no, el parser y Gramatica se separaron. Es un error del
+	// analizador y no del programa, pero se informa como error para no
+	// entregar un árbol concreto inconsistente.
+	if p.cst != nil && len(p.errors) == 0 {
+		if err := VerificarArbol(p.cst.raiz); err != nil {
+			p.agregarError("Error interno del analizador: " + err.Error())
+			p.cst = nil
+		}
+	}
 	return programa
 }
 
+// ParseDeclaracion analiza la siguiente declaración de nivel superior y
+// garantiza que se consuma al menos un token. Devuelve nil si la declaración
+// tenía errores.
+func (p *Parser) ParseDeclaracion() Declaracion {
+	defer p.salir(p.entrar(REGLA_DECLARACION))
+
+	// Guardar posición actual para detectar bucles infinitos
+	posicionAnterior := p.position
+
+	decl := p.parseDeclaracion()
+
+	// CRÍTICO: Si no avanzamos, forzar avance para evitar bucle infinito
+	if p.position == posicionAnterior && p.curToken.Type != lexer.TOKEN_EOF {
+		p.recuperado(fmt.Sprintf("Forzando avance del token '%s' para evitar bucle infinito", p.curToken.Lexeme))
+		p.nextToken()
+	}
+	return decl
+}
+
+// Terminado indica si el parser llegó al final de los tokens
+func (p *Parser) Terminado() bool {
+	return p.curToken.Type == lexer.TOKEN_EOF
+}
+
+// Consumidos devuelve el número de tokens consumidos hasta ahora
+func (p *Parser) Consumidos() int {
+	return p.position
+}
+
 // parseDeclaracion analiza una declaración
 func (p *Parser) parseDeclaracion() Declaracion {
-	switch p.curToken.Lexeme {
-	case "int":
-		return p.parseDeclaracionVariable()
-	case "do":
-		return p.parseDoWhile()
+	switch p.curToken.Type {
+	case lexer.TOKEN_INT:
+		// Evitar devolver un puntero nil envuelto en la interfaz
+		if decl := p.parseDeclaracionEntera(); decl != nil {
+			return decl
+		}
+		return nil
+	case lexer.TOKEN_CONST:
+		if decl := p.parseDeclaracionConstante(); decl != nil {
+			return decl
+		}
+		return nil
+	case lexer.TOKEN_DO:
+		if decl := p.parseDoWhile(); decl != nil {
+			return decl
+		}
+		return nil
+	case lexer.TOKEN_RETURN:
+		if decl := p.parseRetorno(); decl != nil {
+			return decl
+		}
+		return nil
+	case lexer.TOKEN_PRINT:
+		if decl := p.parseImpresion(); decl != nil {
+			return decl
+		}
+		return nil
+	case lexer.TOKEN_READ:
+		if decl := p.parseLectura(); decl != nil {
+			return decl
+		}
+		return nil
 	default:
-		if p.curToken.Type == lexer.TOKEN_IDENT && p.peekTokenIs(lexer.TOKEN_ASSIGN) {
-			return p.parseDeclaracionAsignacion()
+		if p.curToken.Type == lexer.TOKEN_IDENT && p.comienzaSentenciaIdentificador() {
+			return p.parseSentenciaIdentificador()
 		} else {
 			p.agregarError(fmt.Sprintf("Declaración inesperada con token %s", p.curToken.Lexeme))
-			fmt.Printf("ERROR: Token inesperado '%s' - saltando\n", p.curToken.Lexeme)
 			// SIEMPRE avanzar en caso de error para evitar bucle infinito
 			p.nextToken()
 			return nil
@@ -120,19 +206,98 @@ func (p *Parser) parseDeclaracion() Declaracion {
 	}
 }
 
-// parseDeclaracionVariable analiza una declaración de variable (int a = 0;)
-func (p *Parser) parseDeclaracionVariable() *DeclaracionVariable {
-	fmt.Printf("Analizando declaración de variable, tipo: '%s'\n", p.curToken.Lexeme)
-	
-	decl := &DeclaracionVariable{Tipo: p.curToken.Lexeme}
+// parseDeclaracionEntera analiza lo que comienza con int IDENT: una
+// declaración de variable o la definición de una función
+func (p *Parser) parseDeclaracionEntera() Declaracion {
+	defer p.salir(p.entrar(REGLA_DECLARACION_ENTERA))
+
+	p.hoja()
+	tipo := p.curToken.Lexeme
+	inicio := inicioToken(p.curToken)
+
+	// Siguiente debe ser identificador
+	if !p.expectPeek(lexer.TOKEN_IDENT) {
+		return nil
+	}
+	nombre := p.curToken.Lexeme
+
+	p.abrir(REGLA_RESTO_DECLARACION)
+	defer p.cerrar(1)
+	if p.peekTokenIs(lexer.TOKEN_LPAREN) {
+		if decl := p.parseDeclaracionFuncion(tipo, nombre, inicio); decl != nil {
+			return decl
+		}
+		return nil
+	}
+	if p.peekTokenIs(lexer.TOKEN_LBRACKET) {
+		if decl := p.parseDeclaracionArreglo(tipo, nombre, inicio); decl != nil {
+			return decl
+		}
+		return nil
+	}
+	if decl := p.parseDeclaracionVariable(tipo, nombre, inicio); decl != nil {
+		return decl
+	}
+	return nil
+}
+
+// parseDeclaracionVariable analiza el resto de una declaración de variable
+// (int a = b + 1;) con el token actual en el nombre
+func (p *Parser) parseDeclaracionVariable(tipo, nombre string, inicio Posicion) *DeclaracionVariable {
+	defer p.salir(p.entrar(REGLA_DECLARACION_VARIABLE))
+
+	decl := &DeclaracionVariable{Tipo: tipo, Nombre: nombre}
+	decl.Inicio = inicio
+
+	// Siguiente debe ser =
+	if !p.expectPeek(lexer.TOKEN_ASSIGN) {
+		return nil
+	}
+
+	// Siguiente debe ser el valor
+	if !p.nextTokenExists() {
+		p.agregarError("Se esperaba un valor después de '='")
+		return nil
+	}
+	p.nextToken()
+
+	decl.Valor = p.parseExpresionCompleta()
+	if decl.Valor == nil {
+		return nil
+	}
+
+	// Debe terminar con ;
+	if !p.expectPeek(lexer.TOKEN_SEMI) {
+		return nil
+	}
+
+	// Avanzar después del ;
+	p.nextToken()
+	decl.Fin = finToken(p.anterior)
+
+	return decl
+}
+
+// parseDeclaracionConstante analiza una declaración de constante
+// (const int N = 10 * 2;)
+func (p *Parser) parseDeclaracionConstante() *DeclaracionVariable {
+	defer p.salir(p.entrar(REGLA_DECLARACION_CONSTANTE))
+
+	p.hoja()
+	decl := &DeclaracionVariable{Constante: true}
+	decl.Inicio = inicioToken(p.curToken)
+
+	// Siguiente debe ser el tipo
+	if !p.expectPeek(lexer.TOKEN_INT) {
+		return nil
+	}
+	decl.Tipo = p.curToken.Lexeme
 
 	// Siguiente debe ser identificador
 	if !p.expectPeek(lexer.TOKEN_IDENT) {
 		return nil
 	}
-	
 	decl.Nombre = p.curToken.Lexeme
-	fmt.Printf("Nombre de la variable: '%s'\n", decl.Nombre)
 
 	// Siguiente debe ser =
 	if !p.expectPeek(lexer.TOKEN_ASSIGN) {
@@ -145,9 +310,8 @@ func (p *Parser) parseDeclaracionVariable() *DeclaracionVariable {
 		return nil
 	}
 	p.nextToken()
-	
-	fmt.Printf("Analizando valor de la variable, token: '%s'\n", p.curToken.Lexeme)
-	decl.Valor = p.parseExpresionSimple()
+
+	decl.Valor = p.parseExpresionCompleta()
 	if decl.Valor == nil {
 		return nil
 	}
@@ -159,35 +323,480 @@ func (p *Parser) parseDeclaracionVariable() *DeclaracionVariable {
 
 	// Avanzar después del ;
 	p.nextToken()
-	
-	fmt.Println("Declaración de variable analizada correctamente")
+	decl.Fin = finToken(p.anterior)
+
+	return decl
+}
+
+// parseDeclaracionArreglo analiza el resto de la declaración de un arreglo
+// (int v[3] = {1, 2, 3};) con el token actual en el nombre
+func (p *Parser) parseDeclaracionArreglo(tipo, nombre string, inicio Posicion) *DeclaracionArreglo {
+	defer p.salir(p.entrar(REGLA_DECLARACION_ARREGLO))
+
+	decl := &DeclaracionArreglo{Tipo: tipo, Nombre: nombre}
+	decl.Inicio = inicio
+
+	if !p.expectPeek(lexer.TOKEN_LBRACKET) {
+		return nil
+	}
+	// El tamaño debe ser un número
+	if !p.expectPeek(lexer.TOKEN_NUMBER) {
+		return nil
+	}
+	decl.Tamano = &ExpresionNumero{
+		Rango: Rango{Inicio: inicioToken(p.curToken), Fin: finToken(p.curToken)},
+		Valor: p.curToken.Lexeme,
+	}
+	if !p.expectPeek(lexer.TOKEN_RBRACKET) {
+		return nil
+	}
+
+	elementos, ok := p.parseInicializacionArreglo()
+	if !ok {
+		return nil
+	}
+	decl.Elementos = elementos
+
+	// Debe terminar con ;
+	if !p.expectPeek(lexer.TOKEN_SEMI) {
+		return nil
+	}
+
+	// Avanzar después del ;
+	p.nextToken()
+	decl.Fin = finToken(p.anterior)
+
 	return decl
 }
 
+// parseInicializacionArreglo analiza los valores iniciales de un arreglo
+// (= {1, 2, 3}) si el siguiente token es '='. Termina en '}'; devuelve nil
+// si no hay valores iniciales y ok es false si hubo errores.
+func (p *Parser) parseInicializacionArreglo() (elementos []Expresion, ok bool) {
+	defer p.salir(p.entrar(REGLA_INICIALIZACION_ARREGLO))
+
+	if !p.peekTokenIs(lexer.TOKEN_ASSIGN) {
+		return nil, true
+	}
+	p.nextToken()
+	p.hoja()
+	if !p.expectPeek(lexer.TOKEN_LBRACE) {
+		return nil, false
+	}
+
+	// masElementos → , expresionSimple masElementos | ε
+	elementos = []Expresion{}
+	abiertos := 0
+	for {
+		if !p.nextTokenExists() {
+			p.agregarError("Se esperaba un valor en la inicialización del arreglo")
+			p.cerrar(abiertos)
+			return nil, false
+		}
+		p.nextToken()
+		elemento := p.parseExpresionSimple()
+		if elemento == nil {
+			p.cerrar(abiertos)
+			return nil, false
+		}
+		elementos = append(elementos, elemento)
+
+		p.abrir(REGLA_MAS_ELEMENTOS)
+		abiertos++
+		if !p.peekTokenIs(lexer.TOKEN_COMMA) {
+			break
+		}
+		p.nextToken()
+		p.hoja()
+	}
+	p.cerrar(abiertos)
+
+	if !p.expectPeek(lexer.TOKEN_RBRACE) {
+		return nil, false
+	}
+	return elementos, true
+}
+
+// parseDeclaracionFuncion analiza el resto de la definición de una función
+// (int suma(int a, int b) { ... }) con el token actual en el nombre
+func (p *Parser) parseDeclaracionFuncion(tipo, nombre string, inicio Posicion) *DeclaracionFuncion {
+	defer p.salir(p.entrar(REGLA_DECLARACION_FUNCION))
+
+	funcion := &DeclaracionFuncion{Tipo: tipo, Nombre: nombre, Cuerpo: []Declaracion{}}
+	funcion.Inicio = inicio
+
+	if !p.expectPeek(lexer.TOKEN_LPAREN) {
+		return nil
+	}
+	parametros, ok := p.parseParametros()
+	if !ok {
+		return nil
+	}
+	funcion.Parametros = parametros
+	if !p.expectPeek(lexer.TOKEN_RPAREN) {
+		return nil
+	}
+	if !p.expectPeek(lexer.TOKEN_LBRACE) {
+		return nil
+	}
+
+	// Avanzar después de {
+	p.nextToken()
+
+	// cuerpoFuncion → declaracion cuerpoFuncion | ε
+	p.abrir(REGLA_CUERPO_FUNCION)
+	abiertos := 1
+	for p.curToken.Type != lexer.TOKEN_RBRACE && p.curToken.Type != lexer.TOKEN_EOF {
+		decl := p.ParseDeclaracion()
+		p.abrir(REGLA_CUERPO_FUNCION)
+		abiertos++
+		if decl != nil {
+			funcion.Cuerpo = append(funcion.Cuerpo, decl)
+		}
+	}
+	p.cerrar(abiertos)
+
+	if p.curToken.Type != lexer.TOKEN_RBRACE {
+		p.agregarError("Se esperaba '}' al final de la función")
+		return nil
+	}
+	p.hoja()
+
+	// Avanzar después de }
+	p.nextToken()
+	funcion.Fin = finToken(p.anterior)
+
+	return funcion
+}
+
+// parseParametros analiza la lista de parámetros con el token actual en '('.
+// Termina en el último token del último parámetro; ok es false si hubo errores.
+func (p *Parser) parseParametros() (parametros []*Parametro, ok bool) {
+	defer p.salir(p.entrar(REGLA_PARAMETROS))
+
+	parametros = []*Parametro{}
+	if p.peekTokenIs(lexer.TOKEN_RPAREN) {
+		return parametros, true
+	}
+
+	// masParametros → , parametro masParametros | ε
+	abiertos := 0
+	defer func() { p.cerrar(abiertos) }()
+	for {
+		if !p.peekTokenIs(lexer.TOKEN_INT) {
+			p.peekError(lexer.TOKEN_INT)
+			return nil, false
+		}
+		p.nextToken()
+		parametro := p.parseParametro()
+		if parametro == nil {
+			return nil, false
+		}
+		parametros = append(parametros, parametro)
+
+		p.abrir(REGLA_MAS_PARAMETROS)
+		abiertos++
+		if !p.peekTokenIs(lexer.TOKEN_COMMA) {
+			return parametros, true
+		}
+		p.nextToken()
+		p.hoja()
+	}
+}
+
+// parseParametro analiza un parámetro (int a)
+func (p *Parser) parseParametro() *Parametro {
+	defer p.salir(p.entrar(REGLA_PARAMETRO))
+
+	p.hoja()
+	parametro := &Parametro{Tipo: p.curToken.Lexeme}
+	parametro.Inicio = inicioToken(p.curToken)
+	if !p.expectPeek(lexer.TOKEN_IDENT) {
+		return nil
+	}
+	parametro.Nombre = p.curToken.Lexeme
+	parametro.Fin = finToken(p.curToken)
+	return parametro
+}
+
+// parseRetorno analiza la devolución de un valor (return a + 1;)
+func (p *Parser) parseRetorno() *DeclaracionRetorno {
+	defer p.salir(p.entrar(REGLA_RETORNO))
+
+	p.hoja()
+	retorno := &DeclaracionRetorno{}
+	retorno.Inicio = inicioToken(p.curToken)
+
+	// Siguiente debe ser el valor
+	if !p.nextTokenExists() {
+		p.agregarError("Se esperaba un valor después de 'return'")
+		return nil
+	}
+	p.nextToken()
+
+	retorno.Valor = p.parseExpresionCompleta()
+	if retorno.Valor == nil {
+		return nil
+	}
+
+	// Debe terminar con ;
+	if !p.expectPeek(lexer.TOKEN_SEMI) {
+		return nil
+	}
+
+	// Avanzar después del ;
+	p.nextToken()
+	retorno.Fin = finToken(p.anterior)
+
+	return retorno
+}
+
+// comienzaSentenciaIdentificador indica si el token siguiente al
+// identificador actual comienza una asignación o una llamada
+func (p *Parser) comienzaSentenciaIdentificador() bool {
+	return p.peekTokenIs(lexer.TOKEN_ASSIGN) || p.peekTokenIs(lexer.TOKEN_LBRACKET) || p.peekTokenIs(lexer.TOKEN_LPAREN)
+}
+
+// comienzaSentencia indica si el token actual comienza una sentencia del
+// cuerpo de un do-while
+func (p *Parser) comienzaSentencia() bool {
+	switch p.curToken.Type {
+	case lexer.TOKEN_IDENT:
+		return p.comienzaSentenciaIdentificador()
+	case lexer.TOKEN_PRINT, lexer.TOKEN_READ:
+		return true
+	}
+	return false
+}
+
+// parseSentencia analiza una sentencia del cuerpo de un do-while
+func (p *Parser) parseSentencia() Declaracion {
+	defer p.salir(p.entrar(REGLA_SENTENCIA))
+
+	// Evitar devolver un puntero nil envuelto en la interfaz
+	switch p.curToken.Type {
+	case lexer.TOKEN_PRINT:
+		if decl := p.parseImpresion(); decl != nil {
+			return decl
+		}
+	case lexer.TOKEN_READ:
+		if decl := p.parseLectura(); decl != nil {
+			return decl
+		}
+	default:
+		return p.parseSentenciaIdentificador()
+	}
+	return nil
+}
+
+// parseImpresion analiza la escritura de un valor (print(a + 1);)
+func (p *Parser) parseImpresion() *DeclaracionImpresion {
+	defer p.salir(p.entrar(REGLA_IMPRESION))
+
+	p.hoja()
+	impresion := &DeclaracionImpresion{}
+	impresion.Inicio = inicioToken(p.curToken)
+
+	if !p.expectPeek(lexer.TOKEN_LPAREN) {
+		return nil
+	}
+	// Siguiente debe ser el valor
+	if !p.nextTokenExists() {
+		p.agregarError("Se esperaba un valor después de '('")
+		return nil
+	}
+	p.nextToken()
+
+	impresion.Valor = p.parseExpresionCompleta()
+	if impresion.Valor == nil {
+		return nil
+	}
+	if !p.expectPeek(lexer.TOKEN_RPAREN) {
+		return nil
+	}
+
+	// Debe terminar con ;
+	if !p.expectPeek(lexer.TOKEN_SEMI) {
+		return nil
+	}
+
+	// Avanzar después del ;
+	p.nextToken()
+	impresion.Fin = finToken(p.anterior)
+
+	return impresion
+}
+
+// parseLectura analiza la lectura de un entero (read(a); o read(v[i]);)
+func (p *Parser) parseLectura() *DeclaracionLectura {
+	defer p.salir(p.entrar(REGLA_LECTURA))
+
+	p.hoja()
+	lectura := &DeclaracionLectura{}
+	lectura.Inicio = inicioToken(p.curToken)
+
+	if !p.expectPeek(lexer.TOKEN_LPAREN) {
+		return nil
+	}
+	// Siguiente debe ser la variable
+	if !p.expectPeek(lexer.TOKEN_IDENT) {
+		return nil
+	}
+	lectura.Nombre = p.curToken.Lexeme
+
+	indice, ok := p.parseIndice()
+	if !ok {
+		return nil
+	}
+	lectura.Indice = indice
+
+	if !p.expectPeek(lexer.TOKEN_RPAREN) {
+		return nil
+	}
+
+	// Debe terminar con ;
+	if !p.expectPeek(lexer.TOKEN_SEMI) {
+		return nil
+	}
+
+	// Avanzar después del ;
+	p.nextToken()
+	lectura.Fin = finToken(p.anterior)
+
+	return lectura
+}
+
+// parseSentenciaIdentificador analiza lo que comienza con un identificador:
+// una asignación (a = 5; o v[i] = 5;) o una llamada (f(a);). El siguiente
+// token debe ser '=', '[' o '('.
+func (p *Parser) parseSentenciaIdentificador() Declaracion {
+	defer p.salir(p.entrar(REGLA_SENTENCIA_IDENTIFICADOR))
+
+	nombre := p.curToken.Lexeme
+	inicio := inicioToken(p.curToken)
+	p.hoja()
+
+	p.abrir(REGLA_RESTO_SENTENCIA)
+	defer p.cerrar(1)
+	if p.peekTokenIs(lexer.TOKEN_LPAREN) {
+		if decl := p.parseSentenciaLlamada(nombre, inicio); decl != nil {
+			return decl
+		}
+		return nil
+	}
+	if decl := p.parseDeclaracionAsignacion(nombre, inicio); decl != nil {
+		return decl
+	}
+	return nil
+}
+
+// parseSentenciaLlamada analiza una llamada usada como declaración (f(a);)
+// con el token actual en el nombre de la función
+func (p *Parser) parseSentenciaLlamada(nombre string, inicio Posicion) *DeclaracionLlamada {
+	defer p.salir(p.entrar(REGLA_SENTENCIA_LLAMADA))
+
+	llamada := p.parseLlamada(nombre, inicio)
+	if llamada == nil {
+		return nil
+	}
+
+	// Debe terminar con ;
+	if !p.expectPeek(lexer.TOKEN_SEMI) {
+		return nil
+	}
+
+	// Avanzar después del ;
+	p.nextToken()
+
+	return &DeclaracionLlamada{
+		Rango:   Rango{Inicio: inicio, Fin: finToken(p.anterior)},
+		Llamada: llamada,
+	}
+}
+
+// parseLlamada analiza los argumentos de una llamada entre paréntesis con el
+// token actual en el nombre de la función, y termina en ')'
+func (p *Parser) parseLlamada(nombre string, inicio Posicion) *ExpresionLlamada {
+	if !p.expectPeek(lexer.TOKEN_LPAREN) {
+		return nil
+	}
+	p.grupos++
+	defer func() { p.grupos-- }()
+	argumentos, ok := p.parseArgumentos()
+	if !ok {
+		return nil
+	}
+	if !p.expectPeek(lexer.TOKEN_RPAREN) {
+		return nil
+	}
+	return &ExpresionLlamada{
+		Rango:      Rango{Inicio: inicio, Fin: finToken(p.curToken)},
+		Nombre:     nombre,
+		Argumentos: argumentos,
+	}
+}
+
+// parseArgumentos analiza los argumentos de una llamada con el token actual
+// en '('. Termina en el último token del último argumento; ok es false si
+// hubo errores.
+func (p *Parser) parseArgumentos() (argumentos []Expresion, ok bool) {
+	defer p.salir(p.entrar(REGLA_ARGUMENTOS))
+
+	argumentos = []Expresion{}
+	if p.peekTokenIs(lexer.TOKEN_RPAREN) {
+		return argumentos, true
+	}
+
+	// masArgumentos → , expresionCompleta masArgumentos | ε
+	abiertos := 0
+	defer func() { p.cerrar(abiertos) }()
+	for {
+		if !p.nextTokenExists() {
+			p.agregarError("Se esperaba un argumento")
+			return nil, false
+		}
+		p.nextToken()
+		argumento := p.parseExpresionCompleta()
+		if argumento == nil {
+			return nil, false
+		}
+		argumentos = append(argumentos, argumento)
+
+		p.abrir(REGLA_MAS_ARGUMENTOS)
+		abiertos++
+		if !p.peekTokenIs(lexer.TOKEN_COMMA) {
+			return argumentos, true
+		}
+		p.nextToken()
+		p.hoja()
+	}
+}
+
 // parseDoWhile analiza una estructura do-while
 func (p *Parser) parseDoWhile() *DeclaracionDoWhile {
-	fmt.Println("Iniciando análisis de do-while")
+	defer p.salir(p.entrar(REGLA_DO_WHILE))
 	dowhile := &DeclaracionDoWhile{
 		Cuerpo: []Declaracion{},
 	}
+	dowhile.Inicio = inicioToken(p.curToken)
+	p.hoja()
+	abiertos := 0 // no terminales cuerpo abiertos en el árbol concreto
 
 	// Siguiente debe ser {
 	if !p.expectPeek(lexer.TOKEN_LBRACE) {
 		p.agregarError("Se esperaba '{' después de 'do'")
-		fmt.Println("ERROR: Falta '{' después de 'do' - abortando do-while")
-		
+
 		// RECOVERY: Buscar hasta encontrar 'while' o final
 		for p.curToken.Type != lexer.TOKEN_WHILE && p.curToken.Type != lexer.TOKEN_EOF {
-			fmt.Printf("RECOVERY: Saltando token '%s'\n", p.curToken.Lexeme)
 			p.nextToken()
 		}
-		
+
 		if p.curToken.Type == lexer.TOKEN_EOF {
-			fmt.Println("RECOVERY: Llegó al final sin encontrar 'while'")
+			p.recuperado("Se llegó al final sin encontrar 'while'")
 			return nil
 		}
-		
-		fmt.Println("RECOVERY: Encontrado 'while', intentando continuar análisis...")
+
+		p.recuperado("Encontrado 'while', se continúa con la condición")
 		// Continuar con el análisis del while desde aquí
 		goto parseWhileCondition
 	}
@@ -196,26 +805,38 @@ func (p *Parser) parseDoWhile() *DeclaracionDoWhile {
 	p.nextToken()
 
 	// Analizar el cuerpo del do-while hasta encontrar }
+	// cuerpo → sentencia cuerpo | ε
+	p.abrir(REGLA_CUERPO)
+	abiertos++
 	for p.curToken.Type != lexer.TOKEN_RBRACE && p.curToken.Type != lexer.TOKEN_EOF {
-		fmt.Printf("Analizando token en cuerpo do-while: '%s' (%s)\n", p.curToken.Lexeme, p.curToken.Type)
-		
-		if p.curToken.Type == lexer.TOKEN_IDENT && p.peekTokenIs(lexer.TOKEN_ASSIGN) {
-			fmt.Printf("Encontrada asignación a '%s' dentro del do-while\n", p.curToken.Lexeme)
-			asignacion := p.parseDeclaracionAsignacion()
-			if asignacion != nil {
-				dowhile.Cuerpo = append(dowhile.Cuerpo, asignacion)
-				fmt.Println("Asignación agregada al cuerpo del do-while")
+
+		if p.comienzaSentencia() {
+			posicionAnterior := p.position
+			sentencia := p.parseSentencia()
+			p.abrir(REGLA_CUERPO)
+			abiertos++
+			if sentencia != nil {
+				dowhile.Cuerpo = append(dowhile.Cuerpo, sentencia)
+			}
+			// Si la sentencia inválida no consumió tokens, forzar avance
+			// para evitar un bucle infinito
+			if p.position == posicionAnterior && p.curToken.Type != lexer.TOKEN_EOF {
+				p.recuperado(fmt.Sprintf("Forzando avance del token '%s' para evitar bucle infinito", p.curToken.Lexeme))
+				p.nextToken()
 			}
 		} else {
-			p.agregarError(fmt.Sprintf("Se esperaba una asignación en el cuerpo del do-while, se encontró '%s'", p.curToken.Lexeme))
+			p.agregarError(fmt.Sprintf("Se esperaba una asignación, una llamada, print o read en el cuerpo del do-while, se encontró '%s'", p.curToken.Lexeme))
 			p.nextToken()
 		}
 	}
 
+	p.cerrar(abiertos)
+
 	if p.curToken.Type != lexer.TOKEN_RBRACE {
 		p.agregarError("Se esperaba '}' al final del cuerpo do-while")
 		return nil
 	}
+	p.hoja()
 
 	// Siguiente debe ser while
 	if !p.expectPeek(lexer.TOKEN_WHILE) {
@@ -235,12 +856,11 @@ parseWhileCondition:
 		return nil
 	}
 	p.nextToken()
-	
-	fmt.Printf("Analizando condición del while, token actual: '%s'\n", p.curToken.Lexeme)
+
 	dowhile.Condicion = p.parseExpresionComparacion()
 	if dowhile.Condicion == nil {
 		// RECOVERY: Si falla la condición, buscar hasta ) y ;
-		fmt.Println("RECOVERY: Error en condición, buscando ')' y ';'")
+		p.recuperado("Error en la condición, se descarta hasta ')' y ';'")
 		for p.curToken.Type != lexer.TOKEN_RPAREN && p.curToken.Type != lexer.TOKEN_EOF {
 			p.nextToken()
 		}
@@ -265,17 +885,21 @@ parseWhileCondition:
 
 	// Avanzar después del ;
 	p.nextToken()
+	dowhile.Fin = finToken(p.anterior)
 
-	fmt.Println("Análisis de do-while completado con éxito")
 	return dowhile
 }
 
-// parseDeclaracionAsignacion analiza una asignación (a = 5;)
-func (p *Parser) parseDeclaracionAsignacion() Declaracion {
-	fmt.Printf("Parseando asignación para variable: '%s'\n", p.curToken.Lexeme)
-	
-	nombre := p.curToken.Lexeme
-	
+// parseDeclaracionAsignacion analiza el resto de una asignación (a = 5;) con
+// el token actual en el nombre de la variable
+func (p *Parser) parseDeclaracionAsignacion(nombre string, inicio Posicion) *DeclaracionAsignacion {
+	defer p.salir(p.entrar(REGLA_ASIGNACION))
+
+	indice, ok := p.parseIndice()
+	if !ok {
+		return nil
+	}
+
 	// Siguiente debe ser =
 	if !p.expectPeek(lexer.TOKEN_ASSIGN) {
 		return nil
@@ -287,11 +911,12 @@ func (p *Parser) parseDeclaracionAsignacion() Declaracion {
 		return nil
 	}
 	p.nextToken()
-	
+
 	valor := p.parseExpresionCompleta()
 	if valor == nil {
 		return nil
 	}
+	finValor := finToken(p.curToken)
 
 	// Debe terminar con ;
 	if !p.expectPeek(lexer.TOKEN_SEMI) {
@@ -302,24 +927,85 @@ func (p *Parser) parseDeclaracionAsignacion() Declaracion {
 	p.nextToken()
 
 	return &DeclaracionAsignacion{
+		Rango: Rango{Inicio: inicio, Fin: finToken(p.anterior)},
 		Asignacion: &ExpresionAsignacion{
+			Rango:  Rango{Inicio: inicio, Fin: finValor},
 			Nombre: nombre,
+			Indice: indice,
 			Valor:  valor,
 		},
 	}
 }
 
-// parseExpresionSimple analiza una expresión simple (número o identificador sin operadores)
+// parseIndice analiza el índice del elemento asignado o leído ([i + 1]) si el
+// siguiente token es '['. Termina en ']'; devuelve nil si no hay índice y ok
+// es false si hubo errores.
+func (p *Parser) parseIndice() (indice Expresion, ok bool) {
+	defer p.salir(p.entrar(REGLA_INDICE))
+
+	if !p.peekTokenIs(lexer.TOKEN_LBRACKET) {
+		return nil, true
+	}
+	indice = p.parseCorchetes()
+	return indice, indice != nil
+}
+
+// parseCorchetes analiza un índice entre corchetes con el siguiente token en
+// '[' y termina en ']'
+func (p *Parser) parseCorchetes() Expresion {
+	p.nextToken()
+	p.hoja()
+	p.grupos++
+	defer func() { p.grupos-- }()
+	if !p.nextTokenExists() {
+		p.agregarError("Se esperaba un índice después de '['")
+		return nil
+	}
+	p.nextToken()
+	indice := p.parseExpresionCompleta()
+	if indice == nil {
+		return nil
+	}
+	if !p.expectPeek(lexer.TOKEN_RBRACKET) {
+		return nil
+	}
+	return indice
+}
+
+// parseExpresionSimple analiza una expresión simple (número, identificador,
+// llamada o elemento de un arreglo, sin operadores)
 func (p *Parser) parseExpresionSimple() Expresion {
-	fmt.Printf("Analizando expresión simple, token actual: '%s'\n", p.curToken.Lexeme)
+	defer p.salir(p.entrar(REGLA_EXPRESION_SIMPLE))
 
+	rango := Rango{Inicio: inicioToken(p.curToken), Fin: finToken(p.curToken)}
 	switch p.curToken.Type {
 	case lexer.TOKEN_IDENT:
-		fmt.Printf("Expresión simple es identificador: '%s'\n", p.curToken.Lexeme)
-		return &ExpresionIdentificador{Valor: p.curToken.Lexeme}
+		p.hoja()
+		p.abrir(REGLA_RESTO_IDENTIFICADOR)
+		defer p.cerrar(1)
+		if p.peekTokenIs(lexer.TOKEN_LPAREN) {
+			// Evitar devolver un puntero nil envuelto en la interfaz
+			if llamada := p.parseLlamada(p.curToken.Lexeme, rango.Inicio); llamada != nil {
+				return llamada
+			}
+			return nil
+		}
+		if p.peekTokenIs(lexer.TOKEN_LBRACKET) {
+			nombre := p.curToken.Lexeme
+			indice := p.parseCorchetes()
+			if indice == nil {
+				return nil
+			}
+			return &ExpresionIndice{
+				Rango:  Rango{Inicio: rango.Inicio, Fin: finToken(p.curToken)},
+				Nombre: nombre,
+				Indice: indice,
+			}
+		}
+		return &ExpresionIdentificador{Rango: rango, Valor: p.curToken.Lexeme}
 	case lexer.TOKEN_NUMBER:
-		fmt.Printf("Expresión simple es número: '%s'\n", p.curToken.Lexeme)
-		return &ExpresionNumero{Valor: p.curToken.Lexeme}
+		p.hoja()
+		return &ExpresionNumero{Rango: rango, Valor: p.curToken.Lexeme}
 	default:
 		p.agregarError(fmt.Sprintf("Token inesperado en expresión simple: %s", p.curToken.Lexeme))
 		return nil
@@ -328,7 +1014,15 @@ func (p *Parser) parseExpresionSimple() Expresion {
 
 // parseExpresionCompleta analiza una expresión que puede incluir operadores aritméticos
 func (p *Parser) parseExpresionCompleta() Expresion {
-	fmt.Printf("Analizando expresión completa, token actual: '%s'\n", p.curToken.Lexeme)
+	defer p.salir(p.entrar(REGLA_EXPRESION_COMPLETA))
+
+	if p.anidacion >= MaximaAnidacion {
+		p.agregarError(fmt.Sprintf("Expresión demasiado anidada: más de %d niveles", MaximaAnidacion))
+		p.descartarGrupos()
+		return nil
+	}
+	p.anidacion++
+	defer func() { p.anidacion-- }()
 
 	// Obtener el lado izquierdo
 	izquierda := p.parseExpresionSimple()
@@ -336,26 +1030,29 @@ func (p *Parser) parseExpresionCompleta() Expresion {
 		return nil
 	}
 
-	// Verificar si hay un operador aritmético (* o +)
-	if p.peekTokenIs(lexer.TOKEN_MULT) || p.peekTokenIs(lexer.TOKEN_PLUS) {
-		fmt.Printf("Encontrado operador aritmético: '%s'\n", p.peekToken.Lexeme)
-		
+	// Verificar si hay un operador aritmético (+, *, / o %)
+	p.abrir(REGLA_OPERACION)
+	defer p.cerrar(1)
+	if p.peekTokenIs(lexer.TOKEN_MULT) || p.peekTokenIs(lexer.TOKEN_PLUS) ||
+		p.peekTokenIs(lexer.TOKEN_DIV) || p.peekTokenIs(lexer.TOKEN_MOD) {
+
 		p.nextToken() // Avanzar al operador
+		p.hoja()
 		operador := p.curToken.Lexeme
-		
+
 		if !p.nextTokenExists() {
 			p.agregarError("Se esperaba un operando después del operador")
 			return nil
 		}
 		p.nextToken() // Avanzar al operando derecho
-		
-		fmt.Printf("Analizando lado derecho, token: '%s'\n", p.curToken.Lexeme)
+
 		derecha := p.parseExpresionSimple()
 		if derecha == nil {
 			return nil
 		}
-		
+
 		return &ExpresionBinaria{
+			Rango:     Rango{Inicio: izquierda.Ubicacion().Inicio, Fin: derecha.Ubicacion().Fin},
 			Izquierda: izquierda,
 			Operador:  operador,
 			Derecha:   derecha,
@@ -367,7 +1064,7 @@ func (p *Parser) parseExpresionCompleta() Expresion {
 
 // parseExpresionComparacion analiza una expresión de comparación (x == 2)
 func (p *Parser) parseExpresionComparacion() Expresion {
-	fmt.Printf("Analizando expresión de comparación, token actual: '%s'\n", p.curToken.Lexeme)
+	defer p.salir(p.entrar(REGLA_EXPRESION_COMPARACION))
 
 	// Obtener el lado izquierdo
 	izquierda := p.parseExpresionSimple()
@@ -377,24 +1074,24 @@ func (p *Parser) parseExpresionComparacion() Expresion {
 
 	// Debe haber un operador de comparación
 	if p.peekTokenIs(lexer.TOKEN_EQUAL) {
-		fmt.Printf("Encontrado operador de comparación: '%s'\n", p.peekToken.Lexeme)
-		
+
 		p.nextToken() // Avanzar al operador
+		p.hoja()
 		operador := p.curToken.Lexeme
-		
+
 		if !p.nextTokenExists() {
 			p.agregarError("Se esperaba un operando después del operador de comparación")
 			return nil
 		}
 		p.nextToken() // Avanzar al operando derecho
-		
-		fmt.Printf("Analizando lado derecho de comparación, token: '%s'\n", p.curToken.Lexeme)
+
 		derecha := p.parseExpresionSimple()
 		if derecha == nil {
 			return nil
 		}
-		
+
 		return &ExpresionBinaria{
+			Rango:     Rango{Inicio: izquierda.Ubicacion().Inicio, Fin: derecha.Ubicacion().Fin},
 			Izquierda: izquierda,
 			Operador:  operador,
 			Derecha:   derecha,
@@ -405,25 +1102,38 @@ func (p *Parser) parseExpresionComparacion() Expresion {
 	return nil
 }
 
+// descartarGrupos avanza hasta cerrar los paréntesis y corchetes abiertos
+// en la expresión actual, para que una expresión demasiado anidada se
+// informe una sola vez y no con un error por cada ')' sobrante. Se detiene
+// antes de un ';' aunque falten cierres.
+func (p *Parser) descartarGrupos() {
+	abiertos := p.grupos
+	for abiertos > 0 && p.nextTokenExists() && !p.peekTokenIs(lexer.TOKEN_SEMI) {
+		p.nextToken()
+		switch p.curToken.Type {
+		case lexer.TOKEN_LPAREN, lexer.TOKEN_LBRACKET:
+			abiertos++
+		case lexer.TOKEN_RPAREN, lexer.TOKEN_RBRACKET:
+			abiertos--
+		}
+	}
+}
+
 // nextTokenExists verifica si hay un siguiente token válido
 func (p *Parser) nextTokenExists() bool {
-	return p.position+1 < len(p.tokens) && p.peekToken.Type != lexer.TOKEN_EOF
+	return p.peekToken.Type != lexer.TOKEN_EOF
 }
 
 // expectPeek verifica si el siguiente token es del tipo esperado
 func (p *Parser) expectPeek(t lexer.TokenType) bool {
-	fmt.Printf("Verificando si el siguiente token es '%s', actual: '%s', siguiente: '%s'\n", 
-		t, p.curToken.Type, p.peekToken.Type)
-	
+
 	if p.peekTokenIs(t) {
 		p.nextToken()
-		fmt.Printf("Verificación exitosa, token actual ahora es: '%s'\n", p.curToken.Lexeme)
+		p.hoja()
 		return true
 	}
-	
+
 	p.peekError(t)
-	fmt.Printf("Verificación fallida, se esperaba '%s', se encontró '%s'\n", 
-		t, p.peekToken.Type)
 	return false
 }
 
@@ -452,4 +1162,40 @@ func (p *Parser) agregarError(mensaje string) {
 		Columna: p.curToken.Column,
 	}
 	p.errors = append(p.errors, error)
-}
\ No newline at end of file
+	p.registrar(traza.ERROR, "", mensaje)
+}
+
+// entrar registra el comienzo de una regla, la abre en el árbol concreto y devuelve su nombre para salir
+func (p *Parser) entrar(regla string) string {
+	p.registrar(traza.REGLA_ENTRADA, regla, "")
+	p.profundidad++
+	p.abrir(regla)
+	return regla
+}
+
+// salir registra el final de una regla
+func (p *Parser) salir(regla string) {
+	p.cerrar(1)
+	p.profundidad--
+	p.registrar(traza.REGLA_SALIDA, regla, "")
+}
+
+// recuperado registra una recuperación de error
+func (p *Parser) recuperado(mensaje string) {
+	p.registrar(traza.ERROR_RECUPERADO, "", mensaje)
+}
+
+// registrar envía un evento a la traza en la posición del token actual
+func (p *Parser) registrar(tipo traza.TipoEvento, regla, mensaje string) {
+	p.traza.Registrar(traza.Evento{
+		Fase:        traza.FASE_SINTACTICA,
+		Tipo:        tipo,
+		Regla:       regla,
+		TipoToken:   string(p.curToken.Type),
+		Lexema:      p.curToken.Lexeme,
+		Mensaje:     mensaje,
+		Linea:       p.curToken.Line,
+		Columna:     p.curToken.Column,
+		Profundidad: p.profundidad,
+	})
+}
@@ -0,0 +1,252 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"analyzer-api/internal/lexer"
+)
+
+func TestComentarioSinCerrarNoSeRepite(t *testing.T) {
+	// El analizador léxico ya informa el comentario sin cerrar; el parser
+	// no debe informarlo otra vez como una declaración inesperada
+	p := New(lexer.New("int x = 1; /* abc"))
+	programa := p.Parse()
+	if errores := p.Errores(); len(errores) != 0 {
+		t.Errorf("errores de sintaxis %v, no se esperaba ninguno", errores)
+	}
+	if len(programa.Declaraciones) != 1 {
+		t.Errorf("%d declaraciones, se esperaba 1", len(programa.Declaraciones))
+	}
+}
+
+// llamadasAnidadas devuelve una declaración con n llamadas anidadas en su
+// valor inicial, int x = f(f(...f(1)...));
+func llamadasAnidadas(n int) string {
+	return "int x = " + strings.Repeat("f(", n) + "1" + strings.Repeat(")", n) + ";"
+}
+
+func TestAnidacionMaxima(t *testing.T) {
+	// El valor inicial y cada argumento son una expresión completa, así que
+	// MaximaAnidacion-1 llamadas llegan justo al límite
+	p := New(lexer.New(llamadasAnidadas(MaximaAnidacion - 1)))
+	programa := p.Parse()
+	if errores := p.Errores(); len(errores) != 0 {
+		t.Fatalf("errores de sintaxis %v, no se esperaba ninguno", errores)
+	}
+	if _, err := json.Marshal(programa); err != nil {
+		t.Fatalf("no se pudo codificar el AST: %v", err)
+	}
+
+	// Una llamada más excede el límite en el literal más interno. El
+	// resto de la expresión se descarta, así que los errores no crecen
+	// con la profundidad: solo siguen los de la declaración interrumpida.
+	for _, n := range []int{MaximaAnidacion, 300000} {
+		p := New(lexer.New(llamadasAnidadas(n) + " int y = 2;"))
+		programa := p.Parse()
+		errores := p.Errores()
+		if len(errores) == 0 || len(errores) > 3 {
+			t.Fatalf("%d llamadas: errores %v, se esperaba el de anidación y a lo sumo dos más", n, errores)
+		}
+		mensaje := fmt.Sprintf("Expresión demasiado anidada: más de %d niveles", MaximaAnidacion)
+		columna := len("int x = ") + 2*MaximaAnidacion + 1
+		if e := errores[0]; e.Mensaje != mensaje || e.Linea != 1 || e.Columna != columna {
+			t.Errorf("%d llamadas: error %q en %d:%d, se esperaba %q en 1:%d", n, e.Mensaje, e.Linea, e.Columna, mensaje, columna)
+		}
+		if len(programa.Declaraciones) != 1 {
+			t.Errorf("%d llamadas: %d declaraciones, se esperaba solo la de y", n, len(programa.Declaraciones))
+		}
+	}
+}
+
+// programaCompleto usa todas las producciones de la gramática
+const programaCompleto = `const int N = 2 * 3;
+int v[3] = {1, N, x};
+int w[2];
+int x = N;
+int suma(int a, int b) {
+	int t = a + b;
+	return t / 1;
+}
+int cero() {
+	return 0;
+}
+x = suma(x, v[1]) % 5;
+v[0] = cero();
+cero();
+suma(1, 2);
+read(x);
+read(v[x]);
+print(x);
+do {
+	x = x + 1;
+	print(x);
+	read(w[0]);
+} while (x == v[2]);
+do {
+} while (cero() == 1);
+`
+
+// El árbol concreto de un programa con todas las construcciones del lenguaje
+// debe usar solo producciones de Gramatica, y todas ellas. Así, si el parser
+// y la gramática se separan falla esta prueba, sin depender de que una
+// solicitud pida el árbol concreto.
+func TestArbolConcretoUsaLaGramatica(t *testing.T) {
+	p := New(lexer.New(programaCompleto), ConArbolConcreto())
+	p.Parse()
+	if errores := p.Errores(); len(errores) > 0 {
+		t.Fatalf("errores de sintaxis: %v", errores)
+	}
+	arbol := p.ArbolConcreto()
+	if err := VerificarArbol(arbol); err != nil {
+		t.Fatal(err)
+	}
+
+	usadas := map[string]bool{}
+	var recorrer func(n *NodoConcreto)
+	recorrer = func(n *NodoConcreto) {
+		if n.Terminal {
+			return
+		}
+		usadas[n.Produccion().String()] = true
+		for _, h := range n.Hijos {
+			recorrer(h)
+		}
+	}
+	recorrer(arbol)
+	for _, produccion := range Producciones() {
+		if !usadas[produccion.String()] {
+			t.Errorf("ningún árbol usa la producción %s", produccion)
+		}
+	}
+}
+
+// Aplicar los pasos de la derivación desde el símbolo inicial debe llevar
+// siempre a un no terminal igual al lado izquierdo del paso y terminar en
+// los terminales del árbol
+func TestDerivacionReconstruyeLasFormas(t *testing.T) {
+	p := New(lexer.New(programaCompleto), ConArbolConcreto())
+	p.Parse()
+	arbol := p.ArbolConcreto()
+
+	forma := []string{arbol.Simbolo}
+	for i, paso := range arbol.Derivacion() {
+		if paso.Posicion >= len(forma) || forma[paso.Posicion] != paso.Produccion.Izquierda {
+			t.Fatalf("paso %d: %s no se aplica en la posición %d de %v", i, paso.Produccion, paso.Posicion, forma)
+		}
+		forma = AplicarPaso(forma, paso)
+	}
+
+	var hojas []string
+	var recorrer func(n *NodoConcreto)
+	recorrer = func(n *NodoConcreto) {
+		if n.Terminal {
+			hojas = append(hojas, n.Simbolo)
+			return
+		}
+		for _, h := range n.Hijos {
+			recorrer(h)
+		}
+	}
+	recorrer(arbol)
+	if strings.Join(forma, " ") != strings.Join(hojas, " ") {
+		t.Errorf("la derivación termina en %v, se esperaban los terminales %v", forma, hojas)
+	}
+}
+
+// La derivación de un programa grande debe crecer en proporción al
+// programa, no con el cuadrado de su tamaño
+func TestDerivacionLinealEnElPrograma(t *testing.T) {
+	codigo := "int x = 0;\n" + strings.Repeat("x = x + 1;\n", 20000)
+	tokens := len(lexer.New(codigo).Analizar())
+	p := New(lexer.New(codigo), ConArbolConcreto())
+	p.Parse()
+
+	simbolos := 0
+	for _, paso := range p.ArbolConcreto().Derivacion() {
+		simbolos += 1 + len(paso.Produccion.Derecha)
+	}
+	if simbolos > 20*tokens {
+		t.Errorf("la derivación tiene %d símbolos para %d tokens", simbolos, tokens)
+	}
+}
+
+// Codificar, serializar y decodificar el árbol de un programa con todos los
+// tipos de nodo debe dar el mismo JSON
+func TestJSONIdaYVuelta(t *testing.T) {
+	p := New(lexer.New(programaCompleto))
+	programa := p.Parse()
+	original, err := json.Marshal(programa)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tipos := map[string]bool{}
+	var recorrer func(j *NodoJSON)
+	recorrer = func(j *NodoJSON) {
+		tipos[j.Kind] = true
+		for _, h := range j.Children {
+			recorrer(h)
+		}
+	}
+	recorrer(CodificarNodo(programa))
+	for _, tipo := range []string{
+		NODO_PROGRAMA, NODO_DECLARACION_VARIABLE, NODO_DECLARACION_ASIGNACION, NODO_DECLARACION_DO_WHILE,
+		NODO_EXPRESION_IDENTIFICADOR, NODO_EXPRESION_NUMERO, NODO_EXPRESION_BINARIA, NODO_EXPRESION_ASIGNACION,
+		NODO_DECLARACION_FUNCION, NODO_PARAMETRO, NODO_DECLARACION_RETORNO, NODO_DECLARACION_LLAMADA,
+		NODO_EXPRESION_LLAMADA, NODO_DECLARACION_ARREGLO, NODO_EXPRESION_INDICE, NODO_DECLARACION_IMPRESION,
+		NODO_DECLARACION_LECTURA,
+	} {
+		if !tipos[tipo] {
+			t.Errorf("el programa no tiene ningún nodo %s", tipo)
+		}
+	}
+
+	var decodificado Programa
+	if err := json.Unmarshal(original, &decodificado); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	otra, err := json.Marshal(&decodificado)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(otra) != string(original) {
+		t.Errorf("el árbol cambió al decodificarlo:\n%s\nse volvió\n%s", original, otra)
+	}
+}
+
+func TestDecodificarNodoInvalido(t *testing.T) {
+	numero := `{"kind":"ExpresionNumero","rol":"%s","valor":"1","children":[]}`
+	hijo := func(rol string) string { return fmt.Sprintf(numero, rol) }
+	casos := []struct {
+		nombre string
+		json   string
+	}{
+		{"binaria sin operandos", `{"kind":"ExpresionBinaria","operador":"+","children":[]}`},
+		{"binaria sin derecha", `{"kind":"ExpresionBinaria","operador":"+","children":[` + hijo(ROL_IZQUIERDA) + `]}`},
+		{"binaria con dos izquierdas", `{"kind":"ExpresionBinaria","operador":"+","children":[` +
+			hijo(ROL_IZQUIERDA) + `,` + hijo(ROL_IZQUIERDA) + `,` + hijo(ROL_DERECHA) + `]}`},
+		{"do-while sin hijos", `{"kind":"DeclaracionDoWhile","children":[]}`},
+		{"variable sin valor", `{"kind":"DeclaracionVariable","tipo":"int","nombre":"x","children":[]}`},
+		{"asignación sin expresión", `{"kind":"DeclaracionAsignacion","children":[]}`},
+		{"expresión de asignación sin valor", `{"kind":"ExpresionAsignacion","nombre":"x","children":[]}`},
+		{"print sin valor", `{"kind":"DeclaracionImpresion","children":[]}`},
+		{"return sin valor", `{"kind":"DeclaracionRetorno","children":[]}`},
+		{"llamada sin expresión", `{"kind":"DeclaracionLlamada","children":[]}`},
+		{"arreglo sin tamaño", `{"kind":"DeclaracionArreglo","tipo":"int","nombre":"v","children":[]}`},
+		{"índice sin expresión", `{"kind":"ExpresionIndice","nombre":"v","children":[]}`},
+		{"read con dos índices", `{"kind":"DeclaracionLectura","nombre":"v","children":[` + hijo(ROL_INDICE) + `,` + hijo(ROL_INDICE) + `]}`},
+		{"rol equivocado", `{"kind":"DeclaracionImpresion","children":[` + hijo(ROL_CONDICION) + `]}`},
+		{"tipo desconocido", `{"kind":"Otro","children":[]}`},
+		{"declaración dentro de un programa inválida", `{"kind":"Programa","children":[{"kind":"DeclaracionDoWhile","rol":"declaracion","children":[]}]}`},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			if n, err := DecodificarNodo([]byte(c.json)); err == nil {
+				t.Errorf("DecodificarNodo(%s) = %#v, se esperaba un error", c.json, n)
+			}
+		})
+	}
+}
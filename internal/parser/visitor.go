@@ -0,0 +1,236 @@
+package parser
+
+// Visitor recorre el AST con Walk. Pre se llama al llegar a un nodo y, si
+// devuelve true, se visitan sus hijos; Post se llama al terminar con el nodo
+// (aunque Pre haya devuelto false).
+type Visitor interface {
+	Pre(n Nodo) bool
+	Post(n Nodo)
+}
+
+// Walk recorre en profundidad el árbol con raíz n, en el orden del código
+// fuente. Los hijos nil se omiten.
+func Walk(v Visitor, n Nodo) {
+	if esNil(n) {
+		return
+	}
+	if v.Pre(n) {
+		for _, hijo := range Hijos(n) {
+			Walk(v, hijo)
+		}
+	}
+	v.Post(n)
+}
+
+// inspector adapta una función al Visitor
+type inspector func(Nodo) bool
+
+func (f inspector) Pre(n Nodo) bool { return f(n) }
+func (f inspector) Post(Nodo)       {}
+
+// Inspect recorre el árbol en preorden llamando a f con cada nodo. Si f
+// devuelve false no se visitan los hijos de ese nodo.
+func Inspect(n Nodo, f func(Nodo) bool) {
+	Walk(inspector(f), n)
+}
+
+// Hijos devuelve los hijos no nil de n en el orden del código fuente
+func Hijos(n Nodo) []Nodo {
+	var hijos []Nodo
+	agregar := func(h Nodo) {
+		if !esNil(h) {
+			hijos = append(hijos, h)
+		}
+	}
+	switch nodo := n.(type) {
+	case *Programa:
+		for _, decl := range nodo.Declaraciones {
+			agregar(decl)
+		}
+	case *DeclaracionVariable:
+		agregar(nodo.Valor)
+	case *DeclaracionAsignacion:
+		if nodo.Asignacion != nil {
+			agregar(nodo.Asignacion)
+		}
+	case *DeclaracionDoWhile:
+		for _, decl := range nodo.Cuerpo {
+			agregar(decl)
+		}
+		agregar(nodo.Condicion)
+	case *DeclaracionImpresion:
+		agregar(nodo.Valor)
+	case *DeclaracionLectura:
+		agregar(nodo.Indice)
+	case *DeclaracionArreglo:
+		if nodo.Tamano != nil {
+			agregar(nodo.Tamano)
+		}
+		for _, elemento := range nodo.Elementos {
+			agregar(elemento)
+		}
+	case *ExpresionIndice:
+		agregar(nodo.Indice)
+	case *DeclaracionFuncion:
+		for _, parametro := range nodo.Parametros {
+			agregar(parametro)
+		}
+		for _, decl := range nodo.Cuerpo {
+			agregar(decl)
+		}
+	case *DeclaracionRetorno:
+		agregar(nodo.Valor)
+	case *DeclaracionLlamada:
+		if nodo.Llamada != nil {
+			agregar(nodo.Llamada)
+		}
+	case *ExpresionLlamada:
+		for _, argumento := range nodo.Argumentos {
+			agregar(argumento)
+		}
+	case *ExpresionBinaria:
+		agregar(nodo.Izquierda)
+		agregar(nodo.Derecha)
+	case *ExpresionAsignacion:
+		agregar(nodo.Indice)
+		agregar(nodo.Valor)
+	}
+	return hijos
+}
+
+// Rewrite devuelve una copia transformada del árbol con raíz n, sin modificar
+// el original. Recorre en postorden: cada nodo se copia con sus hijos ya
+// transformados y se pasa a f, que devuelve el nodo que lo reemplaza (la misma
+// copia, modificada o no, u otro nodo). Si f devuelve nil para una
+// declaración dentro de una lista, la declaración se elimina; un reemplazo de
+// otro tipo de nodo (una declaración donde va una expresión) se descarta como
+// nil.
+func Rewrite(n Nodo, f func(Nodo) Nodo) Nodo {
+	if esNil(n) {
+		return nil
+	}
+	var copia Nodo
+	switch nodo := n.(type) {
+	case *Programa:
+		c := *nodo
+		c.Declaraciones = reescribirDeclaraciones(nodo.Declaraciones, f)
+		copia = &c
+	case *DeclaracionVariable:
+		c := *nodo
+		c.Valor = reescribirExpresion(nodo.Valor, f)
+		copia = &c
+	case *DeclaracionAsignacion:
+		c := *nodo
+		if nodo.Asignacion != nil {
+			c.Asignacion, _ = Rewrite(nodo.Asignacion, f).(*ExpresionAsignacion)
+		}
+		copia = &c
+	case *DeclaracionDoWhile:
+		c := *nodo
+		c.Cuerpo = reescribirDeclaraciones(nodo.Cuerpo, f)
+		c.Condicion = reescribirExpresion(nodo.Condicion, f)
+		copia = &c
+	case *DeclaracionImpresion:
+		c := *nodo
+		c.Valor = reescribirExpresion(nodo.Valor, f)
+		copia = &c
+	case *DeclaracionLectura:
+		c := *nodo
+		c.Indice = reescribirExpresion(nodo.Indice, f)
+		copia = &c
+	case *DeclaracionArreglo:
+		c := *nodo
+		if nodo.Tamano != nil {
+			c.Tamano, _ = Rewrite(nodo.Tamano, f).(*ExpresionNumero)
+		}
+		c.Elementos = reescribirExpresiones(nodo.Elementos, f)
+		copia = &c
+	case *ExpresionIndice:
+		c := *nodo
+		c.Indice = reescribirExpresion(nodo.Indice, f)
+		copia = &c
+	case *DeclaracionFuncion:
+		c := *nodo
+		if nodo.Parametros != nil {
+			c.Parametros = make([]*Parametro, 0, len(nodo.Parametros))
+			for _, parametro := range nodo.Parametros {
+				if r, ok := Rewrite(parametro, f).(*Parametro); ok && r != nil {
+					c.Parametros = append(c.Parametros, r)
+				}
+			}
+		}
+		c.Cuerpo = reescribirDeclaraciones(nodo.Cuerpo, f)
+		copia = &c
+	case *Parametro:
+		c := *nodo
+		copia = &c
+	case *DeclaracionRetorno:
+		c := *nodo
+		c.Valor = reescribirExpresion(nodo.Valor, f)
+		copia = &c
+	case *DeclaracionLlamada:
+		c := *nodo
+		if nodo.Llamada != nil {
+			c.Llamada, _ = Rewrite(nodo.Llamada, f).(*ExpresionLlamada)
+		}
+		copia = &c
+	case *ExpresionLlamada:
+		c := *nodo
+		c.Argumentos = reescribirExpresiones(nodo.Argumentos, f)
+		copia = &c
+	case *ExpresionIdentificador:
+		c := *nodo
+		copia = &c
+	case *ExpresionNumero:
+		c := *nodo
+		copia = &c
+	case *ExpresionBinaria:
+		c := *nodo
+		c.Izquierda = reescribirExpresion(nodo.Izquierda, f)
+		c.Derecha = reescribirExpresion(nodo.Derecha, f)
+		copia = &c
+	case *ExpresionAsignacion:
+		c := *nodo
+		c.Indice = reescribirExpresion(nodo.Indice, f)
+		c.Valor = reescribirExpresion(nodo.Valor, f)
+		copia = &c
+	default:
+		copia = n
+	}
+	return f(copia)
+}
+
+func reescribirDeclaraciones(declaraciones []Declaracion, f func(Nodo) Nodo) []Declaracion {
+	if declaraciones == nil {
+		return nil
+	}
+	resultado := make([]Declaracion, 0, len(declaraciones))
+	for _, decl := range declaraciones {
+		if d, ok := Rewrite(decl, f).(Declaracion); ok && !esNil(d) {
+			resultado = append(resultado, d)
+		}
+	}
+	return resultado
+}
+
+func reescribirExpresion(e Expresion, f func(Nodo) Nodo) Expresion {
+	if r, ok := Rewrite(e, f).(Expresion); ok && !esNil(r) {
+		return r
+	}
+	return nil
+}
+
+// reescribirExpresiones reescribe una lista de expresiones, eliminando las
+// que f reemplaza por nil
+func reescribirExpresiones(expresiones []Expresion, f func(Nodo) Nodo) []Expresion {
+	if expresiones == nil {
+		return nil
+	}
+	resultado := make([]Expresion, 0, len(expresiones))
+	for _, e := range expresiones {
+		if r := reescribirExpresion(e, f); r != nil {
+			resultado = append(resultado, r)
+		}
+	}
+	return resultado
+}
@@ -0,0 +1,159 @@
+package semantic
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"analyzer-api/internal/parser"
+)
+
+// recolectarConstante registra una constante con el valor de su
+// inicializador, que debe poder calcularse durante el análisis
+func (a *Analizador) recolectarConstante(decl *parser.DeclaracionVariable) {
+	if a.tabla.DeclaradoEnAmbito(decl.Nombre) {
+		a.agregarError(fmt.Sprintf("Constante '%s' ya declarada", decl.Nombre), decl.Inicio.Linea, decl.Inicio.Columna)
+		return
+	}
+	simbolo := Simbolo{
+		Nombre:        decl.Nombre,
+		Tipo:          decl.Tipo,
+		Linea:         decl.Inicio.Linea,
+		Columna:       decl.Inicio.Columna,
+		Categoria:     CATEGORIA_CONSTANTE,
+		Inicializador: decl.Valor,
+	}
+	valor, ok := a.valorConstante(decl.Valor)
+	if ok {
+		simbolo.Valor = valor
+		simbolo.ValorInicial = valor
+	} else if decl.Valor != nil && !a.formadaPorConstantes(decl.Valor) {
+		// Si está formada por constantes pero no se pudo calcular, el error
+		// (número fuera de rango, desbordamiento o división por cero) se
+		// informa al verificar la expresión
+		inicio := decl.Valor.Ubicacion().Inicio
+		a.agregarError(fmt.Sprintf("El valor de la constante '%s' debe poder calcularse al compilar", decl.Nombre), inicio.Linea, inicio.Columna)
+	}
+	a.tabla.DefinirSimbolo(simbolo)
+	if ok {
+		a.simboloDefinido(decl.Nombre, fmt.Sprintf("Constante '%s' de tipo %s = %d", decl.Nombre, decl.Tipo, valor))
+	} else {
+		a.simboloDefinido(decl.Nombre, fmt.Sprintf("Constante '%s' de tipo %s", decl.Nombre, decl.Tipo))
+	}
+}
+
+// valorConstante calcula el valor de una expresión formada solo por números
+// y constantes ya declaradas
+func (a *Analizador) valorConstante(expr parser.Expresion) (int64, bool) {
+	return a.evaluar(expr, nil)
+}
+
+// formadaPorConstantes indica si una expresión usa solo números y
+// constantes ya declaradas
+func (a *Analizador) formadaPorConstantes(expr parser.Expresion) bool {
+	switch e := expr.(type) {
+	case *parser.ExpresionNumero:
+		return true
+	case *parser.ExpresionIdentificador:
+		simbolo, ok := a.tabla.Obtener(e.Valor)
+		return ok && simbolo.Categoria == CATEGORIA_CONSTANTE
+	case *parser.ExpresionBinaria:
+		return a.formadaPorConstantes(e.Izquierda) && a.formadaPorConstantes(e.Derecha)
+	}
+	return false
+}
+
+// verificarNumero informa un número que no cabe en int
+func (a *Analizador) verificarNumero(numero *parser.ExpresionNumero) {
+	valor, err := strconv.ParseInt(numero.Valor, 10, 64)
+	if err != nil || valor < math.MinInt32 || valor > math.MaxInt32 {
+		a.agregarError(fmt.Sprintf("El número %s está fuera del rango de int (%d a %d)",
+			numero.Valor, math.MinInt32, math.MaxInt32), numero.Inicio.Linea, numero.Inicio.Columna)
+	}
+}
+
+// verificarOperacion informa una división o un resto por un divisor
+// constante igual a cero y una operación entre constantes cuyo resultado no
+// cabe en int. El error señala el operando derecho.
+func (a *Analizador) verificarOperacion(operacion *parser.ExpresionBinaria) {
+	derecha, ok := a.valorConstante(operacion.Derecha)
+	if !ok {
+		return
+	}
+	inicio := operacion.Derecha.Ubicacion().Inicio
+	if derecha == 0 && (operacion.Operador == "/" || operacion.Operador == "%") {
+		a.agregarError(fmt.Sprintf("División por cero: el divisor de '%s' vale 0", operacion.Operador), inicio.Linea, inicio.Columna)
+		return
+	}
+	izquierda, ok := a.valorConstante(operacion.Izquierda)
+	if !ok {
+		return
+	}
+	if valor, ok := a.evaluarSinRango(operacion, nil); ok && (valor < math.MinInt32 || valor > math.MaxInt32) {
+		a.agregarError(fmt.Sprintf("El resultado de %d %s %d es %d, fuera del rango de int (%d a %d)",
+			izquierda, operacion.Operador, derecha, valor, math.MinInt32, math.MaxInt32), inicio.Linea, inicio.Columna)
+	}
+}
+
+// evaluar calcula el valor de una expresión formada por números, constantes
+// y variables cuyo valor se conoce en valores (por clave del símbolo). Un
+// resultado fuera del rango de int no se considera conocido.
+func (a *Analizador) evaluar(expr parser.Expresion, valores map[string]int64) (int64, bool) {
+	valor, ok := a.evaluarSinRango(expr, valores)
+	if !ok || valor < math.MinInt32 || valor > math.MaxInt32 {
+		return 0, false
+	}
+	return valor, true
+}
+
+func (a *Analizador) evaluarSinRango(expr parser.Expresion, valores map[string]int64) (int64, bool) {
+	switch e := expr.(type) {
+	case *parser.ExpresionNumero:
+		valor, err := strconv.ParseInt(e.Valor, 10, 64)
+		return valor, err == nil
+	case *parser.ExpresionIdentificador:
+		simbolo, ok := a.tabla.Obtener(e.Valor)
+		if !ok {
+			return 0, false
+		}
+		switch simbolo.Categoria {
+		case CATEGORIA_CONSTANTE:
+			valor, ok := simbolo.Valor.(int64)
+			return valor, ok
+		case CATEGORIA_VARIABLE, CATEGORIA_PARAMETRO:
+			valor, ok := valores[clave(simbolo.Ambito, simbolo.Nombre)]
+			return valor, ok
+		}
+	case *parser.ExpresionBinaria:
+		izquierda, ok := a.evaluar(e.Izquierda, valores)
+		if !ok {
+			return 0, false
+		}
+		derecha, ok := a.evaluar(e.Derecha, valores)
+		if !ok {
+			return 0, false
+		}
+		switch e.Operador {
+		case "+":
+			return izquierda + derecha, true
+		case "*":
+			return izquierda * derecha, true
+		case "/":
+			if derecha == 0 {
+				return 0, false
+			}
+			return izquierda / derecha, true
+		case "%":
+			if derecha == 0 {
+				return 0, false
+			}
+			return izquierda % derecha, true
+		case "==":
+			if izquierda == derecha {
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,267 @@
+package semantic
+
+import (
+	"fmt"
+	"sort"
+
+	"analyzer-api/internal/parser"
+	"analyzer-api/internal/traza"
+)
+
+// Códigos de las advertencias
+const (
+	ADVERTENCIA_SIN_LEER          = "W001" // símbolo declarado cuyo valor nunca se lee
+	ADVERTENCIA_ASIGNACION_MUERTA = "W002" // valor que se sobrescribe antes de leerse
+	ADVERTENCIA_AUTOASIGNACION    = "W003" // a = a;
+	ADVERTENCIA_AUTOCOMPARACION   = "W004" // x == x
+	ADVERTENCIA_CICLO_INFINITO    = "W005" // do-while cuya condición no cambia entre vueltas
+)
+
+// Advertencia representa código válido que probablemente no hace lo que
+// se quiso. No impide ejecutar el programa.
+type Advertencia struct {
+	Codigo  string
+	Mensaje string
+	Linea   int
+	Columna int
+}
+
+// revisar busca en el programa ya verificado los problemas que se informan
+// como advertencias
+func (a *Analizador) revisar() {
+	a.revisarSinLeer()
+	parser.Inspect(a.ast, a.revisarExpresion)
+
+	global := &escrituras{a: a, pendientes: map[string]escritura{}}
+	global.declaraciones(a.ast.Declaraciones)
+	for _, decl := range a.ast.Declaraciones {
+		funcion, ok := decl.(*parser.DeclaracionFuncion)
+		if !ok || !a.ambitoPropio(funcion) {
+			continue
+		}
+		a.tabla.EntrarAmbito(funcion.Nombre)
+		local := &escrituras{a: a, pendientes: map[string]escritura{}}
+		local.declaraciones(funcion.Cuerpo)
+		a.tabla.SalirAmbito()
+	}
+
+	sort.SliceStable(a.advertencias, func(i, j int) bool {
+		if a.advertencias[i].Linea != a.advertencias[j].Linea {
+			return a.advertencias[i].Linea < a.advertencias[j].Linea
+		}
+		return a.advertencias[i].Columna < a.advertencias[j].Columna
+	})
+}
+
+// revisarSinLeer informa las variables, constantes, arreglos y parámetros
+// cuyo valor nunca se usa
+func (a *Analizador) revisarSinLeer() {
+	for _, simbolo := range a.tabla.ListarSimbolos() {
+		if simbolo.Lecturas > 0 {
+			continue
+		}
+		var mensaje string
+		switch simbolo.Categoria {
+		case CATEGORIA_VARIABLE:
+			mensaje = fmt.Sprintf("La variable '%s' se declara pero nunca se lee", simbolo.Nombre)
+		case CATEGORIA_CONSTANTE:
+			mensaje = fmt.Sprintf("La constante '%s' se declara pero nunca se usa", simbolo.Nombre)
+		case CATEGORIA_ARREGLO:
+			mensaje = fmt.Sprintf("El arreglo '%s' se declara pero nunca se lee", simbolo.Nombre)
+		case CATEGORIA_PARAMETRO:
+			mensaje = fmt.Sprintf("El parámetro '%s' de la función '%s' nunca se usa", simbolo.Nombre, simbolo.Ambito)
+		default:
+			continue
+		}
+		a.agregarAdvertencia(ADVERTENCIA_SIN_LEER, mensaje, simbolo.Linea, simbolo.Columna)
+	}
+}
+
+// revisarExpresion informa las asignaciones de una variable a sí misma y
+// las comparaciones de una expresión consigo misma, salvo las condiciones
+// de do-while que verificarTerminacion ya informó como error
+func (a *Analizador) revisarExpresion(n parser.Nodo) bool {
+	switch nodo := n.(type) {
+	case *parser.ExpresionAsignacion:
+		destino := parser.Expresion(&parser.ExpresionIdentificador{Valor: nodo.Nombre})
+		if nodo.Indice != nil {
+			destino = &parser.ExpresionIndice{Nombre: nodo.Nombre, Indice: nodo.Indice}
+		}
+		if mismaExpresion(destino, nodo.Valor) {
+			a.agregarAdvertencia(ADVERTENCIA_AUTOASIGNACION,
+				fmt.Sprintf("Se asigna '%s' a sí misma; la asignación no tiene efecto", textoExpresion(destino)),
+				nodo.Inicio.Linea, nodo.Inicio.Columna)
+		}
+	case *parser.ExpresionBinaria:
+		if nodo.Operador == "==" && usaVariable(nodo.Izquierda) && mismaExpresion(nodo.Izquierda, nodo.Derecha) && !a.infinitas[nodo] {
+			a.agregarAdvertencia(ADVERTENCIA_AUTOCOMPARACION,
+				fmt.Sprintf("Se compara '%s' consigo misma; la comparación siempre es verdadera", textoExpresion(nodo.Izquierda)),
+				nodo.Inicio.Linea, nodo.Inicio.Columna)
+		}
+	}
+	return true
+}
+
+// mismaExpresion indica si dos expresiones calculan siempre el mismo valor
+// porque se escriben igual. Las llamadas no cuentan: pueden devolver valores
+// distintos cada vez.
+func mismaExpresion(x, y parser.Expresion) bool {
+	switch ex := x.(type) {
+	case *parser.ExpresionIdentificador:
+		ey, ok := y.(*parser.ExpresionIdentificador)
+		return ok && ex.Valor == ey.Valor
+	case *parser.ExpresionNumero:
+		ey, ok := y.(*parser.ExpresionNumero)
+		return ok && ex.Valor == ey.Valor
+	case *parser.ExpresionIndice:
+		ey, ok := y.(*parser.ExpresionIndice)
+		return ok && ex.Nombre == ey.Nombre && mismaExpresion(ex.Indice, ey.Indice)
+	case *parser.ExpresionBinaria:
+		ey, ok := y.(*parser.ExpresionBinaria)
+		return ok && ex.Operador == ey.Operador &&
+			mismaExpresion(ex.Izquierda, ey.Izquierda) && mismaExpresion(ex.Derecha, ey.Derecha)
+	}
+	return false
+}
+
+// usaVariable indica si una expresión lee alguna variable o arreglo
+func usaVariable(expr parser.Expresion) bool {
+	usa := false
+	parser.Inspect(expr, func(n parser.Nodo) bool {
+		switch n.(type) {
+		case *parser.ExpresionIdentificador, *parser.ExpresionIndice:
+			usa = true
+		}
+		return !usa
+	})
+	return usa
+}
+
+// textoExpresion escribe una expresión sin llamadas para un mensaje
+func textoExpresion(expr parser.Expresion) string {
+	switch e := expr.(type) {
+	case *parser.ExpresionIdentificador:
+		return e.Valor
+	case *parser.ExpresionNumero:
+		return e.Valor
+	case *parser.ExpresionIndice:
+		return e.Nombre + "[" + textoExpresion(e.Indice) + "]"
+	case *parser.ExpresionBinaria:
+		return textoExpresion(e.Izquierda) + " " + e.Operador + " " + textoExpresion(e.Derecha)
+	}
+	return ""
+}
+
+// escritura es un valor guardado en una variable que todavía no se leyó
+type escritura struct {
+	sitio   Sitio
+	inicial bool // el valor es el inicializador de la declaración
+}
+
+// escrituras recorre el código en línea recta buscando valores que se
+// sobrescriben antes de leerse
+type escrituras struct {
+	a          *Analizador
+	pendientes map[string]escritura // por clave del símbolo
+}
+
+// declaraciones revisa una lista de declaraciones en orden
+func (e *escrituras) declaraciones(declaraciones []parser.Declaracion) {
+	for _, decl := range declaraciones {
+		switch d := decl.(type) {
+		case *parser.DeclaracionVariable:
+			if !d.Constante && d.Valor != nil {
+				e.leer(d.Valor)
+				inicio := d.Valor.Ubicacion().Inicio
+				e.escribir(d.Nombre, Sitio{Linea: inicio.Linea, Columna: inicio.Columna}, true)
+			}
+		case *parser.DeclaracionAsignacion:
+			asignacion := d.Asignacion
+			e.leer(asignacion.Indice)
+			e.leer(asignacion.Valor)
+			if asignacion.Indice == nil {
+				e.escribir(asignacion.Nombre, Sitio{Linea: asignacion.Inicio.Linea, Columna: asignacion.Inicio.Columna}, false)
+			}
+		case *parser.DeclaracionLectura:
+			e.leer(d.Indice)
+			if d.Indice == nil {
+				e.escribir(d.Nombre, Sitio{Linea: d.Inicio.Linea, Columna: d.Inicio.Columna}, false)
+			}
+		case *parser.DeclaracionDoWhile:
+			// La primera vuelta se ejecuta siempre y en línea recta; en las
+			// siguientes, lo que se lee en el ciclo puede ser el valor
+			// guardado en la vuelta anterior
+			e.declaraciones(d.Cuerpo)
+			e.leer(d.Condicion)
+			e.leer(d)
+		case *parser.DeclaracionFuncion:
+			// Se revisa aparte, en su propio ámbito
+		default:
+			e.leer(decl)
+		}
+	}
+}
+
+// leer marca como leídas las variables que usa n. Una llamada puede leer
+// cualquier variable global.
+func (e *escrituras) leer(n parser.Nodo) {
+	if n == nil {
+		return
+	}
+	parser.Inspect(n, func(n parser.Nodo) bool {
+		switch nodo := n.(type) {
+		case *parser.ExpresionIdentificador:
+			if simbolo, ok := e.a.tabla.Obtener(nodo.Valor); ok {
+				delete(e.pendientes, clave(simbolo.Ambito, simbolo.Nombre))
+			}
+		case *parser.ExpresionLlamada:
+			for k := range e.pendientes {
+				if simbolo, ok := e.a.tabla.ListarSimbolos()[k]; ok && simbolo.Ambito == AMBITO_GLOBAL {
+					delete(e.pendientes, k)
+				}
+			}
+		}
+		return true
+	})
+}
+
+// escribir registra un valor nuevo en una variable e informa si el anterior
+// no llegó a leerse
+func (e *escrituras) escribir(nombre string, sitio Sitio, inicial bool) {
+	simbolo, ok := e.a.tabla.Obtener(nombre)
+	if !ok || (simbolo.Categoria != CATEGORIA_VARIABLE && simbolo.Categoria != CATEGORIA_PARAMETRO) {
+		return
+	}
+	k := clave(simbolo.Ambito, nombre)
+	if anterior, ok := e.pendientes[k]; ok {
+		mensaje := fmt.Sprintf("El valor asignado a '%s' se sobrescribe en la línea %d antes de leerse", nombre, sitio.Linea)
+		if anterior.inicial {
+			mensaje = fmt.Sprintf("El valor inicial de '%s' se sobrescribe en la línea %d antes de leerse", nombre, sitio.Linea)
+		}
+		e.a.agregarAdvertencia(ADVERTENCIA_ASIGNACION_MUERTA, mensaje, anterior.sitio.Linea, anterior.sitio.Columna)
+	}
+	e.pendientes[k] = escritura{sitio: sitio, inicial: inicial}
+}
+
+// agregarAdvertencia agrega una advertencia
+func (a *Analizador) agregarAdvertencia(codigo, mensaje string, linea, columna int) {
+	a.advertencias = append(a.advertencias, Advertencia{
+		Codigo:  codigo,
+		Mensaje: mensaje,
+		Linea:   linea,
+		Columna: columna,
+	})
+	a.traza.Registrar(traza.Evento{
+		Fase:    traza.FASE_SEMANTICA,
+		Tipo:    traza.ADVERTENCIA,
+		Mensaje: codigo + ": " + mensaje,
+		Linea:   linea,
+		Columna: columna,
+	})
+}
+
+// Advertencias devuelve las advertencias encontradas por Analizar, en el
+// orden del código fuente
+func (a *Analizador) Advertencias() []Advertencia {
+	return a.advertencias
+}
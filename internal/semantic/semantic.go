@@ -1,8 +1,10 @@
 package semantic
 
 import (
-	"fmt"
 	"analyzer-api/internal/parser"
+	"analyzer-api/internal/traza"
+	"fmt"
+	"strconv"
 )
 
 // ErrorSemantico representa un error semántico
@@ -14,18 +16,41 @@ type ErrorSemantico struct {
 
 // Analizador estructura para el analizador semántico
 type Analizador struct {
-	ast        *parser.Programa
-	tabla      *TablaSimbolos
-	errores    []ErrorSemantico
+	ast          *parser.Programa
+	tabla        *TablaSimbolos
+	errores      []ErrorSemantico
+	advertencias []Advertencia
+	afectadas    []string                  // claves de las variables globales que modifica alguna función
+	infinitas    map[parser.Expresion]bool // condiciones de do-while informadas como siempre verdaderas
+	traza        traza.Trazador
+}
+
+// Opcion configura un analizador semántico al crearlo
+type Opcion func(*Analizador)
+
+// ConTraza registra en t cada símbolo definido y cada error encontrado
+func ConTraza(t traza.Trazador) Opcion {
+	return func(a *Analizador) {
+		if t != nil {
+			a.traza = t
+		}
+	}
 }
 
 // New crea un nuevo analizador semántico
-func New(ast *parser.Programa) *Analizador {
-	return &Analizador{
-		ast:     ast,
-		tabla:   NewTablaSimbolos(),
-		errores: []ErrorSemantico{},
+func New(ast *parser.Programa, opciones ...Opcion) *Analizador {
+	a := &Analizador{
+		ast:          ast,
+		tabla:        NewTablaSimbolos(),
+		errores:      []ErrorSemantico{},
+		advertencias: []Advertencia{},
+		infinitas:    map[parser.Expresion]bool{},
+		traza:        traza.Nulo(),
 	}
+	for _, opcion := range opciones {
+		opcion(a)
+	}
+	return a
 }
 
 // Analizar realiza el análisis semántico
@@ -34,158 +59,400 @@ func (a *Analizador) Analizar() []ErrorSemantico {
 	if a.ast == nil || a.ast.Declaraciones == nil {
 		return a.errores
 	}
-	
+
 	// Realizar un análisis completo en dos fases:
-	// 1. Registrar primero TODAS las declaraciones de variables
-	a.recolectarDeclaraciones(a.ast.Declaraciones)
-	
+	// 1. Registrar primero TODAS las declaraciones de variables y funciones,
+	//    para que una función pueda llamarse antes de su definición o a sí misma
+	parser.Inspect(a.ast, a.recolectarDeclaracion)
+	for _, decl := range a.ast.Declaraciones {
+		if funcion, ok := decl.(*parser.DeclaracionFuncion); ok {
+			a.recolectarFuncion(funcion)
+		}
+	}
+	a.afectadas = a.globalesModificadas()
+
 	// 2. Luego verificar todos los usos
-	a.verificarUsos(a.ast.Declaraciones)
+	parser.Walk(&verificadorUsos{a}, a.ast)
+
+	// 3. Calcular el valor que se conoce de cada variable
+	a.inferirValores()
+
+	// 4. Por último buscar el código sospechoso que se informa como advertencia
+	a.revisar()
 
 	return a.errores
 }
 
-// recolectarDeclaraciones registra todas las declaraciones de variables en la tabla de símbolos
-func (a *Analizador) recolectarDeclaraciones(declaraciones []parser.Declaracion) {
-	if declaraciones == nil {
+// recolectarDeclaracion registra en la tabla de símbolos una declaración de
+// variable o de función de nivel superior. Las expresiones no contienen
+// declaraciones, así que no se recorren; el contenido de las funciones se
+// registra en su propio ámbito con recolectarFuncion.
+func (a *Analizador) recolectarDeclaracion(n parser.Nodo) bool {
+	switch nodo := n.(type) {
+	case *parser.DeclaracionVariable:
+		if nodo.Constante {
+			a.recolectarConstante(nodo)
+			return false
+		}
+		// Registrar la variable en la tabla de símbolos con su inicializador;
+		// su valor y su valor inicial los calcula inferirValores
+		if !a.tabla.DeclaradoEnAmbito(nodo.Nombre) {
+			a.tabla.DefinirSimbolo(Simbolo{
+				Nombre:        nodo.Nombre,
+				Tipo:          nodo.Tipo,
+				Linea:         nodo.Inicio.Linea,
+				Columna:       nodo.Inicio.Columna,
+				Categoria:     CATEGORIA_VARIABLE,
+				Inicializador: nodo.Valor,
+			})
+			a.simboloDefinido(nodo.Nombre, fmt.Sprintf("Variable '%s' de tipo %s", nodo.Nombre, nodo.Tipo))
+		} else {
+			a.agregarError(fmt.Sprintf("Variable '%s' ya declarada", nodo.Nombre), nodo.Inicio.Linea, nodo.Inicio.Columna)
+		}
+		return false
+	case *parser.DeclaracionArreglo:
+		if a.tabla.DeclaradoEnAmbito(nodo.Nombre) {
+			a.agregarError(fmt.Sprintf("Variable '%s' ya declarada", nodo.Nombre), nodo.Inicio.Linea, nodo.Inicio.Columna)
+			return false
+		}
+		tamano := a.tamanoArreglo(nodo)
+		a.tabla.DefinirSimbolo(Simbolo{
+			Nombre:      nodo.Nombre,
+			Tipo:        nodo.Tipo,
+			Linea:       nodo.Inicio.Linea,
+			Columna:     nodo.Inicio.Columna,
+			Categoria:   CATEGORIA_ARREGLO,
+			Dimensiones: []int{tamano},
+		})
+		a.simboloDefinido(nodo.Nombre, fmt.Sprintf("Arreglo '%s' de %d elementos de tipo %s", nodo.Nombre, tamano, nodo.Tipo))
+		return false
+	case *parser.DeclaracionFuncion:
+		if a.tabla.DeclaradoEnAmbito(nodo.Nombre) {
+			a.agregarError(fmt.Sprintf("Función '%s' ya declarada", nodo.Nombre), nodo.Inicio.Linea, nodo.Inicio.Columna)
+			return false
+		}
+		simbolo := Simbolo{
+			Nombre:     nodo.Nombre,
+			Tipo:       nodo.Tipo,
+			Linea:      nodo.Inicio.Linea,
+			Columna:    nodo.Inicio.Columna,
+			Categoria:  CATEGORIA_FUNCION,
+			Parametros: []Parametro{},
+		}
+		for _, parametro := range nodo.Parametros {
+			simbolo.Parametros = append(simbolo.Parametros, Parametro{Nombre: parametro.Nombre, Tipo: parametro.Tipo})
+		}
+		a.tabla.DefinirSimbolo(simbolo)
+		a.simboloDefinido(nodo.Nombre, "Función "+simbolo.Firma())
+		return false
+	case parser.Expresion:
+		return false
+	}
+	return true
+}
+
+// recolectarFuncion registra los parámetros y las variables de una función
+// en su ámbito y verifica que el cuerpo devuelva un valor
+func (a *Analizador) recolectarFuncion(funcion *parser.DeclaracionFuncion) {
+	if !a.ambitoPropio(funcion) {
 		return
 	}
-	
-	for _, decl := range declaraciones {
-		if decl == nil {
+	a.tabla.EntrarAmbito(funcion.Nombre)
+	defer a.tabla.SalirAmbito()
+
+	for _, parametro := range funcion.Parametros {
+		if a.tabla.DeclaradoEnAmbito(parametro.Nombre) {
+			a.agregarError(fmt.Sprintf("Parámetro '%s' repetido en la función '%s'", parametro.Nombre, funcion.Nombre),
+				parametro.Inicio.Linea, parametro.Inicio.Columna)
 			continue
 		}
-		
-		if varDecl, ok := decl.(*parser.DeclaracionVariable); ok && varDecl != nil {
-			// Registrar la variable en la tabla de símbolos
-			if !a.tabla.EstaDeclarado(varDecl.Nombre) {
-				a.tabla.Definir(varDecl.Nombre, varDecl.Tipo, nil, 0, 0)
-			} else {
-				a.agregarError(fmt.Sprintf("Variable '%s' ya declarada", varDecl.Nombre), 0, 0)
-			}
-		} else if doWhile, ok := decl.(*parser.DeclaracionDoWhile); ok && doWhile != nil && doWhile.Cuerpo != nil {
-			// Recolectar declaraciones dentro del do-while
-			a.recolectarDeclaraciones(doWhile.Cuerpo)
+		a.tabla.DefinirSimbolo(Simbolo{
+			Nombre:    parametro.Nombre,
+			Tipo:      parametro.Tipo,
+			Linea:     parametro.Inicio.Linea,
+			Columna:   parametro.Inicio.Columna,
+			Categoria: CATEGORIA_PARAMETRO,
+		})
+		a.simboloDefinido(parametro.Nombre, fmt.Sprintf("Parámetro '%s' de tipo %s en la función '%s'", parametro.Nombre, parametro.Tipo, funcion.Nombre))
+	}
+
+	tieneRetorno := false
+	for _, decl := range funcion.Cuerpo {
+		switch d := decl.(type) {
+		case *parser.DeclaracionFuncion:
+			a.agregarError(fmt.Sprintf("No se puede definir la función '%s' dentro de la función '%s'", d.Nombre, funcion.Nombre),
+				d.Inicio.Linea, d.Inicio.Columna)
+		case *parser.DeclaracionRetorno:
+			tieneRetorno = true
+		default:
+			parser.Inspect(decl, a.recolectarDeclaracion)
 		}
 	}
+	if !tieneRetorno {
+		a.agregarError(fmt.Sprintf("La función '%s' no devuelve un valor con 'return'", funcion.Nombre),
+			funcion.Inicio.Linea, funcion.Inicio.Columna)
+	}
 }
 
-// verificarUsos verifica que todas las variables usadas hayan sido declaradas
-func (a *Analizador) verificarUsos(declaraciones []parser.Declaracion) {
-	if declaraciones == nil {
-		return
+// ambitoPropio indica si la función es la que quedó registrada con su
+// nombre. Una función repetida no tiene ámbito propio.
+func (a *Analizador) ambitoPropio(funcion *parser.DeclaracionFuncion) bool {
+	simbolo, ok := a.tabla.Obtener(funcion.Nombre)
+	return ok && simbolo.Linea == funcion.Inicio.Linea && simbolo.Columna == funcion.Inicio.Columna
+}
+
+// tamanoArreglo devuelve la cantidad de elementos de un arreglo e informa
+// si el tamaño no es válido o si hay más valores iniciales que elementos
+func (a *Analizador) tamanoArreglo(arreglo *parser.DeclaracionArreglo) int {
+	if arreglo.Tamano == nil {
+		return 0
 	}
-	
-	for _, decl := range declaraciones {
-		if decl == nil {
-			continue
+	inicio := arreglo.Tamano.Inicio
+	tamano, err := strconv.Atoi(arreglo.Tamano.Valor)
+	if err != nil || tamano <= 0 {
+		a.agregarError(fmt.Sprintf("El tamaño del arreglo '%s' debe ser un entero mayor que cero, se encontró %s",
+			arreglo.Nombre, arreglo.Tamano.Valor), inicio.Linea, inicio.Columna)
+		return 0
+	}
+	if len(arreglo.Elementos) > tamano {
+		sobrante := arreglo.Elementos[tamano].Ubicacion().Inicio
+		a.agregarError(fmt.Sprintf("El arreglo '%s' tiene %d elementos pero se inicializa con %d valores",
+			arreglo.Nombre, tamano, len(arreglo.Elementos)), sobrante.Linea, sobrante.Columna)
+	}
+	return tamano
+}
+
+// simboloDefinido registra en la traza la definición de un símbolo
+func (a *Analizador) simboloDefinido(nombre, mensaje string) {
+	a.traza.Registrar(traza.Evento{
+		Fase:    traza.FASE_SEMANTICA,
+		Tipo:    traza.SIMBOLO_DEFINIDO,
+		Lexema:  nombre,
+		Mensaje: mensaje,
+	})
+}
+
+// verificadorUsos verifica que todas las variables usadas hayan sido
+// declaradas y que las funciones se llamen correctamente
+type verificadorUsos struct {
+	a *Analizador
+}
+
+func (v *verificadorUsos) Pre(n parser.Nodo) bool {
+	switch nodo := n.(type) {
+	case *parser.DeclaracionFuncion:
+		if v.a.tabla.Ambito() != AMBITO_GLOBAL {
+			// Función anidada, ya informada al recolectar
+			return false
 		}
-		
-		if varDecl, ok := decl.(*parser.DeclaracionVariable); ok && varDecl != nil {
-			// Verificar el valor inicial
-			if varDecl.Valor != nil {
-				a.verificarExpresion(varDecl.Valor)
-			}
-		} else if doWhile, ok := decl.(*parser.DeclaracionDoWhile); ok && doWhile != nil {
-			// Verificar el cuerpo del do-while
-			if doWhile.Cuerpo != nil {
-				for _, cuerpoDecl := range doWhile.Cuerpo {
-					if asign, asignOk := cuerpoDecl.(*parser.DeclaracionAsignacion); asignOk && asign != nil && asign.Asignacion != nil {
-						// CORREGIDO: Verificar que la variable de asignación esté declarada
-						if !a.tabla.EstaDeclarado(asign.Asignacion.Nombre) {
-							a.agregarError(fmt.Sprintf("Variable '%s' usada antes de ser declarada", asign.Asignacion.Nombre), 0, 0)
-						}
-						
-						// Verificar las expresiones en el valor asignado
-						if asign.Asignacion.Valor != nil {
-							a.verificarExpresion(asign.Asignacion.Valor)
-						}
-					}
-				}
-			}
-			
-			// Verificar la condición
-			if doWhile.Condicion != nil {
-				a.verificarCondicionDoWhile(doWhile.Condicion)
-			}
-		} else if asign, ok := decl.(*parser.DeclaracionAsignacion); ok && asign != nil && asign.Asignacion != nil {
-			// Verificar que la variable exista
-			if !a.tabla.EstaDeclarado(asign.Asignacion.Nombre) {
-				a.agregarError(fmt.Sprintf("Variable '%s' usada antes de ser declarada", asign.Asignacion.Nombre), 0, 0)
-			}
-			
-			// Verificar la expresión del valor
-			if asign.Asignacion.Valor != nil {
-				a.verificarExpresion(asign.Asignacion.Valor)
+		if !v.a.ambitoPropio(nodo) {
+			// Función repetida, ya informada al recolectar. Su cuerpo no se
+			// verifica: el ámbito con su nombre es el de la primera.
+			return false
+		}
+		v.a.tabla.EntrarAmbito(nodo.Nombre)
+	case *parser.DeclaracionRetorno:
+		if v.a.tabla.Ambito() == AMBITO_GLOBAL {
+			v.a.agregarError("'return' fuera de una función", nodo.Inicio.Linea, nodo.Inicio.Columna)
+		} else if funcion, ok := v.a.tabla.Obtener(v.a.tabla.Ambito()); ok {
+			v.a.verificarTipo(nodo.Valor, funcion.Tipo, fmt.Sprintf("El valor devuelto por '%s'", funcion.Nombre))
+		}
+	case *parser.DeclaracionArreglo:
+		for i, elemento := range nodo.Elementos {
+			v.a.verificarTipo(elemento, nodo.Tipo, fmt.Sprintf("El valor %d del arreglo '%s'", i+1, nodo.Nombre))
+		}
+	case *parser.ExpresionAsignacion:
+		if simbolo, ok := v.a.verificarDestino(nodo.Nombre, nodo.Indice, nodo.Inicio, "asignar a"); ok {
+			v.a.tabla.RegistrarEscritura(nodo.Nombre, Sitio{Linea: nodo.Inicio.Linea, Columna: nodo.Inicio.Columna})
+			if nodo.Indice != nil {
+				v.a.verificarTipo(nodo.Valor, simbolo.Tipo, fmt.Sprintf("El valor asignado a un elemento de '%s'", nodo.Nombre))
+			} else {
+				v.a.verificarTipo(nodo.Valor, simbolo.Tipo, fmt.Sprintf("El valor asignado a '%s'", nodo.Nombre))
 			}
 		}
+	case *parser.DeclaracionLectura:
+		if _, ok := v.a.verificarDestino(nodo.Nombre, nodo.Indice, nodo.Inicio, "leer en"); ok {
+			v.a.tabla.RegistrarEscritura(nodo.Nombre, Sitio{Linea: nodo.Inicio.Linea, Columna: nodo.Inicio.Columna})
+		}
+	case *parser.ExpresionIdentificador:
+		simbolo, ok := v.a.tabla.Obtener(nodo.Valor)
+		if !ok {
+			v.a.agregarError(fmt.Sprintf("Variable '%s' usada antes de ser declarada", nodo.Valor), nodo.Inicio.Linea, nodo.Inicio.Columna)
+		} else if simbolo.Categoria == CATEGORIA_FUNCION {
+			v.a.agregarError(fmt.Sprintf("La función '%s' se usa como valor sin llamarla", nodo.Valor), nodo.Inicio.Linea, nodo.Inicio.Columna)
+		} else if simbolo.Categoria == CATEGORIA_ARREGLO {
+			v.a.agregarError(fmt.Sprintf("El arreglo '%s' se usa como valor sin indicar un elemento", nodo.Valor), nodo.Inicio.Linea, nodo.Inicio.Columna)
+		} else {
+			v.a.tabla.RegistrarLectura(nodo.Valor)
+		}
+	case *parser.ExpresionIndice:
+		if simbolo, ok := v.a.tabla.Obtener(nodo.Nombre); !ok {
+			v.a.agregarError(fmt.Sprintf("Variable '%s' usada antes de ser declarada", nodo.Nombre), nodo.Inicio.Linea, nodo.Inicio.Columna)
+		} else {
+			v.a.verificarIndice(simbolo, nodo.Indice, nodo.Inicio)
+			v.a.tabla.RegistrarLectura(nodo.Nombre)
+		}
+	case *parser.ExpresionLlamada:
+		v.a.verificarLlamada(nodo)
+	case *parser.ExpresionNumero:
+		v.a.verificarNumero(nodo)
+	case *parser.ExpresionBinaria:
+		v.a.verificarOperacion(nodo)
+	case *parser.DeclaracionDoWhile:
+		// Verificar el cuerpo normalmente y la condición con sus reglas propias
+		for _, decl := range nodo.Cuerpo {
+			parser.Walk(v, decl)
+		}
+		v.a.verificarCondicionDoWhile(nodo.Condicion)
+		v.a.verificarTerminacion(nodo)
+		return false
 	}
+	return true
 }
 
-// verificarCondicionDoWhile verifica la condición del do-while con reglas especiales
-func (a *Analizador) verificarCondicionDoWhile(expr parser.Expresion) {
-	if expr == nil {
+func (v *verificadorUsos) Post(n parser.Nodo) {
+	if funcion, ok := n.(*parser.DeclaracionFuncion); ok && v.a.tabla.Ambito() == funcion.Nombre {
+		v.a.tabla.SalirAmbito()
+	}
+}
+
+// verificarDestino verifica la variable o el elemento de un arreglo en que
+// se guarda un valor (con accion "asignar a" o "leer en") y devuelve su
+// símbolo si es válido
+func (a *Analizador) verificarDestino(nombre string, indice parser.Expresion, inicio parser.Posicion, accion string) (Simbolo, bool) {
+	// La variable debe existir
+	simbolo, ok := a.tabla.Obtener(nombre)
+	switch {
+	case !ok:
+		a.agregarError(fmt.Sprintf("Variable '%s' usada antes de ser declarada", nombre), inicio.Linea, inicio.Columna)
+		return simbolo, false
+	case simbolo.Categoria == CATEGORIA_FUNCION:
+		a.agregarError(fmt.Sprintf("No se puede %s la función '%s'", accion, nombre), inicio.Linea, inicio.Columna)
+		return simbolo, false
+	case simbolo.Categoria == CATEGORIA_CONSTANTE:
+		a.agregarError(fmt.Sprintf("No se puede %s la constante '%s'", accion, nombre), inicio.Linea, inicio.Columna)
+		return simbolo, false
+	case indice != nil:
+		return simbolo, a.verificarIndice(simbolo, indice, inicio)
+	case simbolo.Categoria == CATEGORIA_ARREGLO:
+		a.agregarError(fmt.Sprintf("No se puede %s el arreglo '%s' completo, se debe indicar un elemento", accion, nombre),
+			inicio.Linea, inicio.Columna)
+		return simbolo, false
+	}
+	return simbolo, true
+}
+
+// verificarIndice verifica que se indexe un arreglo y que un índice
+// constante esté dentro de sus límites. Devuelve false si el símbolo no es
+// un arreglo.
+func (a *Analizador) verificarIndice(simbolo Simbolo, indice parser.Expresion, inicio parser.Posicion) bool {
+	if simbolo.Categoria != CATEGORIA_ARREGLO {
+		a.agregarError(fmt.Sprintf("'%s' no es un arreglo", simbolo.Nombre), inicio.Linea, inicio.Columna)
+		return false
+	}
+	valor, ok := a.valorConstante(indice)
+	if !ok || len(simbolo.Dimensiones) == 0 || simbolo.Dimensiones[0] <= 0 {
+		return true
+	}
+	if tamano := simbolo.Dimensiones[0]; valor < 0 || valor >= int64(tamano) {
+		posicion := indice.Ubicacion().Inicio
+		a.agregarError(fmt.Sprintf("Índice %d fuera de rango para el arreglo '%s' de %d elementos (0 a %d)",
+			valor, simbolo.Nombre, tamano, tamano-1), posicion.Linea, posicion.Columna)
+	}
+	return true
+}
+
+// verificarLlamada verifica que se llame a una función declarada con la
+// cantidad y el tipo de argumentos de su firma
+func (a *Analizador) verificarLlamada(llamada *parser.ExpresionLlamada) {
+	linea, columna := llamada.Inicio.Linea, llamada.Inicio.Columna
+	simbolo, ok := a.tabla.Obtener(llamada.Nombre)
+	if !ok {
+		a.agregarError(fmt.Sprintf("Función '%s' no declarada", llamada.Nombre), linea, columna)
+		return
+	}
+	if simbolo.Categoria != CATEGORIA_FUNCION {
+		a.agregarError(fmt.Sprintf("'%s' no es una función", llamada.Nombre), linea, columna)
 		return
 	}
+	a.tabla.RegistrarLectura(llamada.Nombre)
+	if len(llamada.Argumentos) != len(simbolo.Parametros) {
+		a.agregarError(fmt.Sprintf("La función '%s' espera %d argumentos, pero recibió %d",
+			llamada.Nombre, len(simbolo.Parametros), len(llamada.Argumentos)), linea, columna)
+		return
+	}
+	for i, argumento := range llamada.Argumentos {
+		a.verificarTipo(argumento, simbolo.Parametros[i].Tipo,
+			fmt.Sprintf("El argumento %d de '%s'", i+1, llamada.Nombre))
+	}
+}
+
+// verificarTipo informa un error si se conoce el tipo de expr y es distinto
+// del esperado. Los literales numéricos son compatibles con cualquier tipo
+// entero.
+func (a *Analizador) verificarTipo(expr parser.Expresion, esperado, descripcion string) {
+	tipo := a.tipoExpresion(expr)
+	if tipo == "" || esperado == "" || tipo == esperado {
+		return
+	}
+	inicio := expr.Ubicacion().Inicio
+	a.agregarError(fmt.Sprintf("%s es de tipo %s, se esperaba %s", descripcion, tipo, esperado), inicio.Linea, inicio.Columna)
+}
 
+// tipoExpresion devuelve el tipo de una expresión, o "" si no se conoce
+func (a *Analizador) tipoExpresion(expr parser.Expresion) string {
 	switch e := expr.(type) {
 	case *parser.ExpresionIdentificador:
-		if e == nil {
-			return
-		}
-		// Para la condición del do-while, permitimos 'x' como excepción
-		// ya que en algunos casos puede ser una variable de control
-		if !a.tabla.EstaDeclarado(e.Valor) && e.Valor != "x" {
-			a.agregarError(fmt.Sprintf("Variable '%s' usada antes de ser declarada en condición", e.Valor), 0, 0)
+		if simbolo, ok := a.tabla.Obtener(e.Valor); ok && (simbolo.Categoria == CATEGORIA_VARIABLE || simbolo.Categoria == CATEGORIA_CONSTANTE || simbolo.Categoria == CATEGORIA_PARAMETRO) {
+			return simbolo.Tipo
 		}
-	case *parser.ExpresionBinaria:
-		if e == nil {
-			return
+	case *parser.ExpresionLlamada:
+		if simbolo, ok := a.tabla.Obtener(e.Nombre); ok && simbolo.Categoria == CATEGORIA_FUNCION {
+			return simbolo.Tipo
 		}
-		// Para operaciones de comparación en la condición, aplicar reglas especiales
-		// Solo verificar el lado derecho (números), permitir 'x' en el lado izquierdo
-		if e.Izquierda != nil {
-			if ident, ok := e.Izquierda.(*parser.ExpresionIdentificador); ok {
-				if ident.Valor != "x" && !a.tabla.EstaDeclarado(ident.Valor) {
-					a.agregarError(fmt.Sprintf("Variable '%s' usada antes de ser declarada en condición", ident.Valor), 0, 0)
-				}
-			} else {
-				a.verificarExpresion(e.Izquierda)
-			}
+	case *parser.ExpresionIndice:
+		if simbolo, ok := a.tabla.Obtener(e.Nombre); ok && simbolo.Categoria == CATEGORIA_ARREGLO {
+			return simbolo.Tipo
 		}
-		
-		// El lado derecho debe seguir las reglas normales
-		if e.Derecha != nil {
-			a.verificarExpresion(e.Derecha)
+	case *parser.ExpresionBinaria:
+		izquierda, derecha := a.tipoExpresion(e.Izquierda), a.tipoExpresion(e.Derecha)
+		if izquierda == "" {
+			return derecha
 		}
+		return izquierda
 	}
+	return ""
 }
 
-// verificarExpresion verifica el uso correcto de variables en expresiones
-func (a *Analizador) verificarExpresion(expr parser.Expresion) {
+// verificarCondicionDoWhile verifica la condición del do-while con reglas especiales
+func (a *Analizador) verificarCondicionDoWhile(expr parser.Expresion) {
 	if expr == nil {
 		return
 	}
 
 	switch e := expr.(type) {
 	case *parser.ExpresionIdentificador:
-		if e == nil {
-			return
-		}
-		// Verificar que la variable haya sido declarada
-		if !a.tabla.EstaDeclarado(e.Valor) {
-			a.agregarError(fmt.Sprintf("Variable '%s' usada antes de ser declarada", e.Valor), 0, 0)
+		// Para la condición del do-while, permitimos 'x' como excepción
+		// ya que en algunos casos puede ser una variable de control
+		if !a.tabla.EstaDeclarado(e.Valor) && e.Valor != "x" {
+			a.agregarError(fmt.Sprintf("Variable '%s' usada antes de ser declarada en condición", e.Valor), e.Inicio.Linea, e.Inicio.Columna)
 		}
+		a.tabla.RegistrarLectura(e.Valor)
 	case *parser.ExpresionBinaria:
-		if e == nil {
-			return
+		// Para operaciones de comparación en la condición, aplicar reglas especiales
+		// Solo verificar el lado derecho (números), permitir 'x' en el lado izquierdo
+		if ident, ok := e.Izquierda.(*parser.ExpresionIdentificador); ok {
+			if ident.Valor != "x" && !a.tabla.EstaDeclarado(ident.Valor) {
+				a.agregarError(fmt.Sprintf("Variable '%s' usada antes de ser declarada en condición", ident.Valor),
+					ident.Inicio.Linea, ident.Inicio.Columna)
+			}
+			a.tabla.RegistrarLectura(ident.Valor)
+		} else {
+			parser.Walk(&verificadorUsos{a}, e.Izquierda)
 		}
-		// Verificar ambos lados de la expresión binaria
-		a.verificarExpresion(e.Izquierda)
-		a.verificarExpresion(e.Derecha)
-	case *parser.ExpresionNumero:
-		// Los números son válidos por sí mismos, no necesitan verificación
-		return
+
+		// El lado derecho debe seguir las reglas normales
+		parser.Walk(&verificadorUsos{a}, e.Derecha)
 	}
 }
 
@@ -197,9 +464,16 @@ func (a *Analizador) agregarError(mensaje string, linea, columna int) {
 		Columna: columna,
 	}
 	a.errores = append(a.errores, error)
+	a.traza.Registrar(traza.Evento{
+		Fase:    traza.FASE_SEMANTICA,
+		Tipo:    traza.ERROR,
+		Mensaje: mensaje,
+		Linea:   linea,
+		Columna: columna,
+	})
 }
 
 // TablaSimbolos devuelve la tabla de símbolos
 func (a *Analizador) TablaSimbolos() *TablaSimbolos {
 	return a.tabla
-}
\ No newline at end of file
+}
@@ -0,0 +1,143 @@
+package semantic
+
+import (
+	"reflect"
+	"testing"
+
+	"analyzer-api/internal/lexer"
+	"analyzer-api/internal/parser"
+)
+
+// analizar analiza el código, que no debe tener errores de sintaxis
+func analizar(t *testing.T, codigo string) *Analizador {
+	t.Helper()
+	p := parser.New(lexer.New(codigo))
+	programa := p.Parse()
+	if errores := p.Errores(); len(errores) > 0 {
+		t.Fatalf("errores de sintaxis: %v", errores)
+	}
+	a := New(programa)
+	a.Analizar()
+	return a
+}
+
+func TestInferenciaDeValores(t *testing.T) {
+	codigo := `int a = 1;
+int b = a + 2;
+int c = 0;
+read(c);
+int d = 4;
+d = d * 2;
+int e = 0;
+do {
+	e = e + 1;
+} while (e == 3);
+int f(int n) {
+	int local = n + 1;
+	int fijo = 7;
+	return local;
+}
+int g = f(1);`
+	tabla := analizar(t, codigo).TablaSimbolos().ListarSimbolos()
+
+	casos := []struct {
+		clave        string
+		valor        interface{}
+		valorInicial interface{}
+	}{
+		{"a", int64(1), int64(1)},
+		{"b", int64(3), int64(3)}, // el inicial sale de la misma inferencia que el valor
+		{"c", nil, int64(0)},      // read lo vuelve desconocido
+		{"d", int64(8), int64(4)}, // la asignación cambia el valor, no el inicial
+		{"e", nil, int64(0)},      // el ciclo puede repetir la asignación
+		{"g", nil, nil},           // el valor de una llamada no se conoce
+		{"f.local", nil, nil},     // los parámetros no se conocen
+		{"f.fijo", int64(7), int64(7)},
+	}
+	for _, c := range casos {
+		simbolo, ok := tabla[c.clave]
+		if !ok {
+			t.Errorf("%s no está en la tabla", c.clave)
+			continue
+		}
+		if simbolo.Valor != c.valor || simbolo.ValorInicial != c.valorInicial {
+			t.Errorf("%s: Valor=%v ValorInicial=%v, se esperaba Valor=%v ValorInicial=%v",
+				c.clave, simbolo.Valor, simbolo.ValorInicial, c.valor, c.valorInicial)
+		}
+	}
+}
+
+func TestUsosDeSimbolos(t *testing.T) {
+	codigo := `int x = 1;
+x = x + 1;
+read(x);
+print(x);`
+	simbolo := analizar(t, codigo).TablaSimbolos().ListarSimbolos()["x"]
+	if simbolo.Lecturas != 2 || simbolo.Escrituras != 2 {
+		t.Errorf("Lecturas=%d Escrituras=%d, se esperaba 2 y 2", simbolo.Lecturas, simbolo.Escrituras)
+	}
+	if want := []Sitio{{Linea: 2, Columna: 1}, {Linea: 3, Columna: 1}}; !reflect.DeepEqual(simbolo.Asignaciones, want) {
+		t.Errorf("Asignaciones %v, se esperaba %v", simbolo.Asignaciones, want)
+	}
+}
+
+func TestFuncionRepetida(t *testing.T) {
+	// El cuerpo de la segunda f no se verifica con el ámbito de la primera:
+	// b no se informa como no declarada ni t se acepta como local
+	codigo := `int f(int a) { int t = a; return t; }
+int f(int b) { return b + t; }
+print(f(1));`
+	a := analizar(t, codigo)
+	want := []ErrorSemantico{{Mensaje: "Función 'f' ya declarada", Linea: 2, Columna: 1}}
+	if errores := a.errores; !reflect.DeepEqual(errores, want) {
+		t.Errorf("errores %v, se esperaba %v", errores, want)
+	}
+}
+
+// codigos devuelve los códigos de las advertencias
+func codigos(advertencias []Advertencia) []string {
+	var lista []string
+	for _, adv := range advertencias {
+		lista = append(lista, adv.Codigo)
+	}
+	return lista
+}
+
+func TestTerminacion(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		codigo  string
+		errores int
+		w005    bool
+	}{
+		{"siempre verdadera", "do { print(1); } while (1 == 1);", 1, false},
+		{"constante consigo misma", "const int K = 1; do { print(K); } while (K == K);", 1, false},
+		{"variable consigo misma", "int a = 1; do { a = a + 1; } while (a == a);", 1, false},
+		{"la condición no cambia", "int a = 0; do { print(a); } while (a == 0);", 0, true},
+		{"se asigna una constante que la cumple", "int a = 0; do { a = 0; } while (a == 0);", 0, true},
+		{"la variable cambia", "int a = 0; do { a = a + 1; } while (a == 5);", 0, false},
+		{"la variable se lee", "int a = 0; do { read(a); } while (a == 5);", 0, false},
+		{"termina tras una vuelta", "int a = 0; do { a = 1; } while (a == 0);", 0, false},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			a := analizar(t, c.codigo)
+			if got := len(a.errores); got != c.errores {
+				t.Errorf("%d errores %v, se esperaban %d", got, a.errores, c.errores)
+			}
+			w005 := false
+			for _, codigo := range codigos(a.Advertencias()) {
+				switch codigo {
+				case ADVERTENCIA_CICLO_INFINITO:
+					w005 = true
+				case ADVERTENCIA_AUTOCOMPARACION:
+					// La condición que ya es un error no se repite como advertencia
+					t.Errorf("advertencia %s junto al error de terminación", codigo)
+				}
+			}
+			if w005 != c.w005 {
+				t.Errorf("W005 = %v, se esperaba %v; advertencias %v", w005, c.w005, a.Advertencias())
+			}
+		})
+	}
+}
@@ -1,62 +1,199 @@
 package semantic
 
+import (
+	"strings"
+
+	"analyzer-api/internal/parser"
+)
+
+// AMBITO_GLOBAL es el ámbito de las variables y funciones de nivel superior.
+// Cada función abre un ámbito con su nombre para sus parámetros y variables.
+const AMBITO_GLOBAL = "global"
+
+// Categorías de símbolo
+const (
+	CATEGORIA_VARIABLE  = "variable"
+	CATEGORIA_CONSTANTE = "constante"
+	CATEGORIA_ARREGLO   = "arreglo"
+	CATEGORIA_PARAMETRO = "parametro"
+	CATEGORIA_FUNCION   = "funcion"
+)
+
+// Parametro describe un parámetro en la firma de una función
+type Parametro struct {
+	Nombre string
+	Tipo   string
+}
+
+// Sitio es la posición de una línea del código en que se usa un símbolo
+type Sitio struct {
+	Linea   int
+	Columna int
+}
+
 // Símbolo representa una entrada en la tabla de símbolos
 type Simbolo struct {
-	Nombre    string
-	Tipo      string
-	Valor     interface{}
-	Declarado bool
-	Linea     int
-	Columna   int
+	Nombre      string
+	Tipo        string      // en las funciones, el tipo del valor devuelto; en los arreglos, el de los elementos
+	Valor       interface{} // el valor conocido al compilar (int64) al terminar el código en que se declara, o nil
+	Declarado   bool
+	Linea       int
+	Columna     int
+	Categoria   string
+	Ambito      string
+	Parametros  []Parametro // solo en las funciones
+	Dimensiones []int       // solo en los arreglos: la cantidad de elementos
+
+	// Solo en las variables y constantes: la expresión con que se declaran
+	// y su valor, si se conoce al compilar. En las variables es el que
+	// infiere el mismo análisis que calcula Valor, en el punto de la
+	// declaración.
+	Inicializador parser.Expresion
+	ValorInicial  interface{}
+
+	Asignaciones []Sitio // dónde se le asigna un valor o se lee con read
+	Lecturas     int     // cuántas veces se usa su valor; en las funciones, cuántas veces se llaman
+	Escrituras   int     // cuántas veces se le asigna un valor, sin contar el inicializador
 }
 
-// TablaSimbolos mantiene un registro de las variables declaradas
+// Firma devuelve la firma de una función (int suma(int a, int b)), o "" si
+// el símbolo no es una función
+func (s Simbolo) Firma() string {
+	if s.Categoria != CATEGORIA_FUNCION {
+		return ""
+	}
+	parametros := make([]string, len(s.Parametros))
+	for i, p := range s.Parametros {
+		parametros[i] = p.Tipo + " " + p.Nombre
+	}
+	return s.Tipo + " " + s.Nombre + "(" + strings.Join(parametros, ", ") + ")"
+}
+
+// TablaSimbolos mantiene un registro de las variables y funciones
+// declaradas. Los nombres se buscan primero en el ámbito actual y después
+// en el global.
 type TablaSimbolos struct {
 	simbolos map[string]Simbolo
+	ambito   string
 }
 
 // NewTablaSimbolos crea una nueva tabla de símbolos
 func NewTablaSimbolos() *TablaSimbolos {
 	return &TablaSimbolos{
 		simbolos: make(map[string]Simbolo),
+		ambito:   AMBITO_GLOBAL,
+	}
+}
+
+// clave es el nombre con que se guarda un símbolo: el nombre solo en el
+// ámbito global y "funcion.nombre" en el de una función
+func clave(ambito, nombre string) string {
+	if ambito == AMBITO_GLOBAL {
+		return nombre
 	}
+	return ambito + "." + nombre
+}
+
+// EntrarAmbito hace que las definiciones y búsquedas siguientes usen el
+// ámbito de la función dada
+func (ts *TablaSimbolos) EntrarAmbito(funcion string) {
+	ts.ambito = funcion
+}
+
+// SalirAmbito vuelve al ámbito global
+func (ts *TablaSimbolos) SalirAmbito() {
+	ts.ambito = AMBITO_GLOBAL
 }
 
-// Definir agrega un símbolo a la tabla
+// Ambito devuelve el ámbito actual
+func (ts *TablaSimbolos) Ambito() string {
+	return ts.ambito
+}
+
+// Definir agrega una variable al ámbito actual
 func (ts *TablaSimbolos) Definir(nombre, tipo string, valor interface{}, linea, columna int) {
-	ts.simbolos[nombre] = Simbolo{
+	ts.DefinirSimbolo(Simbolo{
 		Nombre:    nombre,
 		Tipo:      tipo,
 		Valor:     valor,
-		Declarado: true,
 		Linea:     linea,
 		Columna:   columna,
-	}
+		Categoria: CATEGORIA_VARIABLE,
+	})
+}
+
+// DefinirSimbolo agrega un símbolo de cualquier categoría al ámbito actual
+func (ts *TablaSimbolos) DefinirSimbolo(simbolo Simbolo) {
+	simbolo.Declarado = true
+	simbolo.Ambito = ts.ambito
+	ts.simbolos[clave(ts.ambito, simbolo.Nombre)] = simbolo
 }
 
 // Actualizar actualiza el valor de un símbolo existente
 func (ts *TablaSimbolos) Actualizar(nombre string, valor interface{}) bool {
-	if simbolo, ok := ts.simbolos[nombre]; ok {
+	if simbolo, ok := ts.Obtener(nombre); ok {
 		simbolo.Valor = valor
-		ts.simbolos[nombre] = simbolo
+		ts.simbolos[clave(simbolo.Ambito, nombre)] = simbolo
 		return true
 	}
 	return false
 }
 
-// Obtener obtiene un símbolo de la tabla
+// ActualizarInicial cambia el valor inicial de un símbolo visible desde el
+// ámbito actual
+func (ts *TablaSimbolos) ActualizarInicial(nombre string, valor interface{}) bool {
+	if simbolo, ok := ts.Obtener(nombre); ok {
+		simbolo.ValorInicial = valor
+		ts.simbolos[clave(simbolo.Ambito, nombre)] = simbolo
+		return true
+	}
+	return false
+}
+
+// RegistrarLectura cuenta un uso del valor de un símbolo visible desde el
+// ámbito actual
+func (ts *TablaSimbolos) RegistrarLectura(nombre string) {
+	if simbolo, ok := ts.Obtener(nombre); ok {
+		simbolo.Lecturas++
+		ts.simbolos[clave(simbolo.Ambito, nombre)] = simbolo
+	}
+}
+
+// RegistrarEscritura cuenta una asignación a un símbolo visible desde el
+// ámbito actual y guarda dónde ocurre
+func (ts *TablaSimbolos) RegistrarEscritura(nombre string, sitio Sitio) {
+	if simbolo, ok := ts.Obtener(nombre); ok {
+		simbolo.Escrituras++
+		simbolo.Asignaciones = append(simbolo.Asignaciones, sitio)
+		ts.simbolos[clave(simbolo.Ambito, nombre)] = simbolo
+	}
+}
+
+// Obtener obtiene un símbolo visible desde el ámbito actual
 func (ts *TablaSimbolos) Obtener(nombre string) (Simbolo, bool) {
+	if simbolo, ok := ts.simbolos[clave(ts.ambito, nombre)]; ok {
+		return simbolo, true
+	}
 	simbolo, ok := ts.simbolos[nombre]
 	return simbolo, ok
 }
 
-// EstaDeclarado verifica si un símbolo ya fue declarado
+// EstaDeclarado verifica si un símbolo visible desde el ámbito actual ya
+// fue declarado
 func (ts *TablaSimbolos) EstaDeclarado(nombre string) bool {
-	_, ok := ts.simbolos[nombre]
+	_, ok := ts.Obtener(nombre)
 	return ok
 }
 
-// ListarSimbolos retorna todos los símbolos de la tabla
+// DeclaradoEnAmbito verifica si un símbolo ya fue declarado en el ámbito
+// actual, sin mirar el global
+func (ts *TablaSimbolos) DeclaradoEnAmbito(nombre string) bool {
+	_, ok := ts.simbolos[clave(ts.ambito, nombre)]
+	return ok
+}
+
+// ListarSimbolos retorna todos los símbolos de la tabla. Los de nivel
+// superior tienen su nombre como clave y los de una función "funcion.nombre".
 func (ts *TablaSimbolos) ListarSimbolos() map[string]Simbolo {
 	return ts.simbolos
-}
\ No newline at end of file
+}
@@ -0,0 +1,130 @@
+package semantic
+
+import "analyzer-api/internal/parser"
+
+// verificarTerminacion busca ciclos do-while que no terminan. Si la
+// condición es siempre verdadera informa un error y la marca en infinitas,
+// para que revisar no la informe otra vez como comparación consigo misma.
+// Si ninguna variable que lee la condición cambia en el cuerpo, o solo se le
+// asignan constantes con las que la condición se sigue cumpliendo, informa
+// una advertencia: la condición vale lo mismo en todas las vueltas y el
+// ciclo, si se repite una vez, se repite siempre.
+func (a *Analizador) verificarTerminacion(ciclo *parser.DeclaracionDoWhile) {
+	condicion := ciclo.Condicion
+	if condicion == nil {
+		return
+	}
+	inicio := condicion.Ubicacion().Inicio
+
+	if valor, ok := a.valorConstante(condicion); ok {
+		if valor != 0 {
+			a.agregarError("La condición del do-while siempre es verdadera: el ciclo nunca termina", inicio.Linea, inicio.Columna)
+			a.infinitas[condicion] = true
+		}
+		return
+	}
+	if b, ok := condicion.(*parser.ExpresionBinaria); ok && b.Operador == "==" &&
+		usaVariable(b.Izquierda) && mismaExpresion(b.Izquierda, b.Derecha) {
+		a.agregarError("La condición del do-while compara una expresión consigo misma y siempre es verdadera: el ciclo nunca termina",
+			inicio.Linea, inicio.Columna)
+		a.infinitas[condicion] = true
+		return
+	}
+
+	// Las variables que lee la condición. Si llama a una función, su valor
+	// puede cambiar en cada vuelta.
+	leidas := map[string]bool{}
+	llamada := false
+	parser.Inspect(condicion, func(n parser.Nodo) bool {
+		switch nodo := n.(type) {
+		case *parser.ExpresionIdentificador:
+			if k, ok := a.claveVisible(nodo.Valor); ok {
+				leidas[k] = true
+			}
+		case *parser.ExpresionIndice:
+			if k, ok := a.claveVisible(nodo.Nombre); ok {
+				leidas[k] = true
+			}
+		case *parser.ExpresionLlamada:
+			llamada = true
+		}
+		return true
+	})
+	if llamada || len(leidas) == 0 {
+		return
+	}
+
+	// El valor de cada variable al terminar el cuerpo, que se ejecuta en
+	// línea recta: una constante o, si cambia, desconocido
+	valores := map[string]int64{}
+	cambian := map[string]bool{}
+	cambiar := func(k string) {
+		cambian[k] = true
+		delete(valores, k)
+	}
+	for _, decl := range ciclo.Cuerpo {
+		parser.Inspect(decl, func(n parser.Nodo) bool {
+			switch nodo := n.(type) {
+			case *parser.ExpresionAsignacion:
+				k, ok := a.claveVisible(nodo.Nombre)
+				if !ok {
+					break
+				}
+				if valor, ok := a.valorConstante(nodo.Valor); ok && nodo.Indice == nil {
+					valores[k] = valor
+					delete(cambian, k)
+				} else {
+					cambiar(k)
+				}
+			case *parser.DeclaracionLectura:
+				if k, ok := a.claveVisible(nodo.Nombre); ok {
+					cambiar(k)
+				}
+			case *parser.ExpresionLlamada:
+				for _, k := range a.afectadas {
+					cambiar(k)
+				}
+			}
+			return true
+		})
+	}
+
+	asignadas := false
+	for k := range leidas {
+		if cambian[k] {
+			return
+		}
+		if _, ok := valores[k]; ok {
+			asignadas = true
+		}
+	}
+	if !asignadas {
+		a.agregarAdvertencia(ADVERTENCIA_CICLO_INFINITO,
+			"Ninguna variable de la condición del do-while cambia en el cuerpo: si la condición se cumple, el ciclo nunca termina",
+			inicio.Linea, inicio.Columna)
+		return
+	}
+	valor, ok := a.evaluar(condicion, valores)
+	switch {
+	case ok && valor == 0:
+		// El ciclo termina después de la primera vuelta
+	case ok:
+		a.agregarAdvertencia(ADVERTENCIA_CICLO_INFINITO,
+			"Con los valores que se asignan en el cuerpo, la condición del do-while se sigue cumpliendo: el ciclo nunca termina",
+			inicio.Linea, inicio.Columna)
+	default:
+		a.agregarAdvertencia(ADVERTENCIA_CICLO_INFINITO,
+			"El cuerpo del do-while solo asigna constantes a las variables de la condición: si se cumple después de la primera vuelta, el ciclo nunca termina",
+			inicio.Linea, inicio.Columna)
+	}
+}
+
+// claveVisible devuelve la clave del símbolo que se ve con ese nombre desde
+// el ámbito actual
+func (a *Analizador) claveVisible(nombre string) (string, bool) {
+	simbolo, ok := a.tabla.Obtener(nombre)
+	if !ok {
+		return "", false
+	}
+	return clave(simbolo.Ambito, simbolo.Nombre), true
+}
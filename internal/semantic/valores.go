@@ -0,0 +1,182 @@
+package semantic
+
+import "analyzer-api/internal/parser"
+
+// inferencia interpreta el programa de forma abstracta: recorre el código en
+// línea recta guardando el valor de cada variable mientras se conoce al
+// compilar. Una variable deja de conocerse cuando se le asigna un valor
+// desconocido, se lee con read, se modifica en el cuerpo de un do-while
+// (que puede repetirse) o en una función que se llama.
+type inferencia struct {
+	a         *Analizador
+	valores   map[string]int64 // por clave del símbolo, solo los que se conocen
+	afectadas []string         // claves de las variables globales que modifica alguna función
+}
+
+// inferirValores calcula el valor de cada variable al terminar el código en
+// que se declara (el programa o su función) y lo guarda en la tabla de
+// símbolos
+func (a *Analizador) inferirValores() {
+	global := &inferencia{a: a, valores: map[string]int64{}, afectadas: a.afectadas}
+	global.declaraciones(a.ast.Declaraciones)
+	global.guardar()
+
+	// Cada función empieza sin conocer sus parámetros ni las variables globales
+	for _, decl := range a.ast.Declaraciones {
+		funcion, ok := decl.(*parser.DeclaracionFuncion)
+		if !ok || !a.ambitoPropio(funcion) {
+			continue
+		}
+		a.tabla.EntrarAmbito(funcion.Nombre)
+		local := &inferencia{a: a, valores: map[string]int64{}, afectadas: a.afectadas}
+		local.declaraciones(funcion.Cuerpo)
+		local.guardar()
+		a.tabla.SalirAmbito()
+	}
+}
+
+// globalesModificadas devuelve las claves de las variables globales a las
+// que se asigna o en las que se lee un valor dentro de alguna función
+func (a *Analizador) globalesModificadas() []string {
+	var claves []string
+	for _, decl := range a.ast.Declaraciones {
+		funcion, ok := decl.(*parser.DeclaracionFuncion)
+		if !ok || !a.ambitoPropio(funcion) {
+			continue
+		}
+		a.tabla.EntrarAmbito(funcion.Nombre)
+		parser.Inspect(funcion, func(n parser.Nodo) bool {
+			if nombre, ok := destino(n); ok {
+				if simbolo, ok := a.tabla.Obtener(nombre); ok && simbolo.Ambito == AMBITO_GLOBAL {
+					claves = append(claves, clave(AMBITO_GLOBAL, nombre))
+				}
+			}
+			return true
+		})
+		a.tabla.SalirAmbito()
+	}
+	return claves
+}
+
+// destino devuelve el nombre de la variable que modifica una asignación o
+// una lectura
+func destino(n parser.Nodo) (string, bool) {
+	switch nodo := n.(type) {
+	case *parser.ExpresionAsignacion:
+		return nodo.Nombre, true
+	case *parser.DeclaracionLectura:
+		return nodo.Nombre, true
+	}
+	return "", false
+}
+
+// declaraciones interpreta una lista de declaraciones en orden
+func (inf *inferencia) declaraciones(declaraciones []parser.Declaracion) {
+	for _, decl := range declaraciones {
+		switch d := decl.(type) {
+		case *parser.DeclaracionVariable:
+			if !d.Constante {
+				inf.llamadas(d.Valor)
+				inf.asignar(d.Nombre, d.Valor)
+				inf.inicial(d)
+			}
+		case *parser.DeclaracionAsignacion:
+			inf.llamadas(d)
+			if d.Asignacion.Indice == nil {
+				inf.asignar(d.Asignacion.Nombre, d.Asignacion.Valor)
+			}
+		case *parser.DeclaracionLectura:
+			inf.olvidar(d.Nombre)
+		case *parser.DeclaracionDoWhile:
+			parser.Inspect(d, func(n parser.Nodo) bool {
+				if nombre, ok := destino(n); ok {
+					inf.olvidar(nombre)
+				}
+				if _, ok := n.(*parser.ExpresionLlamada); ok {
+					inf.olvidarAfectadas()
+				}
+				return true
+			})
+		case *parser.DeclaracionFuncion:
+			// Se interpreta aparte, en su propio ámbito
+		default:
+			inf.llamadas(decl)
+		}
+	}
+}
+
+// asignar guarda el valor de expr en la variable, o la olvida si no se conoce
+func (inf *inferencia) asignar(nombre string, expr parser.Expresion) {
+	simbolo, ok := inf.a.tabla.Obtener(nombre)
+	if !ok || (simbolo.Categoria != CATEGORIA_VARIABLE && simbolo.Categoria != CATEGORIA_PARAMETRO) {
+		return
+	}
+	if valor, ok := inf.a.evaluar(expr, inf.valores); ok {
+		inf.valores[clave(simbolo.Ambito, nombre)] = valor
+	} else {
+		delete(inf.valores, clave(simbolo.Ambito, nombre))
+	}
+}
+
+// inicial guarda en la tabla el valor que se conoce de la variable justo
+// después de su declaración. Una declaración repetida no cambia el símbolo
+// de la primera.
+func (inf *inferencia) inicial(d *parser.DeclaracionVariable) {
+	simbolo, ok := inf.a.tabla.Obtener(d.Nombre)
+	if !ok || simbolo.Categoria != CATEGORIA_VARIABLE ||
+		simbolo.Linea != d.Inicio.Linea || simbolo.Columna != d.Inicio.Columna {
+		return
+	}
+	if valor, ok := inf.valores[clave(simbolo.Ambito, d.Nombre)]; ok {
+		inf.a.tabla.ActualizarInicial(d.Nombre, valor)
+	}
+}
+
+// olvidar marca el valor de la variable como desconocido
+func (inf *inferencia) olvidar(nombre string) {
+	if simbolo, ok := inf.a.tabla.Obtener(nombre); ok {
+		delete(inf.valores, clave(simbolo.Ambito, nombre))
+	}
+}
+
+// olvidarAfectadas marca como desconocidas las variables globales que puede
+// modificar una llamada
+func (inf *inferencia) olvidarAfectadas() {
+	for _, k := range inf.afectadas {
+		delete(inf.valores, k)
+	}
+}
+
+// llamadas olvida las variables que pueden modificar las llamadas de n
+func (inf *inferencia) llamadas(n parser.Nodo) {
+	if n == nil {
+		return
+	}
+	parser.Inspect(n, func(n parser.Nodo) bool {
+		if _, ok := n.(*parser.ExpresionLlamada); ok {
+			inf.olvidarAfectadas()
+			return false
+		}
+		return true
+	})
+}
+
+// guardar actualiza en la tabla el valor de las variables y parámetros del
+// ámbito actual
+func (inf *inferencia) guardar() {
+	tabla := inf.a.tabla
+	var nombres []string
+	for _, simbolo := range tabla.ListarSimbolos() {
+		if simbolo.Ambito == tabla.Ambito() &&
+			(simbolo.Categoria == CATEGORIA_VARIABLE || simbolo.Categoria == CATEGORIA_PARAMETRO) {
+			nombres = append(nombres, simbolo.Nombre)
+		}
+	}
+	for _, nombre := range nombres {
+		if valor, ok := inf.valores[clave(tabla.Ambito(), nombre)]; ok {
+			tabla.Actualizar(nombre, valor)
+		} else {
+			tabla.Actualizar(nombre, nil)
+		}
+	}
+}
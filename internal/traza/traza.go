@@ -0,0 +1,90 @@
+package traza
+
+// TipoEvento clasifica los eventos registrados durante el análisis
+type TipoEvento string
+
+const (
+	TOKEN_LEIDO      TipoEvento = "token"        // el lexer emitió un token
+	REGLA_ENTRADA    TipoEvento = "entrada"      // el parser comenzó una regla
+	REGLA_SALIDA     TipoEvento = "salida"       // el parser terminó una regla
+	ERROR            TipoEvento = "error"        // se registró un error
+	ADVERTENCIA      TipoEvento = "advertencia"  // se registró una advertencia
+	ERROR_RECUPERADO TipoEvento = "recuperacion" // el parser se recuperó de un error
+	SIMBOLO_DEFINIDO TipoEvento = "simbolo"      // el analizador semántico definió un símbolo
+)
+
+// Fases del análisis que emiten eventos
+const (
+	FASE_LEXICA     = "lexica"
+	FASE_SINTACTICA = "sintactica"
+	FASE_SEMANTICA  = "semantica"
+)
+
+// Evento es un suceso del análisis. Solo se completan los campos que aplican
+// a su tipo.
+type Evento struct {
+	Fase        string     `json:"fase"`
+	Tipo        TipoEvento `json:"tipo"`
+	Regla       string     `json:"regla,omitempty"`
+	TipoToken   string     `json:"tipoToken,omitempty"`
+	Lexema      string     `json:"lexema,omitempty"`
+	Mensaje     string     `json:"mensaje,omitempty"`
+	Linea       int        `json:"linea"`
+	Columna     int        `json:"columna"`
+	Profundidad int        `json:"profundidad"` // anidamiento de reglas en el parser
+}
+
+// Trazador recibe los eventos del análisis. Las implementaciones no necesitan
+// ser seguras para uso concurrente: cada análisis usa su propio trazador.
+type Trazador interface {
+	Registrar(e Evento)
+}
+
+type nulo struct{}
+
+func (nulo) Registrar(Evento) {}
+
+// Nulo devuelve un trazador que descarta todos los eventos. Es el trazador
+// por defecto del lexer, el parser y el analizador semántico.
+func Nulo() Trazador {
+	return nulo{}
+}
+
+// LimitePorDefecto es el número máximo de eventos que conserva una Memoria
+// creada con límite 0
+const LimitePorDefecto = 10000
+
+// Memoria conserva en orden los eventos registrados hasta un límite
+type Memoria struct {
+	eventos     []Evento
+	limite      int
+	descartados int
+}
+
+// NuevaMemoria crea un trazador en memoria que conserva como máximo limite
+// eventos (LimitePorDefecto si limite es 0)
+func NuevaMemoria(limite int) *Memoria {
+	if limite <= 0 {
+		limite = LimitePorDefecto
+	}
+	return &Memoria{eventos: []Evento{}, limite: limite}
+}
+
+// Registrar conserva el evento o lo cuenta como descartado si se alcanzó el límite
+func (m *Memoria) Registrar(e Evento) {
+	if len(m.eventos) >= m.limite {
+		m.descartados++
+		return
+	}
+	m.eventos = append(m.eventos, e)
+}
+
+// Eventos devuelve los eventos conservados
+func (m *Memoria) Eventos() []Evento {
+	return m.eventos
+}
+
+// Descartados devuelve cuántos eventos no se conservaron por el límite
+func (m *Memoria) Descartados() int {
+	return m.descartados
+}
@@ -0,0 +1,183 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"analyzer-api/internal/compilacion"
+	"analyzer-api/internal/parser"
+)
+
+// Phase es una fase del análisis. Los valores son los mismos que acepta el
+// selector de fases de la API HTTP.
+type Phase string
+
+// Fases en el orden en que se ejecutan. Cada una necesita las anteriores.
+const (
+	PhaseLexical   Phase = "lexico"
+	PhaseSyntactic Phase = "sintactico"
+	PhaseSemantic  Phase = "semantico"
+)
+
+// Phases devuelve todas las fases en el orden en que se ejecutan
+func Phases() []Phase {
+	return []Phase{PhaseLexical, PhaseSyntactic, PhaseSemantic}
+}
+
+// Options configura un análisis. El valor cero analiza con el dialecto base
+// y ejecuta todas las fases.
+type Options struct {
+	// Dialect es el dialecto del código; nil para el dialecto base
+	Dialect *Dialect
+	// Phase es la última fase que se ejecuta; vacío para todas
+	Phase Phase
+}
+
+// Analyze analiza source ejecutando las fases hasta opts.Phase. Los errores
+// del programa no hacen fallar a Analyze: se devuelven en
+// Result.Diagnostics. Devuelve un error solo si las opciones son inválidas
+// o si ctx termina antes que el análisis, lo que se comprueba entre fase y
+// fase.
+func Analyze(ctx context.Context, source string, opts Options) (*Result, error) {
+	ultima := opts.Phase
+	if ultima == "" {
+		ultima = PhaseSemantic
+	}
+	if !ultima.Valid() {
+		return nil, fmt.Errorf("analyzer: fase desconocida: %s", ultima)
+	}
+
+	u := compilacion.Nueva(source, compilacion.ConDialecto(opts.Dialect.dialecto()))
+	res := &Result{
+		Source:      source,
+		Diagnostics: []Diagnostic{},
+	}
+
+	for _, fase := range Phases() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		switch fase {
+		case PhaseLexical:
+			res.Tokens = convertirTokens(u)
+			for _, e := range u.ErroresLexicos() {
+				res.Diagnostics = append(res.Diagnostics, Diagnostic{
+					Phase: PhaseLexical, Severity: SeverityError, Message: e.Mensaje, Line: e.Linea, Column: e.Columna,
+				})
+			}
+		case PhaseSyntactic:
+			res.AST = convertirNodo(parser.CodificarNodo(u.Programa()))
+			for _, e := range u.ErroresSintacticos() {
+				res.Diagnostics = append(res.Diagnostics, Diagnostic{
+					Phase: PhaseSyntactic, Severity: SeverityError, Message: e.Mensaje, Line: e.Linea, Column: e.Columna,
+				})
+			}
+		case PhaseSemantic:
+			for _, e := range u.ErroresSemanticos() {
+				res.Diagnostics = append(res.Diagnostics, Diagnostic{
+					Phase: PhaseSemantic, Severity: SeverityError, Message: e.Mensaje, Line: e.Linea, Column: e.Columna,
+				})
+			}
+			for _, a := range u.Advertencias() {
+				res.Diagnostics = append(res.Diagnostics, Diagnostic{
+					Phase: PhaseSemantic, Severity: SeverityWarning, Code: a.Codigo, Message: a.Mensaje, Line: a.Linea, Column: a.Columna,
+				})
+			}
+			res.Symbols = convertirSimbolos(u)
+		}
+		res.Phases = append(res.Phases, fase)
+		if fase == ultima {
+			break
+		}
+	}
+	return res, nil
+}
+
+// Valid indica si la fase es una de las de Phases
+func (f Phase) Valid() bool {
+	for _, fase := range Phases() {
+		if fase == f {
+			return true
+		}
+	}
+	return false
+}
+
+// convertirTokens copia los tokens de la unidad, incluido EOF
+func convertirTokens(u *compilacion.Unidad) []Token {
+	tokens := u.Tokens()
+	resultado := make([]Token, len(tokens))
+	for i, t := range tokens {
+		resultado[i] = Token{
+			Type:     string(t.Type),
+			Lexeme:   t.Lexeme,
+			Category: t.Clase(),
+			Pos:      Position{Offset: t.Offset, Line: t.Line, Column: t.Column},
+		}
+	}
+	return resultado
+}
+
+// convertirNodo copia la forma serializable de un nodo del AST
+func convertirNodo(j *parser.NodoJSON) *Node {
+	if j == nil {
+		return nil
+	}
+	n := &Node{
+		Kind:     j.Kind,
+		Role:     j.Rol,
+		Span:     convertirRango(j.Rango),
+		Type:     j.Tipo,
+		Name:     j.Nombre,
+		Value:    j.Valor,
+		Operator: j.Operador,
+		Constant: j.Constante,
+		Children: make([]*Node, len(j.Children)),
+	}
+	for i, hijo := range j.Children {
+		n.Children[i] = convertirNodo(hijo)
+	}
+	return n
+}
+
+func convertirRango(r parser.Rango) Span {
+	return Span{
+		Start: Position{Offset: r.Inicio.Offset, Line: r.Inicio.Linea, Column: r.Inicio.Columna},
+		End:   Position{Offset: r.Fin.Offset, Line: r.Fin.Linea, Column: r.Fin.Columna},
+	}
+}
+
+// convertirSimbolos copia la tabla de símbolos en el orden del código fuente
+func convertirSimbolos(u *compilacion.Unidad) []Symbol {
+	simbolos := []Symbol{}
+	for _, s := range u.TablaSimbolos().ListarSimbolos() {
+		simbolo := Symbol{
+			Name:       s.Nombre,
+			Type:       s.Tipo,
+			Kind:       s.Categoria,
+			Scope:      s.Ambito,
+			Signature:  s.Firma(),
+			Dimensions: s.Dimensiones,
+			Line:       s.Linea,
+			Column:     s.Columna,
+			Reads:      s.Lecturas,
+			Writes:     s.Escrituras,
+		}
+		if v, ok := s.Valor.(int64); ok {
+			simbolo.Value, simbolo.HasValue = v, true
+		}
+		simbolos = append(simbolos, simbolo)
+	}
+	sort.Slice(simbolos, func(i, j int) bool {
+		a, b := simbolos[i], simbolos[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return a.Name < b.Name
+	})
+	return simbolos
+}
@@ -0,0 +1,150 @@
+package analyzer_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"analyzer-api/pkg/analyzer"
+)
+
+// Estas pruebas fijan el comportamiento que promete la documentación del
+// paquete; si alguna falla, el cambio rompe la compatibilidad de la API
+
+func analizar(t *testing.T, codigo string, opts analyzer.Options) *analyzer.Result {
+	t.Helper()
+	res, err := analyzer.Analyze(context.Background(), codigo, opts)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	return res
+}
+
+// El valor cero de Options ejecuta todas las fases con el dialecto base
+func TestOpcionesCero(t *testing.T) {
+	res := analizar(t, "int x = 5;\nprint(x);", analyzer.Options{})
+	if !reflect.DeepEqual(res.Phases, analyzer.Phases()) {
+		t.Errorf("fases %v, se esperaban %v", res.Phases, analyzer.Phases())
+	}
+	if res.AST == nil || res.AST.Kind != "Programa" || len(res.AST.Children) != 2 {
+		t.Errorf("AST %+v, se esperaba un Programa con 2 declaraciones", res.AST)
+	}
+	if len(res.Symbols) != 1 || res.Symbols[0].Name != "x" || !res.Symbols[0].HasValue || res.Symbols[0].Value != 5 {
+		t.Errorf("símbolos %+v, se esperaba x con valor 5", res.Symbols)
+	}
+	if len(res.Diagnostics) != 0 {
+		t.Errorf("diagnósticos %v, no se esperaba ninguno", res.Diagnostics)
+	}
+}
+
+// Un Dialect nil o sin cargar es el dialecto base
+func TestDialectoCero(t *testing.T) {
+	var nulo *analyzer.Dialect
+	for _, d := range []*analyzer.Dialect{nulo, {}} {
+		if got := d.Name(); got != nulo.Name() || got == "" {
+			t.Errorf("Name() = %q, se esperaba el nombre del dialecto base", got)
+		}
+		res := analizar(t, "int x = 5;\nprint(x);", analyzer.Options{Dialect: d})
+		if len(res.Diagnostics) != 0 || len(res.Tokens) != 11 || res.Tokens[0].Category != "PR" {
+			t.Errorf("tokens %+v y diagnósticos %v, se esperaba el análisis con el dialecto base", res.Tokens, res.Diagnostics)
+		}
+	}
+}
+
+// Phase es la última fase que se ejecuta; los campos de las siguientes
+// quedan vacíos
+func TestUltimaFase(t *testing.T) {
+	// y no está declarada: es un error semántico
+	const codigo = "int x = 1;\ny = 2;"
+	casos := []struct {
+		fase    analyzer.Phase
+		fases   []analyzer.Phase
+		ast     bool
+		errores int
+	}{
+		{analyzer.PhaseLexical, []analyzer.Phase{analyzer.PhaseLexical}, false, 0},
+		{analyzer.PhaseSyntactic, []analyzer.Phase{analyzer.PhaseLexical, analyzer.PhaseSyntactic}, true, 0},
+		{analyzer.PhaseSemantic, analyzer.Phases(), true, 1},
+	}
+	for _, c := range casos {
+		t.Run(string(c.fase), func(t *testing.T) {
+			res := analizar(t, codigo, analyzer.Options{Phase: c.fase})
+			if !reflect.DeepEqual(res.Phases, c.fases) {
+				t.Errorf("fases %v, se esperaban %v", res.Phases, c.fases)
+			}
+			if len(res.Tokens) == 0 {
+				t.Error("sin tokens")
+			}
+			if (res.AST != nil) != c.ast {
+				t.Errorf("AST %+v", res.AST)
+			}
+			if c.fase != analyzer.PhaseSemantic && len(res.Symbols) != 0 {
+				t.Errorf("símbolos %+v sin la fase semántica", res.Symbols)
+			}
+			if len(res.Errors()) != c.errores {
+				t.Errorf("errores %v, se esperaban %d", res.Errors(), c.errores)
+			}
+		})
+	}
+}
+
+func TestFaseDesconocida(t *testing.T) {
+	if _, err := analyzer.Analyze(context.Background(), "", analyzer.Options{Phase: "otra"}); err == nil {
+		t.Error("se esperaba un error por la fase desconocida")
+	}
+}
+
+// Si el contexto termina antes del análisis, Analyze devuelve ctx.Err()
+func TestContextoCancelado(t *testing.T) {
+	ctx, cancelar := context.WithCancel(context.Background())
+	cancelar()
+	res, err := analyzer.Analyze(ctx, "int x = 1;", analyzer.Options{})
+	if !errors.Is(err, context.Canceled) || res != nil {
+		t.Errorf("Analyze = %v, %v; se esperaba nil, context.Canceled", res, err)
+	}
+}
+
+// Tokens termina siempre con un token EOF, también en un programa vacío
+func TestTokensTerminanEnEOF(t *testing.T) {
+	for _, codigo := range []string{"", "int x = 1;"} {
+		res := analizar(t, codigo, analyzer.Options{Phase: analyzer.PhaseLexical})
+		ultimo := res.Tokens[len(res.Tokens)-1]
+		if ultimo.Type != "EOF" || ultimo.Category != "EOF" {
+			t.Errorf("%q: último token %+v, se esperaba EOF", codigo, ultimo)
+		}
+	}
+	res := analizar(t, "int x = 1;", analyzer.Options{})
+	tipos := []string{}
+	for _, tok := range res.Tokens {
+		tipos = append(tipos, tok.Type)
+	}
+	if esperados := []string{"INT", "IDENT", "ASSIGN", "NUMBER", "SEMI", "EOF"}; !reflect.DeepEqual(tipos, esperados) {
+		t.Errorf("tipos %v, se esperaban %v", tipos, esperados)
+	}
+}
+
+func TestErroresYAdvertencias(t *testing.T) {
+	// z no está declarada y sin se declara pero no se usa
+	res := analizar(t, "int sin = 1;\nz = 2;", analyzer.Options{})
+	errores, advertencias := res.Errors(), res.Warnings()
+	if len(errores) != 1 || errores[0].Phase != analyzer.PhaseSemantic || errores[0].Line != 2 || errores[0].Code != "" {
+		t.Errorf("errores %v, se esperaba uno semántico en la línea 2", errores)
+	}
+	if len(advertencias) != 1 || advertencias[0].Code != "W001" || advertencias[0].Severity != analyzer.SeverityWarning {
+		t.Errorf("advertencias %v, se esperaba W001", advertencias)
+	}
+	if !res.HasErrors() {
+		t.Error("HasErrors() = false")
+	}
+	if s := advertencias[0].String(); !strings.HasPrefix(s, "1:1: warning: ") || !strings.HasSuffix(s, " [W001]") {
+		t.Errorf("String() = %q", s)
+	}
+
+	// Las advertencias solas no son errores
+	res = analizar(t, "int sin = 1;", analyzer.Options{})
+	if res.HasErrors() || len(res.Errors()) != 0 || len(res.Warnings()) != 1 {
+		t.Errorf("errores %v y advertencias %v", res.Errors(), res.Warnings())
+	}
+}
@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"io"
+
+	"analyzer-api/internal/lexer"
+)
+
+// Dialect es una definición del lenguaje: sus palabras reservadas,
+// operadores y la forma de los identificadores y números. El valor nil y el
+// valor cero representan el dialecto base.
+type Dialect struct {
+	d *lexer.Dialecto
+}
+
+// Name devuelve el nombre del dialecto
+func (d *Dialect) Name() string {
+	return d.dialecto().Nombre
+}
+
+func (d *Dialect) dialecto() *lexer.Dialecto {
+	if d == nil || d.d == nil {
+		return lexer.DialectoBase()
+	}
+	return d.d
+}
+
+// LoadDialect lee la especificación JSON de un dialecto, con el formato de
+// los archivos del directorio dialectos
+func LoadDialect(r io.Reader) (*Dialect, error) {
+	d, err := lexer.CargarDialecto(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Dialect{d: d}, nil
+}
+
+// LoadDialectFile lee la especificación de un dialecto desde un archivo
+func LoadDialectFile(path string) (*Dialect, error) {
+	d, err := lexer.CargarDialectoArchivo(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Dialect{d: d}, nil
+}
+
+// LoadDialects carga todos los archivos *.json de dir como dialectos,
+// indexados por nombre. Siempre incluye el dialecto base.
+func LoadDialects(dir string) (map[string]*Dialect, error) {
+	cargados, err := lexer.CargarDialectosDirectorio(dir)
+	if err != nil {
+		return nil, err
+	}
+	dialectos := make(map[string]*Dialect, len(cargados))
+	for nombre, d := range cargados {
+		dialectos[nombre] = &Dialect{d: d}
+	}
+	return dialectos, nil
+}
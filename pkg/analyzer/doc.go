@@ -0,0 +1,35 @@
+// Package analyzer es la API pública del analizador para usarlo desde otros
+// programas Go sin levantar el servidor HTTP. Analyze ejecuta las fases
+// léxica, sintáctica y semántica sobre un programa y devuelve sus tokens, el
+// árbol sintáctico, los diagnósticos y la tabla de símbolos:
+//
+//	res, err := analyzer.Analyze(ctx, "int x = 5; print(x);", analyzer.Options{})
+//	if err != nil {
+//		return err // solo si ctx se canceló o las opciones son inválidas
+//	}
+//	for _, d := range res.Diagnostics {
+//		fmt.Println(d)
+//	}
+//
+// # Compatibilidad
+//
+// El paquete sigue el versionado semántico y su versión está en Version.
+// Dentro de una misma versión mayor:
+//
+//   - no se quitan ni cambian de tipo los identificadores exportados, ni los
+//     campos de los tipos exportados;
+//   - pueden agregarse funciones, tipos, campos y valores de Phase, Severity
+//     o Node.Kind nuevos, de modo que conviene no construir los tipos del
+//     paquete con literales sin nombres de campo ni suponer que un switch
+//     sobre ellos es exhaustivo;
+//   - los códigos de las advertencias (Diagnostic.Code) y los valores de
+//     Phase, Severity, Token.Type, Token.Category, Node.Kind, Node.Role y
+//     Symbol.Kind conservan su significado.
+//
+// Los textos de Diagnostic.Message pueden cambiar en cualquier versión y no
+// deberían compararse. Los paquetes bajo internal/ no forman parte de la API
+// y pueden cambiar sin aviso.
+package analyzer
+
+// Version es la versión de la API de este paquete
+const Version = "1.0.0"
@@ -0,0 +1,148 @@
+package analyzer
+
+import "fmt"
+
+// Result es el resultado de Analyze. Los campos de las fases que no se
+// ejecutaron quedan vacíos.
+type Result struct {
+	Source string
+	// Phases son las fases que se ejecutaron, en orden
+	Phases []Phase
+	// Tokens son todos los tokens del programa, terminando con uno de tipo EOF
+	Tokens []Token
+	// AST es el árbol sintáctico, que puede estar incompleto si hay errores
+	// de sintaxis; nil si no se ejecutó la fase sintáctica
+	AST *Node
+	// Diagnostics son los errores y advertencias, agrupados por fase
+	Diagnostics []Diagnostic
+	// Symbols es la tabla de símbolos en el orden del código fuente
+	Symbols []Symbol
+}
+
+// Errors devuelve los diagnósticos de severidad error
+func (r *Result) Errors() []Diagnostic {
+	return r.filtrar(SeverityError)
+}
+
+// Warnings devuelve las advertencias
+func (r *Result) Warnings() []Diagnostic {
+	return r.filtrar(SeverityWarning)
+}
+
+// HasErrors indica si alguna fase encontró errores
+func (r *Result) HasErrors() bool {
+	return len(r.Errors()) > 0
+}
+
+func (r *Result) filtrar(severidad Severity) []Diagnostic {
+	var resultado []Diagnostic
+	for _, d := range r.Diagnostics {
+		if d.Severity == severidad {
+			resultado = append(resultado, d)
+		}
+	}
+	return resultado
+}
+
+// Position es una posición en el código fuente. Offset cuenta bytes desde
+// 0; Line y Column empiezan en 1.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// Span es el rango [Start, End) que ocupa un nodo en el código fuente
+type Span struct {
+	Start Position
+	End   Position
+}
+
+// Token es un token del programa
+type Token struct {
+	// Type es el tipo del token: "INT", "IDENT", "NUMBER", "PLUS", "EOF",
+	// "ERROR"...
+	Type   string
+	Lexeme string
+	// Category es la categoría del token, igual en todos los dialectos:
+	// "PR", "ID", "Numeros", "Simbolos", "EOF" o "Error"
+	Category string
+	Pos      Position
+}
+
+// Node es un nodo del árbol sintáctico. Todos los nodos comparten la misma
+// estructura, la de la representación JSON del AST en la API HTTP: el tipo
+// en Kind ("Programa", "DeclaracionVariable", "ExpresionBinaria"...), sus
+// atributos y los hijos en orden, cada uno con el rol que ocupa en el padre.
+type Node struct {
+	Kind     string
+	Role     string // "declaracion", "valor", "cuerpo", "condicion"...; vacío en la raíz
+	Span     Span
+	Type     string // tipo declarado, p. ej. "int"
+	Name     string // nombre de la variable, función o arreglo
+	Value    string // literal de un número o nombre de un identificador
+	Operator string
+	Constant bool // la declaración es const
+	Children []*Node
+}
+
+// Walk recorre el nodo y sus descendientes en preorden. Si fn devuelve
+// false no se recorren los hijos de ese nodo.
+func (n *Node) Walk(fn func(*Node) bool) {
+	if n == nil || !fn(n) {
+		return
+	}
+	for _, hijo := range n.Children {
+		hijo.Walk(fn)
+	}
+}
+
+// Severity indica si un diagnóstico impide ejecutar el programa
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic es un error o advertencia encontrado en una fase
+type Diagnostic struct {
+	Phase    Phase
+	Severity Severity
+	// Code identifica el tipo de advertencia ("W001"...); vacío en los errores
+	Code    string
+	Message string
+	Line    int
+	Column  int
+}
+
+// String escribe el diagnóstico como "línea:columna: severidad: mensaje"
+func (d Diagnostic) String() string {
+	if d.Code != "" {
+		return fmt.Sprintf("%d:%d: %s: %s [%s]", d.Line, d.Column, d.Severity, d.Message, d.Code)
+	}
+	return fmt.Sprintf("%d:%d: %s: %s", d.Line, d.Column, d.Severity, d.Message)
+}
+
+// Symbol es una entrada de la tabla de símbolos
+type Symbol struct {
+	Name string
+	// Type es el tipo de la variable; en las funciones, el del valor
+	// devuelto, y en los arreglos, el de sus elementos
+	Type string
+	// Kind es "variable", "constante", "arreglo", "parametro" o "funcion"
+	Kind string
+	// Scope es "global" o el nombre de la función en que se declara
+	Scope     string
+	Signature string // solo en las funciones
+	// Dimensions es la cantidad de elementos, solo en los arreglos
+	Dimensions []int
+	// Value es el valor que se conoce al compilar al terminar el código en
+	// que se declara, si HasValue
+	Value    int64
+	HasValue bool
+	Line     int
+	Column   int
+	Reads    int // veces que se usa su valor; en las funciones, veces que se llaman
+	Writes   int // veces que se le asigna un valor, sin contar el inicializador
+}
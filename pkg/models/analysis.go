@@ -6,13 +6,18 @@ import (
 	"analyzer-api/internal/semantic"
 )
 
-// TokenInfo representa la información de un token para la API
+// TokenInfo representa la información de un token para la API. Tipo es la
+// categoría general del token (PR, ID, Numeros, Simbolos), la misma en todos
+// los dialectos, y Categoria la misma categoría con el nombre que le da el
+// dialecto. TipoToken es el tipo concreto del token (INT, IDENT, NUMBER,
+// ASSIGN, ...).
 type TokenInfo struct {
-	Tipo     string `json:"tipo"`
-	Lexema   string `json:"lexema"`
-	Linea    int    `json:"linea"`
-	Columna  int    `json:"columna"`
+	Tipo      string `json:"tipo"`
+	Lexema    string `json:"lexema"`
+	Linea     int    `json:"linea"`
+	Columna   int    `json:"columna"`
 	Categoria string `json:"categoria"`
+	TipoToken string `json:"tipoToken"`
 }
 
 // ConteoTokens representa el conteo de tokens por categoría
@@ -27,97 +32,153 @@ type ConteoTokens struct {
 
 // ErrorInfo representa la información de un error para la API
 type ErrorInfo struct {
-	Tipo     string `json:"tipo"` // "sintactico" o "semantico"
-	Mensaje  string `json:"mensaje"`
-	Linea    int    `json:"linea"`
-	Columna  int    `json:"columna"`
+	Tipo    string `json:"tipo"` // "lexico", "sintactico", "semantico" o "ejecucion"
+	Mensaje string `json:"mensaje"`
+	Linea   int    `json:"linea"`
+	Columna int    `json:"columna"`
+	Codigo  string `json:"codigo,omitempty"` // solo en las advertencias, p. ej. "W001"
 }
 
 // SimboloInfo representa la información de un símbolo para la API
 type SimboloInfo struct {
-	Nombre   string      `json:"nombre"`
-	Tipo     string      `json:"tipo"`
-	Valor    interface{} `json:"valor"`
-	Linea    int         `json:"linea"`
-	Columna  int         `json:"columna"`
+	Nombre      string      `json:"nombre"`
+	Tipo        string      `json:"tipo"`
+	Valor       interface{} `json:"valor"`
+	Linea       int         `json:"linea"`
+	Columna     int         `json:"columna"`
+	Categoria   string      `json:"categoria"`
+	Ambito      string      `json:"ambito"`
+	Firma       string      `json:"firma,omitempty"`
+	Dimensiones []int       `json:"dimensiones,omitempty"`
+
+	// Inicializador es el texto de la expresión con que se declara la
+	// variable o constante, y ValorInicial su valor si se conoce al compilar.
+	// Valor es el que se conoce al terminar el código en que se declara.
+	Inicializador string      `json:"inicializador,omitempty"`
+	ValorInicial  interface{} `json:"valorInicial,omitempty"`
+	Asignaciones  []SitioInfo `json:"asignaciones,omitempty"`
+	Lecturas      int         `json:"lecturas"`
+	Escrituras    int         `json:"escrituras"`
+}
+
+// SitioInfo representa la posición de un uso de un símbolo para la API
+type SitioInfo struct {
+	Linea   int `json:"linea"`
+	Columna int `json:"columna"`
+}
+
+// EventoInfo representa un evento de la traza de las fases para la API
+type EventoInfo struct {
+	Fase        string `json:"fase"`
+	Tipo        string `json:"tipo"`
+	Regla       string `json:"regla,omitempty"`
+	TipoToken   string `json:"tipoToken,omitempty"`
+	Lexema      string `json:"lexema,omitempty"`
+	Mensaje     string `json:"mensaje,omitempty"`
+	Linea       int    `json:"linea"`
+	Columna     int    `json:"columna"`
+	Profundidad int    `json:"profundidad"` // anidamiento de reglas en el parser
 }
 
 // ResultadoAnalisis representa el resultado completo del análisis
 type ResultadoAnalisis struct {
+	Fases        []string      `json:"fases"` // las fases cuyos resultados contiene
 	Tokens       []TokenInfo   `json:"tokens"`
 	ConteoTokens ConteoTokens  `json:"conteoTokens"`
 	Errores      []ErrorInfo   `json:"errores"`
+	Advertencias []ErrorInfo   `json:"advertencias"`
 	Simbolos     []SimboloInfo `json:"simbolos"`
 	CodigoFuente string        `json:"codigoFuente"`
+	AST          *NodoInfo     `json:"ast"`
+
+	// Traza de las fases, solo si la solicitud la pidió
+	Traza            []EventoInfo `json:"traza,omitempty"`
+	TrazaDescartados int          `json:"trazaDescartados,omitempty"`
+
+	// Árbol concreto y derivación por la izquierda, solo si la solicitud los pidió
+	CST        *NodoConcretoInfo    `json:"cst,omitempty"`
+	Derivacion []PasoDerivacionInfo `json:"derivacion,omitempty"`
+
+	// Ejecución del programa, solo si la solicitud la pidió
+	Ejecucion *ResultadoEjecucion `json:"ejecucion,omitempty"`
+
+	// Métricas del programa, si se construyó el árbol
+	Metricas *MetricasInfo `json:"metricas,omitempty"`
 }
 
-// NuevoResultadoAnalisis crea un nuevo resultado de análisis a partir de los componentes
+// NuevoResultadoAnalisis crea un resultado de análisis a partir de los
+// componentes, con los tokens, los errores de las tres fases, las
+// advertencias y los símbolos. Ejecuta el análisis semántico de sem, así que
+// no debe haberse ejecutado antes.
+//
+// Deprecated: no incluye el árbol, las métricas ni la traza. El servidor
+// arma el resultado completo a partir de una unidad de compilación, que
+// ejecuta cada fase una sola vez; fuera del servidor conviene usar el
+// paquete analyzer.
 func NuevoResultadoAnalisis(
 	lex *lexer.Lexer,
-	p *parser.Parser, 
+	p *parser.Parser,
 	sem *semantic.Analizador,
 	codigoFuente string,
 ) *ResultadoAnalisis {
 	resultado := &ResultadoAnalisis{
+		Fases:        Fases,
 		Tokens:       []TokenInfo{},
 		Errores:      []ErrorInfo{},
+		Advertencias: []ErrorInfo{},
 		Simbolos:     []SimboloInfo{},
 		CodigoFuente: codigoFuente,
 	}
 
-	// Convertir tokens a TokenInfo
+	// Analizar no vuelve a leer los tokens que ya consumió el parser
 	tokens := lex.Analizar()
 	for _, token := range tokens {
 		resultado.Tokens = append(resultado.Tokens, TokenInfo{
-			Tipo:     string(token.Type),
-			Lexema:   token.Lexeme,
-			Linea:    token.Line,
-			Columna:  token.Column,
+			Tipo:      token.Clase(),
+			Lexema:    token.Lexeme,
+			Linea:     token.Line,
+			Columna:   token.Column,
 			Categoria: token.Categoria(),
+			TipoToken: string(token.Type),
 		})
 	}
 
-	// Obtener conteo de tokens
 	conteo := lex.ContarTokens()
 	resultado.ConteoTokens = ConteoTokens{
-		PR:       conteo["PR"],
-		ID:       conteo["ID"],
-		Numeros:  conteo["Numeros"],
-		Simbolos: conteo["Simbolos"],
-		Error:    conteo["Error"],
+		PR:       conteo[lexer.CATEGORIA_PR],
+		ID:       conteo[lexer.CATEGORIA_ID],
+		Numeros:  conteo[lexer.CATEGORIA_NUMEROS],
+		Simbolos: conteo[lexer.CATEGORIA_SIMBOLOS],
+		Error:    conteo[lexer.CATEGORIA_ERROR],
 		Total:    len(tokens) - 1, // Restamos 1 para no contar EOF
 	}
 
-	// Convertir errores sintácticos a ErrorInfo
+	for _, err := range lex.Errores() {
+		resultado.Errores = append(resultado.Errores, ErrorInfo{Tipo: FASE_LEXICA, Mensaje: err.Mensaje, Linea: err.Linea, Columna: err.Columna})
+	}
 	for _, err := range p.Errores() {
-		resultado.Errores = append(resultado.Errores, ErrorInfo{
-			Tipo:    "sintactico",
-			Mensaje: err.Mensaje,
-			Linea:   err.Linea,
-			Columna: err.Columna,
-		})
+		resultado.Errores = append(resultado.Errores, ErrorInfo{Tipo: FASE_SINTACTICA, Mensaje: err.Mensaje, Linea: err.Linea, Columna: err.Columna})
 	}
-
-	// Convertir errores semánticos a ErrorInfo
 	for _, err := range sem.Analizar() {
-		resultado.Errores = append(resultado.Errores, ErrorInfo{
-			Tipo:    "semantico",
-			Mensaje: err.Mensaje,
-			Linea:   err.Linea,
-			Columna: err.Columna,
-		})
+		resultado.Errores = append(resultado.Errores, ErrorInfo{Tipo: FASE_SEMANTICA, Mensaje: err.Mensaje, Linea: err.Linea, Columna: err.Columna})
+	}
+	for _, adv := range sem.Advertencias() {
+		resultado.Advertencias = append(resultado.Advertencias, ErrorInfo{Tipo: FASE_SEMANTICA, Mensaje: adv.Mensaje, Linea: adv.Linea, Columna: adv.Columna, Codigo: adv.Codigo})
 	}
 
-	// Convertir símbolos a SimboloInfo
 	for _, simbolo := range sem.TablaSimbolos().ListarSimbolos() {
 		resultado.Simbolos = append(resultado.Simbolos, SimboloInfo{
-			Nombre:  simbolo.Nombre,
-			Tipo:    simbolo.Tipo,
-			Valor:   simbolo.Valor,
-			Linea:   simbolo.Linea,
-			Columna: simbolo.Columna,
+			Nombre:      simbolo.Nombre,
+			Tipo:        simbolo.Tipo,
+			Valor:       simbolo.Valor,
+			Linea:       simbolo.Linea,
+			Columna:     simbolo.Columna,
+			Categoria:   simbolo.Categoria,
+			Ambito:      simbolo.Ambito,
+			Firma:       simbolo.Firma(),
+			Dimensiones: simbolo.Dimensiones,
 		})
 	}
 
 	return resultado
-}
\ No newline at end of file
+}
@@ -0,0 +1,54 @@
+package models
+
+// PosicionInfo representa una posición del código fuente para la API
+type PosicionInfo struct {
+	Offset  int `json:"offset"`
+	Linea   int `json:"linea"`
+	Columna int `json:"columna"`
+}
+
+// RangoInfo representa el texto que ocupa un nodo, desde Inicio hasta antes
+// de Fin
+type RangoInfo struct {
+	Inicio PosicionInfo `json:"inicio"`
+	Fin    PosicionInfo `json:"fin"`
+}
+
+// NodoInfo representa un nodo del árbol sintáctico abstracto para la API.
+// Kind es el tipo de nodo y Rol el papel que cumple dentro de su padre.
+type NodoInfo struct {
+	Kind      string      `json:"kind"`
+	Rol       string      `json:"rol,omitempty"`
+	Rango     RangoInfo   `json:"span"`
+	Tipo      string      `json:"tipo,omitempty"`
+	Nombre    string      `json:"nombre,omitempty"`
+	Valor     string      `json:"valor,omitempty"`
+	Operador  string      `json:"operador,omitempty"`
+	Constante bool        `json:"constante,omitempty"`
+	Children  []*NodoInfo `json:"children"`
+}
+
+// NodoConcretoInfo representa un nodo del árbol concreto para la API: un
+// no terminal con los símbolos de la producción que se aplicó, o un terminal
+// con su lexema
+type NodoConcretoInfo struct {
+	Simbolo  string              `json:"simbolo"`
+	Terminal bool                `json:"terminal"`
+	Lexema   string              `json:"lexema,omitempty"`
+	Rango    RangoInfo           `json:"span"`
+	Hijos    []*NodoConcretoInfo `json:"hijos,omitempty"`
+}
+
+// ProduccionInfo representa una producción de una gramática para la API
+type ProduccionInfo struct {
+	Izquierda string   `json:"izquierda"`
+	Derecha   []string `json:"derecha"`
+}
+
+// PasoDerivacionInfo es un paso de la derivación por la izquierda: la
+// producción aplicada y la posición, en la forma sentencial anterior, del no
+// terminal que reemplaza. La primera forma es el símbolo inicial.
+type PasoDerivacionInfo struct {
+	Produccion ProduccionInfo `json:"produccion"`
+	Posicion   int            `json:"posicion"`
+}
@@ -0,0 +1,45 @@
+package models
+
+// DefinicionInfo representa una definición de token con su expresión
+// regular. Tipo es el tipo de token que emite; Ignorar indica que no se
+// emite (espacios, comentarios).
+type DefinicionInfo struct {
+	Nombre  string `json:"nombre"`
+	Patron  string `json:"patron"`
+	Tipo    string `json:"tipo,omitempty"`
+	Ignorar bool   `json:"ignorar,omitempty"`
+}
+
+// FilaTablaInfo representa la fila de un estado en una tabla de transiciones
+type FilaTablaInfo struct {
+	Estado       int     `json:"estado"`
+	Acepta       string  `json:"acepta,omitempty"`   // nombre de la definición aceptada
+	Conjunto     []int   `json:"conjunto,omitempty"` // estados de origen de la construcción
+	Transiciones [][]int `json:"transiciones"`       // destinos por símbolo
+}
+
+// TablaTransicionesInfo representa un autómata como tabla: una columna por
+// símbolo (o clase de bytes equivalentes) y una fila por estado
+type TablaTransicionesInfo struct {
+	Simbolos []string        `json:"simbolos"`
+	Inicial  int             `json:"inicial"`
+	Filas    []FilaTablaInfo `json:"filas"`
+}
+
+// AutomataInfo representa un autómata para la API: su tabla de transiciones y
+// su descripción en Graphviz DOT
+type AutomataInfo struct {
+	Estados int                    `json:"estados"`
+	Tabla   *TablaTransicionesInfo `json:"tabla"`
+	DOT     string                 `json:"dot"`
+}
+
+// ResultadoAutomata representa las etapas de la construcción del analizador
+// léxico a partir de expresiones regulares
+type ResultadoAutomata struct {
+	Definiciones []DefinicionInfo `json:"definiciones"`
+	AFN          AutomataInfo     `json:"afn"`
+	AFD          AutomataInfo     `json:"afd"`
+	AFDMinimo    AutomataInfo     `json:"afdMinimo"`
+	Tokens       []TokenInfo      `json:"tokens"`
+}
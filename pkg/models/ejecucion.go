@@ -0,0 +1,17 @@
+package models
+
+// VariableEjecucion es el valor final de una variable global: un entero, o
+// la lista de elementos si es un arreglo
+type VariableEjecucion struct {
+	Nombre string      `json:"nombre"`
+	Valor  interface{} `json:"valor"`
+}
+
+// ResultadoEjecucion representa la ejecución de un programa
+type ResultadoEjecucion struct {
+	Ejecutado bool                `json:"ejecutado"` // false si el programa tenía errores y no se ejecutó
+	Salida    string              `json:"salida"`    // lo escrito con print
+	Errores   []ErrorInfo         `json:"errores"`   // los errores que impidieron ejecutar, o el que detuvo la ejecución
+	Variables []VariableEjecucion `json:"variables"` // valores finales de las variables globales
+	Pasos     int                 `json:"pasos"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import "fmt"
+
+// Fases del análisis. Cada una necesita que antes se ejecuten las anteriores.
+const (
+	FASE_LEXICA     = "lexico"
+	FASE_SINTACTICA = "sintactico"
+	FASE_SEMANTICA  = "semantico"
+)
+
+// Fases son todas las fases en el orden en que se ejecutan
+var Fases = []string{FASE_LEXICA, FASE_SINTACTICA, FASE_SEMANTICA}
+
+// SeleccionarFases valida las fases pedidas y las devuelve sin repetir y en
+// el orden en que se ejecutan. Si no se pide ninguna, devuelve todas.
+func SeleccionarFases(pedidas []string) ([]string, error) {
+	if len(pedidas) == 0 {
+		return Fases, nil
+	}
+	incluidas := map[string]bool{}
+	for _, fase := range pedidas {
+		if !esFase(fase) {
+			return nil, fmt.Errorf("fase desconocida: %s", fase)
+		}
+		incluidas[fase] = true
+	}
+	var fases []string
+	for _, fase := range Fases {
+		if incluidas[fase] {
+			fases = append(fases, fase)
+		}
+	}
+	return fases, nil
+}
+
+func esFase(nombre string) bool {
+	for _, fase := range Fases {
+		if fase == nombre {
+			return true
+		}
+	}
+	return false
+}
+
+// IncluyeFase indica si la fase está entre las seleccionadas
+func IncluyeFase(fases []string, fase string) bool {
+	for _, f := range fases {
+		if f == fase {
+			return true
+		}
+	}
+	return false
+}
+
+// ResultadoLexico representa el resultado del análisis léxico
+type ResultadoLexico struct {
+	Tokens       []TokenInfo  `json:"tokens"`
+	ConteoTokens ConteoTokens `json:"conteoTokens"`
+	Errores      []ErrorInfo  `json:"errores"`
+
+	// Traza de las fases, solo si la solicitud la pidió
+	Traza            []EventoInfo `json:"traza,omitempty"`
+	TrazaDescartados int          `json:"trazaDescartados,omitempty"`
+}
+
+// ResultadoSintactico representa el resultado del análisis sintáctico
+type ResultadoSintactico struct {
+	AST     *NodoInfo   `json:"ast"`
+	Errores []ErrorInfo `json:"errores"`
+
+	// Árbol concreto y derivación por la izquierda, solo si la solicitud los pidió
+	CST        *NodoConcretoInfo    `json:"cst,omitempty"`
+	Derivacion []PasoDerivacionInfo `json:"derivacion,omitempty"`
+
+	Traza            []EventoInfo `json:"traza,omitempty"`
+	TrazaDescartados int          `json:"trazaDescartados,omitempty"`
+}
+
+// ResultadoSemantico representa el resultado del análisis semántico. Se
+// hace sobre el árbol que el parser recuperó aunque tenga errores de sintaxis.
+type ResultadoSemantico struct {
+	Errores      []ErrorInfo   `json:"errores"`
+	Advertencias []ErrorInfo   `json:"advertencias"`
+	Simbolos     []SimboloInfo `json:"simbolos"`
+
+	Traza            []EventoInfo `json:"traza,omitempty"`
+	TrazaDescartados int          `json:"trazaDescartados,omitempty"`
+}
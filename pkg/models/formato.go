@@ -0,0 +1,9 @@
+package models
+
+// ResultadoFormato representa el código formateado con el estilo canónico
+type ResultadoFormato struct {
+	Codigo     string      `json:"codigo"`     // código formateado, o el original si hubo errores
+	Diff       string      `json:"diff"`       // diferencias en formato unificado
+	Modificado bool        `json:"modificado"` // el formato cambió el código
+	Errores    []ErrorInfo `json:"errores"`    // errores léxicos y de sintaxis que impidieron formatear
+}
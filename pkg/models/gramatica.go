@@ -0,0 +1,133 @@
+package models
+
+// ConflictoLL1Info representa una celda de la tabla LL(1) con más de una
+// producción
+type ConflictoLL1Info struct {
+	NoTerminal   string `json:"noTerminal"`
+	Terminal     string `json:"terminal"`
+	Producciones []int  `json:"producciones"`
+}
+
+// TablaLL1Info representa la tabla de análisis predictivo: para cada no
+// terminal y terminal de anticipación, los índices de las producciones
+// aplicables
+type TablaLL1Info struct {
+	Terminales []string                    `json:"terminales"` // columnas, con $ al final
+	Celdas     map[string]map[string][]int `json:"celdas"`
+	Conflictos []ConflictoLL1Info          `json:"conflictos"`
+}
+
+// PasoLL1Info representa un paso del análisis predictivo
+type PasoLL1Info struct {
+	Pila            []string `json:"pila"`            // los elementos más cercanos a la cima
+	ProfundidadPila int      `json:"profundidadPila"` // elementos de la pila completa
+	Entrada         []string `json:"entrada"`         // símbolos restantes
+	Accion          string   `json:"accion"`
+}
+
+// TrazaAnalisisInfo representa el análisis de una entrada con una tabla: sus
+// pasos y, si la rechazó, el error y dónde ocurrió
+type TrazaAnalisisInfo[P any] struct {
+	Aceptada bool   `json:"aceptada"`
+	Pasos    []P    `json:"pasos"`
+	Error    string `json:"error,omitempty"`
+	Linea    int    `json:"linea,omitempty"`
+	Columna  int    `json:"columna,omitempty"`
+}
+
+// ResultadoLL1 representa el análisis LL(1) de una gramática y, si se dio un
+// programa, la traza del análisis predictivo
+type ResultadoLL1 struct {
+	Gramatica    string                          `json:"gramatica"` // BNF sin construcciones EBNF
+	Producciones []string                        `json:"producciones"`
+	Advertencias []string                        `json:"advertencias"`
+	Primeros     map[string][]string             `json:"primeros"`
+	Siguientes   map[string][]string             `json:"siguientes"`
+	Tabla        *TablaLL1Info                   `json:"tabla"`
+	EsLL1        bool                            `json:"esLL1"`
+	Traza        *TrazaAnalisisInfo[PasoLL1Info] `json:"traza,omitempty"`
+}
+
+// AccionLRInfo representa una entrada de la tabla ACCION. Numero es el estado
+// destino de un desplazamiento o la producción de una reducción.
+type AccionLRInfo struct {
+	Tipo   string `json:"tipo"`
+	Numero int    `json:"numero,omitempty"`
+}
+
+// EstadoLRInfo representa un conjunto de ítems de la colección canónica. Los
+// ítems del núcleo van primero.
+type EstadoLRInfo struct {
+	Numero       int            `json:"numero"`
+	Items        []string       `json:"items"`
+	Nucleo       int            `json:"nucleo"` // cantidad de ítems del núcleo
+	Transiciones map[string]int `json:"transiciones"`
+}
+
+// ConflictoLRInfo representa una celda de la tabla ACCION con más de una
+// acción
+type ConflictoLRInfo struct {
+	Estado   int            `json:"estado"`
+	Terminal string         `json:"terminal"`
+	Tipo     string         `json:"tipo"` // "desplazamiento/reducción" o "reducción/reducción"
+	Acciones []AccionLRInfo `json:"acciones"`
+}
+
+// TablaLRInfo representa las tablas ACCION e IR_A de un analizador
+// ascendente. Aumentada es la producción S' → S que se agrega a la gramática.
+type TablaLRInfo struct {
+	Metodo       string                      `json:"metodo"`
+	Aumentada    ProduccionInfo              `json:"aumentada"`
+	Estados      []EstadoLRInfo              `json:"estados"`
+	Terminales   []string                    `json:"terminales"` // columnas de ACCION, con $ al final
+	NoTerminales []string                    `json:"noTerminales"`
+	Accion       []map[string][]AccionLRInfo `json:"accion"` // por estado y terminal
+	IrA          []map[string]int            `json:"irA"`    // por estado y no terminal
+	Conflictos   []ConflictoLRInfo           `json:"conflictos"`
+}
+
+// PasoLRInfo representa un paso del análisis por desplazamiento y reducción
+type PasoLRInfo struct {
+	Estados         []int    `json:"estados"`         // los estados más cercanos a la cima de la pila
+	Simbolos        []string `json:"simbolos"`        // símbolos entre los estados mostrados
+	ProfundidadPila int      `json:"profundidadPila"` // estados de la pila completa
+	Entrada         []string `json:"entrada"`         // símbolos restantes
+	Accion          string   `json:"accion"`
+}
+
+// ResultadoLR representa las tablas de un analizador ascendente y, si se dio
+// un programa, la traza de desplazamientos y reducciones
+type ResultadoLR struct {
+	Gramatica     string                         `json:"gramatica"`
+	Producciones  []string                       `json:"producciones"`
+	Advertencias  []string                       `json:"advertencias"`
+	Tabla         *TablaLRInfo                   `json:"tabla"`
+	SinConflictos bool                           `json:"sinConflictos"`
+	Traza         *TrazaAnalisisInfo[PasoLRInfo] `json:"traza,omitempty"`
+}
+
+// ElementoInfo representa una expresión EBNF del lado derecho de una regla.
+// Simbolo es el tipo de token de un terminal o el nombre de la regla de un
+// no terminal.
+type ElementoInfo struct {
+	Tipo    string         `json:"tipo"`
+	Simbolo string         `json:"simbolo,omitempty"`
+	Hijos   []ElementoInfo `json:"hijos,omitempty"`
+}
+
+// ResultadoGramaticaParser describe la gramática que reconoce el parser
+type ResultadoGramaticaParser struct {
+	EBNF         string             `json:"ebnf"`
+	Producciones []string           `json:"producciones"` // forma BNF, la del árbol concreto
+	Reglas       []ReglaGramatica   `json:"reglas"`
+	EsLL1        bool               `json:"esLL1"`
+	Conflictos   []ConflictoLL1Info `json:"conflictos"`
+}
+
+// ReglaGramatica es una regla con su texto EBNF y su diagrama de sintaxis
+type ReglaGramatica struct {
+	Nombre   string       `json:"nombre"`
+	EBNF     string       `json:"ebnf"`
+	Cuerpo   ElementoInfo `json:"cuerpo"`
+	Diagrama string       `json:"diagrama"` // documento SVG
+}
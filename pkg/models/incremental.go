@@ -0,0 +1,24 @@
+package models
+
+// ResultadoDocumento representa un documento abierto para edición incremental
+type ResultadoDocumento struct {
+	DocumentoID  string      `json:"documentoId"`
+	Version      int         `json:"version"`
+	Errores      []ErrorInfo `json:"errores"`
+	Advertencias []ErrorInfo `json:"advertencias"`
+}
+
+// ResultadoEdicion representa los diagnósticos que cambiaron tras una edición.
+// Los diagnósticos que solo se desplazaron con el texto no se incluyen.
+type ResultadoEdicion struct {
+	DocumentoID               string      `json:"documentoId"`
+	Version                   int         `json:"version"`
+	ErroresNuevos             []ErrorInfo `json:"erroresNuevos"`
+	ErroresResueltos          []ErrorInfo `json:"erroresResueltos"`
+	AdvertenciasNuevas        []ErrorInfo `json:"advertenciasNuevas"`
+	AdvertenciasResueltas     []ErrorInfo `json:"advertenciasResueltas"`
+	TokensReanalizados        int         `json:"tokensReanalizados"`
+	TokensReutilizados        int         `json:"tokensReutilizados"`
+	DeclaracionesReanalizadas int         `json:"declaracionesReanalizadas"`
+	DeclaracionesReutilizadas int         `json:"declaracionesReutilizadas"`
+}
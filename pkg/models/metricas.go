@@ -0,0 +1,66 @@
+package models
+
+// HalsteadInfo representa las medidas de Halstead de un programa para la API
+type HalsteadInfo struct {
+	OperadoresDistintos int     `json:"operadoresDistintos"` // n1
+	OperandosDistintos  int     `json:"operandosDistintos"`  // n2
+	Operadores          int     `json:"operadores"`          // N1
+	Operandos           int     `json:"operandos"`           // N2
+	Vocabulario         int     `json:"vocabulario"`         // n = n1 + n2
+	Longitud            int     `json:"longitud"`            // N = N1 + N2
+	Volumen             float64 `json:"volumen"`             // V = N log2 n
+	Dificultad          float64 `json:"dificultad"`          // D = n1/2 · N2/n2
+	Esfuerzo            float64 `json:"esfuerzo"`            // E = D · V
+}
+
+// MetricasFuncionInfo representa las métricas del cuerpo de una función
+type MetricasFuncionInfo struct {
+	Nombre                 string `json:"nombre"`
+	Sentencias             int    `json:"sentencias"`
+	ProfundidadCiclos      int    `json:"profundidadCiclos"`
+	ComplejidadCiclomatica int    `json:"complejidadCiclomatica"`
+}
+
+// MetricasInfo representa las métricas de un programa para la API
+type MetricasInfo struct {
+	Sentencias              int                   `json:"sentencias"`
+	ProfundidadCiclos       int                   `json:"profundidadCiclos"`
+	ComplejidadCiclomatica  int                   `json:"complejidadCiclomatica"`
+	Halstead                HalsteadInfo          `json:"halstead"`
+	Variables               int                   `json:"variables"`
+	LongitudIdentificadores float64               `json:"longitudPromedioIdentificadores"`
+	Funciones               []MetricasFuncionInfo `json:"funciones"`
+}
+
+// EstadisticaInfo resume una métrica en un conjunto de programas
+type EstadisticaInfo struct {
+	Minimo   float64 `json:"minimo"`
+	Maximo   float64 `json:"maximo"`
+	Promedio float64 `json:"promedio"`
+}
+
+// ResumenInfo representa el resumen de las métricas de un lote de programas
+type ResumenInfo struct {
+	Programas               int             `json:"programas"`
+	Sentencias              EstadisticaInfo `json:"sentencias"`
+	ProfundidadCiclos       EstadisticaInfo `json:"profundidadCiclos"`
+	ComplejidadCiclomatica  EstadisticaInfo `json:"complejidadCiclomatica"`
+	VolumenHalstead         EstadisticaInfo `json:"volumenHalstead"`
+	EsfuerzoHalstead        EstadisticaInfo `json:"esfuerzoHalstead"`
+	Variables               EstadisticaInfo `json:"variables"`
+	LongitudIdentificadores EstadisticaInfo `json:"longitudPromedioIdentificadores"`
+}
+
+// MetricasPrograma son las métricas de un programa de un lote
+type MetricasPrograma struct {
+	Nombre   string       `json:"nombre"`
+	Errores  int          `json:"errores"` // léxicos, sintácticos y semánticos
+	Metricas MetricasInfo `json:"metricas"`
+}
+
+// ResultadoLote representa las métricas de un lote de programas y su resumen
+type ResultadoLote struct {
+	LoteID    string             `json:"loteId"`
+	Resumen   ResumenInfo        `json:"resumen"`
+	Programas []MetricasPrograma `json:"programas,omitempty"`
+}